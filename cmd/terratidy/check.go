@@ -4,12 +4,12 @@ package main
 import (
 	"context"
 	"fmt"
-	"os"
 
 	fmtengine "github.com/santosr2/terratidy/internal/engines/format"
 	"github.com/santosr2/terratidy/internal/engines/lint"
 	"github.com/santosr2/terratidy/internal/engines/policy"
 	"github.com/santosr2/terratidy/internal/engines/style"
+	"github.com/santosr2/terratidy/internal/output"
 	"github.com/santosr2/terratidy/pkg/sdk"
 	"github.com/spf13/cobra"
 )
@@ -63,19 +63,24 @@ func runCheck(_ *cobra.Command, args []string) error {
 
 	printCheckHeader(len(files))
 
-	allFindings, err := runAllChecks(files)
+	report, err := startReport(files)
 	if err != nil {
 		return err
 	}
 
-	return printCheckSummary(allFindings)
+	allFindings, err := runAllChecks(files, report)
+	if err != nil {
+		return err
+	}
+
+	return finishReport(report, allFindings)
 }
 
 func printNoFilesMessage() {
 	if changed {
-		fmt.Println("No changed HCL files found")
+		statusf("No changed HCL files found\n")
 	} else {
-		fmt.Println("No HCL files found")
+		statusf("No HCL files found\n")
 	}
 }
 
@@ -84,29 +89,43 @@ func printCheckHeader(fileCount int) {
 	if changed {
 		modeMsg = " (changed files only)"
 	}
-	fmt.Printf("Checking %s%s...\n\n", formatFileCount(fileCount), modeMsg)
+	statusf("Checking %s%s...\n\n", formatFileCount(fileCount), modeMsg)
 }
 
-func runAllChecks(files []string) ([]sdk.Finding, error) {
+func runAllChecks(files []string, report output.StreamFormatter) ([]sdk.Finding, error) {
 	ctx := context.Background()
 	var allFindings []sdk.Finding
 	step := 1
 
+	// collect adds the findings of an engine, streaming them to the reports
+	collect := func(findings []sdk.Finding) error {
+		allFindings = append(allFindings, findings...)
+		return report.WriteFindings(findings)
+	}
+
 	if !checkSkipFmt {
 		findings, err := runFmtCheck(ctx, files, step)
 		if err != nil {
 			return nil, err
 		}
-		allFindings = append(allFindings, findings...)
+		if err := collect(findings); err != nil {
+			return nil, err
+		}
 		step++
 	}
 
 	if !checkSkipStyle {
-		findings, err := runStyleCheck(ctx, files, step)
+		lintReportsWrappers, err := lintReportsInterpolationWrappers()
 		if err != nil {
 			return nil, err
 		}
-		allFindings = append(allFindings, findings...)
+		findings, err := runStyleCheck(ctx, files, step, lintReportsWrappers)
+		if err != nil {
+			return nil, err
+		}
+		if err := collect(findings); err != nil {
+			return nil, err
+		}
 		step++
 	}
 
@@ -115,7 +134,9 @@ func runAllChecks(files []string) ([]sdk.Finding, error) {
 		if err != nil {
 			return nil, err
 		}
-		allFindings = append(allFindings, findings...)
+		if err := collect(findings); err != nil {
+			return nil, err
+		}
 		step++
 	}
 
@@ -124,78 +145,97 @@ func runAllChecks(files []string) ([]sdk.Finding, error) {
 		if err != nil {
 			return nil, err
 		}
-		allFindings = append(allFindings, findings...)
+		if err := collect(findings); err != nil {
+			return nil, err
+		}
 	}
 
 	return allFindings, nil
 }
 
 func runFmtCheck(ctx context.Context, files []string, step int) ([]sdk.Finding, error) {
-	fmt.Printf("%d. Checking formatting...\n", step)
-	fmtEngine := fmtengine.New(&fmtengine.Config{Check: true})
+	statusf("%d. Checking formatting...\n", step)
+	opts, err := loadFmtOptions()
+	if err != nil {
+		return nil, err
+	}
+	// Text edits are only reported by the structured formats
+	fmtEngine := fmtengine.New(&fmtengine.Config{Check: true, Fixes: !textOutput(), Options: opts})
 	findings, err := fmtEngine.Run(ctx, files)
 	if err != nil {
 		return nil, fmt.Errorf("fmt check failed: %w", err)
 	}
-	fmt.Printf("   Found %d issue(s)\n\n", len(findings))
+	statusf("   Found %d issue(s)\n\n", len(findings))
 	return findings, nil
 }
 
-func runStyleCheck(ctx context.Context, files []string, step int) ([]sdk.Finding, error) {
-	fmt.Printf("%d. Checking style...\n", step)
+func runStyleCheck(ctx context.Context, files []string, step int, lintReportsWrappers bool) ([]sdk.Finding, error) {
+	statusf("%d. Checking style...\n", step)
+	rules := make(map[string]style.RuleConfig)
+	if lintReportsWrappers {
+		// The modernizer reports the same expressions, for the module's version
+		rules["style.no-interpolation-wrapper"] = style.RuleConfig{Enabled: false}
+	}
 	styleEngine := style.New(&style.Config{
 		Fix:   false,
-		Rules: make(map[string]style.RuleConfig),
+		Rules: rules,
 	})
 	findings, err := styleEngine.Run(ctx, files)
 	if err != nil {
 		return nil, fmt.Errorf("style check failed: %w", err)
 	}
-	fmt.Printf("   Found %d issue(s)\n\n", len(findings))
+	statusf("   Found %d issue(s)\n\n", len(findings))
 	return findings, nil
 }
 
+// lintReportsInterpolationWrappers reports whether the lint step reports
+// interpolation-only expressions through lint.terraform-deprecated-syntax, in
+// which case check leaves them out of the style step.
+func lintReportsInterpolationWrappers() (bool, error) {
+	if checkSkipLint {
+		return false, nil
+	}
+	lintCfg, err := loadLintConfig()
+	if err != nil {
+		return false, err
+	}
+	if rule, ok := lintCfg.Rules["lint.terraform-deprecated-syntax"]; ok && !rule.Enabled {
+		return false, nil
+	}
+	// TFLint replaces the built-in rules when it is available
+	return !lintCfg.UseTFLint || !lint.New(lintCfg).IsTFLintAvailable(), nil
+}
+
 func runLintCheck(ctx context.Context, files []string, step int) ([]sdk.Finding, error) {
-	fmt.Printf("%d. Running linter...\n", step)
-	lintEngine := lint.New(&lint.Config{ConfigFile: ".tflint.hcl"})
+	statusf("%d. Running linter...\n", step)
+	lintCfg, err := loadLintConfig()
+	if err != nil {
+		return nil, err
+	}
+	lintEngine := lint.New(lintCfg)
 	findings, err := lintEngine.Run(ctx, files)
 	if err != nil {
 		return nil, fmt.Errorf("lint check failed: %w", err)
 	}
-	fmt.Printf("   Found %d issue(s)\n\n", len(findings))
+	statusf("   Found %d issue(s)\n\n", len(findings))
 	return findings, nil
 }
 
 func runPolicyCheck(ctx context.Context, files []string, step int) ([]sdk.Finding, error) {
-	fmt.Printf("%d. Running policy checks...\n", step)
-	policyEngine := policy.New(&policy.Config{})
+	statusf("%d. Running policy checks...\n", step)
+	policyCfg, err := loadPolicyConfig()
+	if err != nil {
+		return nil, err
+	}
+	policyEngine := policy.New(policyCfg)
 	findings, err := policyEngine.Run(ctx, files)
 	if err != nil {
 		return nil, fmt.Errorf("policy check failed: %w", err)
 	}
-	fmt.Printf("   Found %d issue(s)\n\n", len(findings))
+	statusf("   Found %d issue(s)\n\n", len(findings))
 	return findings, nil
 }
 
-func printCheckSummary(allFindings []sdk.Finding) error {
-	fmt.Println("---")
-	fmt.Printf("Summary: %d total issue(s)\n", len(allFindings))
-
-	if len(allFindings) == 0 {
-		fmt.Println("All checks passed!")
-		return nil
-	}
-
-	errors, warnings, info := countBySeverity(allFindings)
-	printSeverityCounts(errors, warnings, info)
-	printCheckHints()
-
-	if errors > 0 {
-		os.Exit(1)
-	}
-	return nil
-}
-
 func countBySeverity(findings []sdk.Finding) (errors, warnings, info int) {
 	for _, finding := range findings {
 		switch finding.Severity {
@@ -209,25 +249,3 @@ func countBySeverity(findings []sdk.Finding) (errors, warnings, info int) {
 	}
 	return
 }
-
-func printSeverityCounts(errors, warnings, info int) {
-	fmt.Println()
-	if errors > 0 {
-		fmt.Printf("  Errors:   %d\n", errors)
-	}
-	if warnings > 0 {
-		fmt.Printf("  Warnings: %d\n", warnings)
-	}
-	if info > 0 {
-		fmt.Printf("  Info:     %d\n", info)
-	}
-}
-
-func printCheckHints() {
-	fmt.Println()
-	fmt.Println("Run individual commands for details:")
-	fmt.Println("  terratidy fmt --check")
-	fmt.Println("  terratidy style")
-	fmt.Println("  terratidy lint")
-	fmt.Println("  terratidy policy")
-}
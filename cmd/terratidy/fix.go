@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	fmtengine "github.com/santosr2/terratidy/internal/engines/format"
+	"github.com/santosr2/terratidy/internal/engines/lint"
 	"github.com/santosr2/terratidy/internal/engines/style"
 	"github.com/santosr2/terratidy/pkg/sdk"
 	"github.com/spf13/cobra"
@@ -14,7 +15,9 @@ import (
 var fixCmd = &cobra.Command{
 	Use:   "fix [paths...]",
 	Short: "Auto-fix all fixable issues",
-	Long: `Automatically fix formatting and style issues. Runs fmt + style --fix.
+	Long: `Automatically fix deprecated syntax, formatting and style issues.
+Rewrites deprecated Terraform syntax for the module's required_version,
+then runs fmt + style --fix.
 
 Use --changed to only fix files that have been modified in git.`,
 	Example: `  # Fix all files
@@ -67,6 +70,13 @@ func runAllFixes(files []string) ([]sdk.Finding, int, error) {
 	var allFindings []sdk.Finding
 	totalFixed := 0
 
+	lintFindings, modernized, err := runLintFix(ctx, files)
+	if err != nil {
+		return nil, 0, err
+	}
+	allFindings = append(allFindings, lintFindings...)
+	totalFixed += modernized
+
 	fmtFindings, formatted, err := runFmtFix(ctx, files)
 	if err != nil {
 		return nil, 0, err
@@ -84,9 +94,37 @@ func runAllFixes(files []string) ([]sdk.Finding, int, error) {
 	return allFindings, totalFixed, nil
 }
 
+func runLintFix(ctx context.Context, files []string) ([]sdk.Finding, int, error) {
+	fmt.Println("1. Modernizing deprecated syntax...")
+	lintCfg, err := loadLintConfig()
+	if err != nil {
+		return nil, 0, err
+	}
+	lintCfg.Fix = true
+	lintEngine := lint.New(lintCfg)
+	findings, err := lintEngine.Run(ctx, files)
+	if err != nil {
+		return nil, 0, fmt.Errorf("modernizing failed: %w", err)
+	}
+
+	// Only fixed findings are reported here; 'terratidy lint' covers the rest
+	var fixed []sdk.Finding
+	for _, f := range findings {
+		if f.Fixable && f.FixFunc != nil {
+			fixed = append(fixed, f)
+		}
+	}
+	fmt.Printf("   Modernized %d expression(s)\n\n", len(fixed))
+	return fixed, len(fixed), nil
+}
+
 func runFmtFix(ctx context.Context, files []string) ([]sdk.Finding, int, error) {
-	fmt.Println("1. Formatting files...")
-	fmtEngine := fmtengine.New(&fmtengine.Config{Check: false})
+	fmt.Println("2. Formatting files...")
+	opts, err := loadFmtOptions()
+	if err != nil {
+		return nil, 0, err
+	}
+	fmtEngine := fmtengine.New(&fmtengine.Config{Check: false, Verify: true, Options: opts})
 	findings, err := fmtEngine.Run(ctx, files)
 	if err != nil {
 		return nil, 0, fmt.Errorf("formatting failed: %w", err)
@@ -108,7 +146,7 @@ func countFormattedFiles(findings []sdk.Finding) int {
 }
 
 func runStyleFix(ctx context.Context, files []string) ([]sdk.Finding, int, error) {
-	fmt.Println("2. Fixing style issues...")
+	fmt.Println("3. Fixing style issues...")
 	styleEngine := style.New(&style.Config{
 		Fix:   true,
 		Rules: make(map[string]style.RuleConfig),
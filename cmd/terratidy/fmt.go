@@ -1,25 +1,37 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"os"
 
 	fmtengine "github.com/santosr2/terratidy/internal/engines/format"
+	"github.com/santosr2/terratidy/pkg/sdk"
 	"github.com/spf13/cobra"
 )
 
 var (
-	fmtCheck bool
-	fmtDiff  bool
+	fmtCheck  bool
+	fmtDiff   bool
+	fmtVerify bool
+	fmtLines  []string
 )
 
 var fmtCmd = &cobra.Command{
 	Use:   "fmt [paths...]",
 	Short: "Format Terraform and Terragrunt files",
-	Long: `Format .tf and .hcl files using the HCL formatter.
+	Long: `Format .tf, .tfvars and .hcl files using the HCL formatter, and canonicalise
+.tf.json and .tfvars.json files (sorted keys, two-space indentation).
 
 Use --changed to only format files that have been modified in git.
-Use --check to verify formatting without making changes.`,
+Use --check to verify formatting without making changes.
+Use --verify to also reformat the output and refuse to write files whose
+formatting is not idempotent. Output that no longer parses is never written.
+Use --lines to only apply the formatting changes that touch the given lines,
+e.g. the lines changed in a commit.
+Use - as the only path to format stdin to stdout (for editor integration).`,
 	Example: `  # Format all files in current directory
   terratidy fmt
 
@@ -29,9 +41,32 @@ Use --check to verify formatting without making changes.`,
   # Check formatting without modifying
   terratidy fmt --check
 
+  # Show a unified diff of the changes
+  terratidy fmt --diff
+
+  # Format stdin to stdout
+  cat main.tf | terratidy fmt -
+
+  # Only format lines 10 to 20 and line 42 of a file
+  terratidy fmt --lines 10-20 --lines 42 main.tf
+
   # Only format changed files (git)
   terratidy fmt --changed`,
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
+		opts, err := loadFmtOptions()
+		if err != nil {
+			return err
+		}
+
+		lines, err := parseLineRanges(fmtLines)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 && args[0] == "-" {
+			return formatStdin(cmd.InOrStdin(), cmd.OutOrStdout(), *opts, lines)
+		}
+
 		// Get target files (respecting --changed flag)
 		files, err := getTargetFiles(args, changed)
 		if err != nil {
@@ -40,24 +75,40 @@ Use --check to verify formatting without making changes.`,
 
 		if len(files) == 0 {
 			if changed {
-				fmt.Println("No changed HCL files found")
+				statusf("No changed HCL files found\n")
 			} else {
-				fmt.Println("No HCL files found")
+				statusf("No HCL files found\n")
 			}
 			return nil
 		}
 
-		// Create formatter engine
+		if fmtDiff && !textOutput() {
+			return fmt.Errorf("--diff cannot be combined with report formats (--format, --output)")
+		}
+
+		// Create formatter engine. With --diff the engine only reports the
+		// changes and the files are written here after the diff is shown.
+		var ranges map[string][]fmtengine.LineRange
+		if lines != nil {
+			ranges = make(map[string][]fmtengine.LineRange, len(files))
+			for _, file := range files {
+				ranges[file] = lines
+			}
+		}
 		engine := fmtengine.New(&fmtengine.Config{
-			Check: fmtCheck,
-			Diff:  fmtDiff,
+			Check:   fmtCheck || fmtDiff,
+			Diff:    fmtDiff,
+			Verify:  fmtVerify,
+			Fixes:   !textOutput(),
+			Ranges:  ranges,
+			Options: opts,
 		})
 
 		modeMsg := ""
 		if changed {
 			modeMsg = " (changed files only)"
 		}
-		fmt.Printf("Formatting %s%s...\n\n", formatFileCount(len(files)), modeMsg)
+		statusf("Formatting %s%s...\n\n", formatFileCount(len(files)), modeMsg)
 
 		// Run formatter
 		findings, err := engine.Run(context.Background(), files)
@@ -65,6 +116,10 @@ Use --check to verify formatting without making changes.`,
 			return fmt.Errorf("formatting files: %w", err)
 		}
 
+		if !textOutput() {
+			return writeReport(findings, files)
+		}
+
 		// Display results
 		if len(findings) == 0 {
 			fmt.Println("All files are properly formatted")
@@ -73,14 +128,31 @@ Use --check to verify formatting without making changes.`,
 
 		needsFormatting := 0
 		formatted := 0
+		failures := 0
 		for _, finding := range findings {
 			switch finding.Rule {
 			case "fmt.needs-formatting":
+				if fmtDiff {
+					if err := printFormatDiff(finding); err != nil {
+						return err
+					}
+				}
+				if fmtDiff && !fmtCheck {
+					if err := writeFormatted(finding); err != nil {
+						return err
+					}
+					fmt.Printf("  [+] %s: formatted\n", finding.File)
+					formatted++
+					continue
+				}
 				fmt.Printf("  [!] %s: needs formatting\n", finding.File)
 				needsFormatting++
 			case "fmt.formatted":
 				fmt.Printf("  [+] %s: formatted\n", finding.File)
 				formatted++
+			case "fmt.parse-error", "fmt.invalid-output", "fmt.non-idempotent":
+				fmt.Printf("  [!] %s: %s\n", finding.File, finding.Message)
+				failures++
 			}
 		}
 
@@ -90,6 +162,10 @@ Use --check to verify formatting without making changes.`,
 			fmt.Printf("Formatted %s\n", formatFileCount(formatted))
 		}
 
+		if failures > 0 {
+			return fmt.Errorf("%d file(s) could not be formatted", failures)
+		}
+
 		// In check mode, return error if any file needs formatting
 		if fmtCheck && needsFormatting > 0 {
 			return fmt.Errorf("%d file(s) need formatting", needsFormatting)
@@ -102,5 +178,88 @@ Use --check to verify formatting without making changes.`,
 func init() {
 	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "check if files are formatted without modifying")
 	fmtCmd.Flags().BoolVar(&fmtDiff, "diff", false, "show diff of formatting changes")
+	fmtCmd.Flags().BoolVar(&fmtVerify, "verify", false, "verify formatting is idempotent before writing")
+	fmtCmd.Flags().StringSliceVar(&fmtLines, "lines", nil, "only format changes touching these lines (START-END or N)")
 	rootCmd.AddCommand(fmtCmd)
 }
+
+// parseLineRanges parses the --lines values; nil means no restriction
+func parseLineRanges(values []string) ([]fmtengine.LineRange, error) {
+	var ranges []fmtengine.LineRange
+	for _, value := range values {
+		r, err := fmtengine.ParseLineRange(value)
+		if err != nil {
+			return nil, fmt.Errorf("--lines: %w", err)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// formatStdin formats stdin and writes the result to stdout. With --check nothing
+// is written and an error is returned when the input is not formatted; with --diff
+// a unified diff is written instead of the formatted content.
+func formatStdin(in io.Reader, out io.Writer, opts fmtengine.Options, lines []fmtengine.LineRange) error {
+	content, err := io.ReadAll(in)
+	if err != nil {
+		return fmt.Errorf("reading stdin: %w", err)
+	}
+
+	// JSON configuration starts with an object; HCL bodies never do
+	name := "<stdin>"
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		name = "<stdin>.tf.json"
+	}
+
+	config := &fmtengine.Config{Verify: fmtVerify, Options: &opts}
+	if lines != nil {
+		config.Ranges = map[string][]fmtengine.LineRange{name: lines}
+	}
+	formatted, err := fmtengine.New(config).FormatContent(name, content)
+	if err != nil {
+		return fmt.Errorf("formatting stdin: %w", err)
+	}
+
+	changed := !bytes.Equal(content, formatted)
+	switch {
+	case fmtDiff:
+		_, err = io.WriteString(out, fmtengine.ComputeDiff("<stdin>", content, formatted).String())
+	case !fmtCheck:
+		_, err = out.Write(formatted)
+	}
+	if err != nil {
+		return fmt.Errorf("writing stdout: %w", err)
+	}
+
+	if fmtCheck && changed {
+		return fmt.Errorf("stdin needs formatting")
+	}
+	return nil
+}
+
+// printFormatDiff prints the unified diff for a file that needs formatting.
+func printFormatDiff(finding sdk.Finding) error {
+	original, err := os.ReadFile(finding.File)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", finding.File, err)
+	}
+	formatted, err := finding.FixFunc()
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", finding.File, err)
+	}
+	fmt.Print(fmtengine.ComputeDiff(finding.File, original, formatted).String())
+	fmt.Println()
+	return nil
+}
+
+// writeFormatted writes the formatted content of a file that needs formatting.
+func writeFormatted(finding sdk.Finding) error {
+	formatted, err := finding.FixFunc()
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w", finding.File, err)
+	}
+	if err := os.WriteFile(finding.File, formatted, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", finding.File, err)
+	}
+	return nil
+}
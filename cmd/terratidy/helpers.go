@@ -7,7 +7,14 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/santosr2/terratidy/internal/config"
+	fmtengine "github.com/santosr2/terratidy/internal/engines/format"
+	"github.com/santosr2/terratidy/internal/engines/lint"
+	"github.com/santosr2/terratidy/internal/engines/policy"
+	"github.com/santosr2/terratidy/internal/output"
 	"github.com/santosr2/terratidy/internal/vcs"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/spf13/cobra"
 )
 
 // getTargetFiles returns the list of files to process based on the provided paths
@@ -174,9 +181,14 @@ func shouldSkipDir(_ string, name string) bool {
 	return skipDirs[name]
 }
 
-// isHCLFile checks if a file has .tf, .tfvars, or .hcl extension.
+// isHCLFile checks if a file has .tf, .tfvars, or .hcl extension,
+// or is a JSON configuration file (.tf.json, .tfvars.json).
 func isHCLFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".json" {
+		name := strings.ToLower(filepath.Base(path))
+		return strings.HasSuffix(name, ".tf.json") || strings.HasSuffix(name, ".tfvars.json")
+	}
 	return ext == ".tf" || ext == ".hcl" || ext == ".tfvars"
 }
 
@@ -187,3 +199,239 @@ func formatFileCount(count int) string {
 	}
 	return fmt.Sprintf("%d files", count)
 }
+
+// reportOutputs are the reports of the run, selected by resolveOutputs before
+// a command runs.
+var reportOutputs []config.OutputConfig
+
+// resolveOutputs selects the reports of the run: the --output flags, else the
+// outputs of the config file, else --format on stdout. Formats and templates
+// are checked here so that a bad one is reported before the checks rather
+// than after them.
+func resolveOutputs(cmd *cobra.Command) error {
+	formatSet := cmd.Flags().Changed("format")
+	var outputs []config.OutputConfig
+	switch {
+	case len(outputSpecs) > 0:
+		if formatSet {
+			return fmt.Errorf("--format cannot be combined with --output; use --output %s", format)
+		}
+		for _, spec := range outputSpecs {
+			outputs = append(outputs, parseOutputSpec(spec))
+		}
+	case !formatSet:
+		// A config that fails to load is reported by the commands that use it
+		if cfg, err := loadConfig(); err == nil {
+			outputs = cfg.Outputs
+		}
+	}
+	if len(outputs) == 0 {
+		outputs = []config.OutputConfig{{Format: format}}
+	}
+
+	if err := config.ValidateOutputs(outputs); err != nil {
+		return err
+	}
+	for i := range outputs {
+		out := &outputs[i]
+		if _, err := output.GetFormatter(out.Format, false, version); err != nil {
+			return err
+		}
+		if out.Format != "template" {
+			continue
+		}
+		if out.Template == "" {
+			out.Template = templateFile
+		}
+		if out.Template == "" {
+			return fmt.Errorf("--format template requires --template-file")
+		}
+		if _, err := output.ParseTemplateFile(out.Template); err != nil {
+			return err
+		}
+	}
+
+	reportOutputs = outputs
+	return nil
+}
+
+// parseOutputSpec parses an --output value: a format, written to stdout, or
+// format=path.
+func parseOutputSpec(spec string) config.OutputConfig {
+	name, path, _ := strings.Cut(spec, "=")
+	return config.OutputConfig{Format: strings.TrimSpace(name), Path: strings.TrimSpace(path)}
+}
+
+// isStdout reports whether an output is written to stdout.
+func isStdout(out config.OutputConfig) bool {
+	return out.Path == "" || out.Path == "-"
+}
+
+// isTextFormat reports whether format is the commands' own text output.
+func isTextFormat(format string) bool {
+	return format == "" || format == "text"
+}
+
+// textOutput reports whether the only report is text on stdout, which the
+// commands print themselves rather than through a report formatter.
+func textOutput() bool {
+	if len(reportOutputs) == 0 {
+		return isTextFormat(format)
+	}
+	return len(reportOutputs) == 1 && isStdout(reportOutputs[0]) && isTextFormat(reportOutputs[0].Format)
+}
+
+// statusf prints a progress message. When a report other than text is written
+// to stdout the message goes to stderr so that stdout holds only the report.
+func statusf(msg string, args ...any) {
+	w := os.Stdout
+	for _, out := range reportOutputs {
+		if isStdout(out) && !isTextFormat(out.Format) {
+			w = os.Stderr
+		}
+	}
+	_, _ = fmt.Fprintf(w, msg, args...)
+}
+
+// writeReport writes findings to every report of the run. files are the
+// checked files, which some formats list even without findings. Like the text
+// output, it exits with status 1 when any finding is an error.
+func writeReport(findings []sdk.Finding, files []string) error {
+	report, err := startReport(files)
+	if err != nil {
+		return err
+	}
+	if err := report.WriteFindings(findings); err != nil {
+		return err
+	}
+	return finishReport(report, findings)
+}
+
+// startReport creates the formatters of the reports of the run and starts
+// writing them, so that streaming formats receive findings as each engine
+// completes.
+func startReport(files []string) (*output.MultiFormatter, error) {
+	var baseline *output.Baseline
+	if baselineFile != "" {
+		var err error
+		if baseline, err = output.LoadBaseline(baselineFile); err != nil {
+			return nil, err
+		}
+	}
+
+	report := &output.MultiFormatter{}
+	for _, out := range reportOutputs {
+		formatter, err := output.GetFormatter(out.Format, false, version)
+		if err != nil {
+			return nil, err
+		}
+
+		switch f := formatter.(type) {
+		case *output.JUnitFormatter:
+			f.Files = files
+		case *output.SARIFFormatter:
+			f.Rules = ruleDescriptors()
+		case *output.MarkdownFormatter:
+			f.Baseline = baseline
+		case *output.HTMLFormatter:
+			f.Rules = ruleDescriptors()
+			f.Baseline = baseline
+		case *output.TemplateFormatter:
+			if f.Template, err = output.ParseTemplateFile(out.Template); err != nil {
+				return nil, err
+			}
+			f.Rules = ruleDescriptors()
+		}
+
+		report.Targets = append(report.Targets, output.Target{
+			Format:    out.Format,
+			Path:      out.Path,
+			Formatter: formatter,
+		})
+	}
+
+	if err := report.Start(os.Stdout); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// finishReport completes the reports of findings and, like the text output,
+// exits with status 1 when any finding is an error.
+func finishReport(report *output.MultiFormatter, findings []sdk.Finding) error {
+	exitCode := 0
+	if errors, _, _ := countBySeverity(findings); errors > 0 {
+		exitCode = 1
+	}
+	for _, target := range report.Targets {
+		if f, ok := target.Formatter.(*output.SARIFFormatter); ok {
+			f.ExitCode = exitCode
+		}
+	}
+
+	if err := report.Finish(); err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+	return nil
+}
+
+// loadFmtOptions reads the formatting policies from engines.fmt.config,
+// applying the selected profile if any
+func loadFmtOptions() (*fmtengine.Options, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := fmtengine.ParseOptions(cfg.Engines.Fmt.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid engines.fmt.config: %w", err)
+	}
+	return &opts, nil
+}
+
+// loadLintConfig reads the lint engine configuration from engines.lint.config
+func loadLintConfig() (*lint.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	lintCfg, err := lint.ParseConfig(cfg.Engines.Lint.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid engines.lint.config: %w", err)
+	}
+	return lintCfg, nil
+}
+
+// loadPolicyConfig reads the policy engine configuration from
+// engines.policy.config, applying the selected profile if any
+func loadPolicyConfig() (*policy.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	policyCfg, err := policy.ParseConfig(cfg.Engines.Policy.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid engines.policy.config: %w", err)
+	}
+	return policyCfg, nil
+}
+
+// loadConfig loads the configuration file and applies the selected profile
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(cfgFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading config: %w", err)
+	}
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return nil, fmt.Errorf("applying profile: %w", err)
+		}
+	}
+	return cfg, nil
+}
@@ -36,7 +36,7 @@ Use --changed to only lint files that have been modified in git.`,
 
   # Enable specific rules
   terratidy lint --rule terraform_required_version`,
-	RunE: func(_ *cobra.Command, args []string) error {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		// Get target files (respecting --changed flag)
 		files, err := getTargetFiles(args, changed)
 		if err != nil {
@@ -45,40 +45,48 @@ Use --changed to only lint files that have been modified in git.`,
 
 		if len(files) == 0 {
 			if changed {
-				fmt.Println("No changed HCL files found")
+				statusf("No changed HCL files found\n")
 			} else {
-				fmt.Println("No HCL files found")
+				statusf("No HCL files found\n")
 			}
 			return nil
 		}
 
-		// Create rule config
-		ruleConfig := make(map[string]lint.RuleConfig)
+		// Flags extend the configured settings
+		lintCfg, err := loadLintConfig()
+		if err != nil {
+			return err
+		}
+		if cmd.Flags().Changed("config-file") {
+			lintCfg.ConfigFile = lintConfigFile
+		}
+		lintCfg.Plugins = append(lintCfg.Plugins, lintPlugins...)
 		for _, rule := range lintRules {
-			ruleConfig[rule] = lint.RuleConfig{
-				Enabled:  true,
-				Severity: "warning",
+			ruleCfg := lintCfg.Rules[rule]
+			ruleCfg.Enabled = true
+			if ruleCfg.Severity == "" {
+				ruleCfg.Severity = "warning"
 			}
+			lintCfg.Rules[rule] = ruleCfg
 		}
 
-		// Create lint engine
-		engine := lint.New(&lint.Config{
-			ConfigFile: lintConfigFile,
-			Plugins:    lintPlugins,
-			Rules:      ruleConfig,
-		})
+		engine := lint.New(lintCfg)
 
 		modeMsg := ""
 		if changed {
 			modeMsg = " (changed files only)"
 		}
-		fmt.Printf("Running linter on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
+		statusf("Running linter on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
 
 		findings, err := engine.Run(context.Background(), files)
 		if err != nil {
 			return fmt.Errorf("running linter: %w", err)
 		}
 
+		if !textOutput() {
+			return writeReport(findings, files)
+		}
+
 		// Display results
 		if len(findings) == 0 {
 			fmt.Println("No linting issues found")
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/santosr2/terratidy/internal/engines/policy"
 	"github.com/santosr2/terratidy/pkg/sdk"
@@ -11,9 +13,25 @@ import (
 )
 
 var (
-	policyDirs     []string
-	policyFiles    []string
-	policyShowJSON bool
+	policyDirs       []string
+	policyFiles      []string
+	policyData       []string
+	policyShowJSON   bool
+	policyShowRepo   bool
+	policyPlan       string
+	policyProfile    bool
+	policyEntries    []string
+	policyBundles    []string
+	policyPubKey     string
+	policyLibrary    []string
+	policyExceptions string
+	policyNoBuiltin  bool
+
+	pullName      string
+	pullRevision  string
+	pullCacheDir  string
+	pullKeyID     string
+	pullAlgorithm string
 )
 
 var policyCmd = &cobra.Command{
@@ -32,7 +50,38 @@ Built-in policies include:
   - Required tags on resources
   - Module version constraints
 
-Custom policies can be provided via --policy-dir or --policy-file flags.
+Custom policies can be provided via --policy-dir or --policy-file flags, or
+engines.policy.config in the configuration file. The deny, warn and info rules
+of package terraform and of every package terratidy.<namespace> are evaluated
+as errors, warnings and info; use --entrypoint to evaluate other queries.
+Their deny_repo, warn_repo and info_repo rules are evaluated once against the
+whole repository: every module with its module calls (local sources resolved)
+and backend. Use --show-repo-input to inspect that input.
+
+OPA bundles (.tar.gz files or directories with a .manifest) are loaded with
+--bundle, either by path or by name[@revision] from the cache filled by
+'terratidy policy pull'. With --public-key, bundles must be signed by that key.
+
+The built-in policy library (AWS, GCP, Azure and generic rules) is opt-in:
+select groups with --library all, a provider (aws) or provider/category
+(aws/logging), or enable single rules under engines.policy.config.rules.
+Library policies run alongside custom policies and the built-in policies
+above. Use 'terratidy policy library' to list the library rules. Turn the
+built-in policies off with --no-builtin or builtin: false.
+
+An exceptions register (--exceptions or exceptions_file) waives rules for
+resource addresses or globs. Each exception records a justification, an
+approver and an expiry date: findings it covers are reported as info until it
+expires, and as errors afterwards. Policies see the register as data.exceptions.
+
+JSON/YAML data documents are loaded into data from --data files and from data/
+folders next to the policies. A .terratidy-data.yaml file in a module directory
+or one of its parents overlays that data for the modules below it.
+Use --plan to evaluate policies against a plan exported with
+'terraform show -json'; the plan is available to policies as input.plan.
+Use --profile to report the evaluation time of each policy file.
+Policies that fail to parse, compile or evaluate are reported as
+policy.eval-error findings at the offending line.
 Use --changed to only check files that have been modified in git.`,
 	Example: `  # Run policy checks on current directory
   terratidy policy
@@ -40,6 +89,20 @@ Use --changed to only check files that have been modified in git.`,
   # Run with custom policies
   terratidy policy --policy-dir ./policies
 
+  # Run the AWS and generic rules of the policy library
+  terratidy policy --library aws --library generic
+
+  # Pull a signed bundle into the cache, then evaluate it
+  terratidy policy pull https://example.com/bundles/security.tar.gz --public-key key.pem
+  terratidy policy --bundle security --public-key key.pem
+
+  # Evaluate policies against a plan
+  terraform plan -out tfplan && terraform show -json tfplan > plan.json
+  terratidy policy --plan plan.json --policy-dir ./policies
+
+  # Evaluate a custom entrypoint
+  terratidy policy --policy-dir ./policies --entrypoint data.myorg.security.deny
+
   # Only check changed files
   terratidy policy --changed
 
@@ -52,22 +115,50 @@ Use --changed to only check files that have been modified in git.`,
 			return fmt.Errorf("finding files: %w", err)
 		}
 
-		if len(files) == 0 {
+		if len(files) == 0 && policyPlan == "" {
 			if changed {
-				fmt.Println("No changed HCL files found")
+				statusf("No changed HCL files found\n")
 			} else {
-				fmt.Println("No HCL files found")
+				statusf("No HCL files found\n")
 			}
 			return nil
 		}
 
-		// Create policy engine
-		engine := policy.New(&policy.Config{
-			PolicyDirs:  policyDirs,
-			PolicyFiles: policyFiles,
-		})
+		// Create policy engine; flags add to the configured policies
+		policyCfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		policyCfg.PolicyDirs = append(policyCfg.PolicyDirs, policyDirs...)
+		policyCfg.PolicyFiles = append(policyCfg.PolicyFiles, policyFiles...)
+		policyCfg.DataFiles = append(policyCfg.DataFiles, policyData...)
+		policyCfg.Bundles = append(policyCfg.Bundles, policyBundles...)
+		policyCfg.Library = append(policyCfg.Library, policyLibrary...)
+		if policyExceptions != "" {
+			policyCfg.ExceptionsFile = policyExceptions
+		}
+		if policyPubKey != "" {
+			policyCfg.BundleVerification.PublicKey = policyPubKey
+		}
+		if len(policyEntries) > 0 {
+			policyCfg.Entrypoints = policyEntries
+		}
+		policyCfg.PlanFile = policyPlan
+		policyCfg.Profile = policyProfile
+		if policyNoBuiltin {
+			policyCfg.NoBuiltin = true
+		}
+		engine := policy.New(policyCfg)
 
 		// Show input JSON if requested
+		if policyShowRepo {
+			jsonData, err := engine.GetRepositoryInput(files)
+			if err != nil {
+				return fmt.Errorf("generating repository input JSON: %w", err)
+			}
+			fmt.Println(string(jsonData))
+			return nil
+		}
 		if policyShowJSON {
 			jsonData, err := engine.GetInput(files)
 			if err != nil {
@@ -81,7 +172,11 @@ Use --changed to only check files that have been modified in git.`,
 		if changed {
 			modeMsg = " (changed files only)"
 		}
-		fmt.Printf("Running policy checks on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
+		if policyPlan != "" {
+			statusf("Running policy checks on plan %s...\n\n", policyPlan)
+		} else {
+			statusf("Running policy checks on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
+		}
 
 		// Run policy checks
 		ctx := context.Background()
@@ -90,6 +185,14 @@ Use --changed to only check files that have been modified in git.`,
 			return fmt.Errorf("policy check failed: %w", err)
 		}
 
+		if policyProfile {
+			printPolicyTimings(engine.Timings())
+		}
+
+		if !textOutput() {
+			return writeReport(findings, files)
+		}
+
 		// Display results
 		if len(findings) == 0 {
 			fmt.Println("All policy checks passed!")
@@ -144,6 +247,138 @@ Use --changed to only check files that have been modified in git.`,
 func init() {
 	policyCmd.Flags().StringSliceVar(&policyDirs, "policy-dir", nil, "directories containing Rego policy files")
 	policyCmd.Flags().StringSliceVar(&policyFiles, "policy-file", nil, "individual Rego policy files")
+	policyCmd.Flags().StringSliceVar(&policyData, "data", nil, "JSON/YAML data documents available as data.*")
+	policyCmd.Flags().StringVar(&policyPlan, "plan", "", "terraform plan JSON to evaluate (terraform show -json)")
+	policyCmd.Flags().StringSliceVar(&policyEntries, "entrypoint", nil,
+		"queries to evaluate, e.g. data.myorg.deny (default: deny/warn/info of conventional packages)")
+	policyCmd.Flags().BoolVar(&policyProfile, "profile", false, "report evaluation time per policy file")
 	policyCmd.Flags().BoolVar(&policyShowJSON, "show-input", false, "show input JSON for debugging policies")
+	policyCmd.Flags().BoolVar(&policyShowRepo, "show-repo-input", false,
+		"show the repository input JSON of deny_repo/warn_repo/info_repo policies")
+	policyCmd.Flags().StringSliceVar(&policyBundles, "bundle", nil, "OPA bundles to load: a path or a cached name[@revision]")
+	policyCmd.Flags().StringVar(&policyPubKey, "public-key", "", "PEM public key bundles must be signed with")
+	policyCmd.Flags().StringSliceVar(&policyLibrary, "library", nil,
+		"policy library groups to load: all, a provider (aws) or provider/category (aws/logging)")
+	policyCmd.Flags().BoolVar(&policyNoBuiltin, "no-builtin", false, "skip the built-in policies")
+	policyCmd.Flags().StringVar(&policyExceptions, "exceptions", "", "exceptions register (exceptions.yaml) waiving rules for resources")
+
+	policyPullCmd.Flags().StringVar(&pullName, "name", "", "cache name (default: source file name)")
+	policyPullCmd.Flags().StringVar(&pullRevision, "revision", "", "cache revision (default: manifest revision)")
+	policyPullCmd.Flags().StringVar(&pullCacheDir, "cache-dir", "", "bundle cache directory")
+	policyPullCmd.Flags().StringVar(&policyPubKey, "public-key", "", "PEM public key the bundle must be signed with")
+	policyPullCmd.Flags().StringVar(&pullKeyID, "key-id", "", "signing key ID (default: "+policy.DefaultBundleKeyID+")")
+	policyPullCmd.Flags().StringVar(&pullAlgorithm, "key-algorithm", "",
+		"signing algorithm (default: "+policy.DefaultBundleAlgorithm+")")
+
+	policyLibraryCmd.Flags().StringSliceVar(&policyLibrary, "library", nil, "policy library groups to mark as enabled")
+
+	policyCmd.AddCommand(policyPullCmd)
+	policyCmd.AddCommand(policyLibraryCmd)
 	rootCmd.AddCommand(policyCmd)
 }
+
+var policyPullCmd = &cobra.Command{
+	Use:   "pull <source>",
+	Short: "Pull an OPA bundle into the local cache",
+	Long: `Pull an OPA bundle from a file or an http(s) URL into the local cache.
+
+The bundle is read (and verified, when a public key is given) before it is
+stored as <cache>/<name>/<revision>.tar.gz. Pulled bundles can then be loaded
+with --bundle <name> (the latest pull) or --bundle <name>@<revision>, so
+policy checks run without network access.`,
+	Example: `  # Pull a bundle, named after the file
+  terratidy policy pull https://example.com/bundles/security.tar.gz
+
+  # Pull a signed bundle under an explicit name and revision
+  terratidy policy pull ./build/bundle.tar.gz --name security --revision 1.2.0 --public-key key.pem`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		policyCfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+
+		opts := policy.PullOptions{
+			Name:     pullName,
+			Revision: pullRevision,
+			CacheDir: policyCfg.BundleCache,
+			Verify:   policyCfg.BundleVerification,
+		}
+		if pullCacheDir != "" {
+			opts.CacheDir = pullCacheDir
+		}
+		if policyPubKey != "" {
+			opts.Verify.PublicKey = policyPubKey
+		}
+		if pullKeyID != "" {
+			opts.Verify.KeyID = pullKeyID
+		}
+		if pullAlgorithm != "" {
+			opts.Verify.Algorithm = pullAlgorithm
+		}
+
+		pulled, err := policy.PullBundle(cmd.Context(), args[0], opts)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Pulled %s@%s (%d policies)\n", pulled.Name, pulled.Revision, pulled.Modules)
+		fmt.Printf("  %s\n", pulled.Path)
+		return nil
+	},
+}
+
+var policyLibraryCmd = &cobra.Command{
+	Use:   "library",
+	Short: "List the rules of the built-in policy library",
+	Long: `List the rules of the built-in policy library by provider and category.
+
+A rule is enabled when its group is selected by --library or
+engines.policy.config.library, or when it is enabled under
+engines.policy.config.rules. A rule set to enabled: false there stays
+disabled even when its group is selected.`,
+	Example: `  # List the library and what the configuration enables
+  terratidy policy library
+
+  # Preview a selection
+  terratidy policy library --library aws/exposure,generic`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		policyCfg, err := loadPolicyConfig()
+		if err != nil {
+			return err
+		}
+		policyCfg.Library = append(policyCfg.Library, policyLibrary...)
+
+		rules, err := policy.New(policyCfg).LibraryRules(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		group := ""
+		for _, rule := range rules {
+			if rule.Group != group {
+				if group != "" {
+					fmt.Println()
+				}
+				group = rule.Group
+				fmt.Printf("%s\n", group)
+			}
+			status := " "
+			if rule.Enabled {
+				status = "x"
+			}
+			fmt.Printf("  [%s] %-36s %-8s %s\n", status, strings.TrimPrefix(rule.ID, "policy."), rule.Severity, rule.Title)
+		}
+		return nil
+	},
+}
+
+// printPolicyTimings prints the evaluation time of each policy file, slowest first.
+func printPolicyTimings(timings []policy.PolicyTiming) {
+	statusf("Policy evaluation time:\n")
+	for _, timing := range timings {
+		statusf("  %10s  %s\n", timing.Duration.Round(time.Microsecond), timing.Policy)
+	}
+	statusf("\n")
+}
@@ -11,6 +11,9 @@ var (
 	changed           bool
 	paths             []string
 	severityThreshold string
+	baselineFile      string
+	templateFile      string
+	outputSpecs       []string
 )
 
 var rootCmd = &cobra.Command{
@@ -22,18 +25,36 @@ It provides formatting, style checking, linting, and policy enforcement
 in a single binary with no external dependencies.`,
 	SilenceUsage:  true,
 	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+		return resolveOutputs(cmd)
+	},
 }
 
 func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is .terratidy.yaml)")
 	rootCmd.PersistentFlags().StringVar(&profile, "profile", "", "profile to use from config")
-	rootCmd.PersistentFlags().StringVar(&format, "format", "text", "output format (text|json|sarif)")
+	rootCmd.PersistentFlags().StringVar(
+		&format, "format", "text",
+		"output format (text|json|json-compact|ndjson|sarif|html|markdown|junit|github|gitlab|checkstyle|rdjson|template)",
+	)
 	rootCmd.PersistentFlags().BoolVar(&changed, "changed", false, "only check changed files")
 	rootCmd.PersistentFlags().StringSliceVar(&paths, "paths", []string{}, "paths to check")
 	rootCmd.PersistentFlags().StringVar(
 		&severityThreshold, "severity-threshold", "",
 		"minimum severity level to fail (info|warning|error)",
 	)
+	rootCmd.PersistentFlags().StringVar(
+		&baselineFile, "baseline", "",
+		"previous --format json result to compare findings against (markdown, html)",
+	)
+	rootCmd.PersistentFlags().StringVar(
+		&templateFile, "template-file", "",
+		"Go text/template file rendering the findings (template)",
+	)
+	rootCmd.PersistentFlags().StringArrayVar(
+		&outputSpecs, "output", nil,
+		"report to write, as format or format=path; repeat for several reports (replaces --format)",
+	)
 }
 
 // Execute runs the root command
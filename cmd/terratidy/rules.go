@@ -2,14 +2,19 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"text/tabwriter"
 
 	"github.com/santosr2/terratidy/internal/engines/lint"
+	"github.com/santosr2/terratidy/internal/engines/policy"
 	"github.com/santosr2/terratidy/internal/engines/style"
+	"github.com/santosr2/terratidy/internal/output"
+	"github.com/santosr2/terratidy/pkg/sdk"
 	"github.com/spf13/cobra"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -73,12 +78,22 @@ func init() {
 // RuleInfo holds information about a rule for display.
 type RuleInfo struct {
 	Name        string
+	Title       string
 	Description string
+	HelpURI     string
 	Engine      string
 	Severity    string
 	Enabled     bool
 }
 
+// Rule documentation pages, used as help URIs in reports
+const (
+	docsURL       = "https://santosr2.github.io/terratidy"
+	styleRulesURL = docsURL + "/rules/style-rules/"
+	lintRulesURL  = docsURL + "/rules/lint-rules/"
+	policyURL     = docsURL + "/user-guide/engines/policy/"
+)
+
 func runRulesList(_ *cobra.Command, _ []string) error {
 	rules := getAllRules()
 
@@ -202,6 +217,7 @@ func getAllRules() []RuleInfo {
 		rules = append(rules, RuleInfo{
 			Name:        rule.Name(),
 			Description: getStyleRuleDescription(rule.Name()),
+			HelpURI:     styleRulesURL,
 			Engine:      "style",
 			Severity:    "warning",
 			Enabled:     true,
@@ -214,6 +230,7 @@ func getAllRules() []RuleInfo {
 		rules = append(rules, RuleInfo{
 			Name:        rule.Name(),
 			Description: rule.Description(),
+			HelpURI:     lintRulesURL,
 			Engine:      "lint",
 			Severity:    "warning",
 			Enabled:     true,
@@ -224,59 +241,78 @@ func getAllRules() []RuleInfo {
 	rules = append(rules, RuleInfo{
 		Name:        "lint.tflint",
 		Description: "TFLint integration - runs all enabled TFLint rules (configure via .tflint.hcl)",
+		HelpURI:     lintRulesURL,
 		Engine:      "lint",
 		Severity:    "variable",
 		Enabled:     true,
 	})
 
-	// Add policy rules (built-in)
-	policyRules := []RuleInfo{
-		{
-			Name:        "policy.required-terraform-block",
-			Description: "Require terraform block with required_version",
-			Engine:      "policy", Severity: "warning", Enabled: true,
-		},
-		{
-			Name:        "policy.required-version",
-			Description: "Require required_version in terraform block",
-			Engine:      "policy", Severity: "warning", Enabled: true,
-		},
-		{
-			Name:        "policy.required-providers",
-			Description: "Require required_providers block when using providers",
-			Engine:      "policy", Severity: "warning", Enabled: true,
-		},
-		{
-			Name:        "policy.no-public-ssh",
-			Description: "Disallow security groups with public SSH access",
-			Engine:      "policy", Severity: "error", Enabled: true,
-		},
-		{
-			Name:        "policy.no-public-s3",
-			Description: "Disallow S3 buckets with public-read ACL",
-			Engine:      "policy", Severity: "error", Enabled: true,
-		},
-		{
-			Name:        "policy.no-public-rds",
-			Description: "Disallow publicly accessible RDS instances",
-			Engine:      "policy", Severity: "error", Enabled: true,
-		},
-		{
-			Name:        "policy.required-tags",
-			Description: "Require tags on taggable resources",
-			Engine:      "policy", Severity: "warning", Enabled: true,
-		},
-		{
-			Name:        "policy.module-version",
-			Description: "Require version constraint on external modules",
-			Engine:      "policy", Severity: "warning", Enabled: true,
-		},
+	// Add policy rules from the configured (or built-in) policies
+	rules = append(rules, getPolicyRules()...)
+
+	return rules
+}
+
+// getPolicyRules lists the rules of the configured policies from their METADATA
+// annotations, falling back to the built-in policies.
+func getPolicyRules() []RuleInfo {
+	policyCfg, err := loadPolicyConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v; listing built-in policies\n", err)
 	}
-	rules = append(rules, policyRules...)
 
+	metadata, err := policy.New(policyCfg).Rules(context.Background())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: listing policy rules: %v\n", err)
+		return nil
+	}
+
+	rules := make([]RuleInfo, 0, len(metadata))
+	for _, meta := range metadata {
+		desc := meta.Description
+		if desc == "" {
+			desc = meta.Title
+		}
+		if desc == "" {
+			desc = fmt.Sprintf("Defined in %s (%s)", meta.File, meta.Entrypoint)
+		}
+		helpURI := policyURL
+		if len(meta.RelatedResources) > 0 {
+			helpURI = meta.RelatedResources[0]
+		}
+		rules = append(rules, RuleInfo{
+			Name:        meta.ID,
+			Title:       meta.Title,
+			Description: desc,
+			HelpURI:     helpURI,
+			Engine:      "policy",
+			Severity:    string(meta.Severity),
+			Enabled:     true,
+		})
+	}
 	return rules
 }
 
+// ruleDescriptors returns the metadata of all rules for reports that describe
+// the rules of their findings. Collecting them compiles the policies, so it
+// happens once per process and the reports share the read-only map.
+var ruleDescriptors = sync.OnceValue(func() map[string]output.RuleDescriptor {
+	descriptors := make(map[string]output.RuleDescriptor)
+	for _, rule := range getAllRules() {
+		descriptor := output.RuleDescriptor{
+			Title:       rule.Title,
+			Description: rule.Description,
+			HelpURI:     rule.HelpURI,
+		}
+		switch severity := sdk.Severity(rule.Severity); severity {
+		case sdk.SeverityError, sdk.SeverityWarning, sdk.SeverityInfo:
+			descriptor.Severity = severity
+		}
+		descriptors[rule.Name] = descriptor
+	}
+	return descriptors
+})
+
 // getStyleRuleDescription returns a description for a style rule.
 func getStyleRuleDescription(name string) string {
 	descriptions := map[string]string{
@@ -292,6 +328,11 @@ func getStyleRuleDescription(name string) string {
 		"style.terraform-block-first":      "Place terraform block first in file",
 		"style.provider-block-order":       "Order provider blocks after terraform block",
 		"style.no-empty-blocks":            "Disallow empty blocks",
+		"style.heredoc-indented":           "Prefer indented heredocs (<<-EOT)",
+		"style.policy-jsonencode":          "Use jsonencode() for JSON policy documents",
+		"style.trailing-comma":             "Require trailing commas in multi-line lists",
+		"style.long-expression-wrap":       "Wrap long conditional and for expressions",
+		"style.no-interpolation-wrapper":   "Disallow interpolation-only \"${}\" templates",
 	}
 
 	if desc, ok := descriptions[name]; ok {
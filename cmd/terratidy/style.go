@@ -46,9 +46,9 @@ Use --fix to automatically fix fixable style issues.`,
 
 		if len(files) == 0 {
 			if changed {
-				fmt.Println("No changed HCL files found")
+				statusf("No changed HCL files found\n")
 			} else {
-				fmt.Println("No HCL files found")
+				statusf("No HCL files found\n")
 			}
 			return nil
 		}
@@ -63,7 +63,7 @@ Use --fix to automatically fix fixable style issues.`,
 		if changed {
 			modeMsg = " (changed files only)"
 		}
-		fmt.Printf("Checking style on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
+		statusf("Checking style on %s%s...\n\n", formatFileCount(len(files)), modeMsg)
 
 		// Run style checks
 		findings, err := engine.Run(context.Background(), files)
@@ -71,6 +71,10 @@ Use --fix to automatically fix fixable style issues.`,
 			return fmt.Errorf("checking style: %w", err)
 		}
 
+		if !textOutput() {
+			return writeReport(findings, files)
+		}
+
 		// Display results
 		if len(findings) == 0 {
 			fmt.Println("No style issues found")
@@ -3,38 +3,71 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/open-policy-agent/opa/v1/cover"
 	"github.com/santosr2/terratidy/internal/engines/policy"
+	"github.com/santosr2/terratidy/internal/plugins"
+	"github.com/santosr2/terratidy/internal/ruletest"
 	"github.com/santosr2/terratidy/pkg/sdk"
 	"github.com/spf13/cobra"
-	"gopkg.in/yaml.v3"
 )
 
 var (
 	testRuleFixtures string
 	testRuleExpect   string
 	testRuleVerbose  bool
+	testRuleCoverage bool
+	testRuleJUnit    string
 )
 
 var testRuleCmd = &cobra.Command{
 	Use:   "test-rule [rule-path]",
 	Short: "Test a specific rule",
-	Long: `Test a rule against fixture files and expected findings.
-
-This command allows you to test custom rules during development.
-It runs the rule against test fixtures and compares the findings
-with expected results.`,
-	Example: `  # Test a Rego policy
+	Long: `Test a rule with its unit tests and against fixture files.
+
+Supported rules:
+  - Rego policies (.rego file or directory): the test_* rules in the policies
+    and their _test.rego files run with the OPA test runner (opa test
+    semantics; todo_test_* rules are skipped), then the policies run against
+    the fixtures
+  - YAML rules (.yaml): the examples in the rule must pass (good) and fail
+    (bad), then the rule runs against the fixtures
+  - Go rule plugins (.so): the plugin's rules run against the fixtures
+  - Go rule sources (directory of .go files): go test runs the rule's tests
+
+Fixtures are directories with the Terraform to check under input/ and the
+findings it must produce in expected.yaml next to it:
+
+  test_fixtures/
+    public-bucket/
+      input/main.tf
+      expected.yaml     # findings: [{rule: no-public-s3, file: main.tf, line: 1}]
+    compliant/
+      input/main.tf     # no expected.yaml: must produce no findings
+
+Expected findings match on rule (with or without the engine prefix),
+severity, message substring, file and line; every finding must be expected.
+Without fixture directories, the .tf files in --fixtures are checked together
+and compared with --expect, if given.`,
+	Example: `  # Run the tests of a Rego policy and its fixtures
   terratidy test-rule ./policies/my-rule.rego
 
+  # Run all policy tests with coverage
+  terratidy test-rule ./policies --coverage
+
   # Test with specific fixtures directory
   terratidy test-rule ./policies/my-rule.rego --fixtures ./test_fixtures
 
+  # Test a YAML rule and write a JUnit report for CI
+  terratidy test-rule ./rules/s3-encryption.yaml --junit rule-tests.xml
+
   # Test with expected findings file
   terratidy test-rule ./policies/my-rule.rego --expect ./expected.yaml`,
 	Args: cobra.ExactArgs(1),
@@ -43,212 +76,395 @@ with expected results.`,
 
 func init() {
 	testRuleCmd.Flags().StringVar(&testRuleFixtures, "fixtures", "test_fixtures/", "fixtures directory")
-	testRuleCmd.Flags().StringVar(&testRuleExpect, "expect", "", "expected findings file (YAML or JSON)")
+	testRuleCmd.Flags().StringVar(&testRuleExpect, "expect", "",
+		"expected findings file (YAML or JSON) for fixtures without case directories")
 	testRuleCmd.Flags().BoolVarP(&testRuleVerbose, "verbose", "v", false, "verbose output")
+	testRuleCmd.Flags().BoolVar(&testRuleCoverage, "coverage", false, "report Rego policy coverage")
+	testRuleCmd.Flags().StringVar(&testRuleJUnit, "junit", "", "write a JUnit XML report to this file ('-' for stdout)")
 	rootCmd.AddCommand(testRuleCmd)
 }
 
-// ExpectedFinding represents an expected finding in test fixtures.
-type ExpectedFinding struct {
-	Rule     string `yaml:"rule" json:"rule"`
-	Severity string `yaml:"severity" json:"severity"`
-	Message  string `yaml:"message" json:"message"`
-	File     string `yaml:"file" json:"file"`
-}
+// testOutput receives the human-readable report; it is stderr when the JUnit
+// report goes to stdout.
+var testOutput io.Writer = os.Stdout
 
-// ExpectedResults represents expected test results.
-type ExpectedResults struct {
-	Findings []ExpectedFinding `yaml:"findings" json:"findings"`
-}
-
-func runTestRule(_ *cobra.Command, args []string) error {
+func runTestRule(cmd *cobra.Command, args []string) error {
 	rulePath := args[0]
 
 	// Check if rule file exists
-	if _, err := os.Stat(rulePath); os.IsNotExist(err) {
+	info, err := os.Stat(rulePath)
+	if os.IsNotExist(err) {
 		return fmt.Errorf("rule file not found: %s", rulePath)
 	}
+	if err != nil {
+		return err
+	}
 
-	fmt.Printf("Testing rule: %s\n\n", rulePath)
+	if testRuleJUnit == "-" {
+		testOutput = os.Stderr
+	}
+	_, _ = fmt.Fprintf(testOutput, "Testing rule: %s\n\n", rulePath)
 
 	// Determine rule type based on extension
+	ctx := cmd.Context()
+	var results []ruletest.Result
 	ext := strings.ToLower(filepath.Ext(rulePath))
-	switch ext {
-	case ".rego":
-		return testRegoRule(rulePath)
+	switch {
+	case info.IsDir() && isGoRuleDir(rulePath):
+		results, err = testGoSourceRule(ctx, rulePath)
+	case info.IsDir() || ext == ".rego":
+		results, err = testRegoRule(ctx, rulePath, info.IsDir())
+	case ext == ".yaml" || ext == ".yml":
+		results, err = testYAMLRule(rulePath)
+	case ext == ".so":
+		results, err = testGoPluginRule(rulePath)
 	default:
 		return fmt.Errorf("unsupported rule type: %s", ext)
 	}
+	if err != nil {
+		return err
+	}
+
+	return reportTestResults(results)
 }
 
-func testRegoRule(rulePath string) error {
-	// Find fixture files
-	fixtures, err := findFixtures(testRuleFixtures)
-	if err != nil {
-		return fmt.Errorf("finding fixtures: %w", err)
+// isGoRuleDir reports whether a directory holds Go rule sources.
+func isGoRuleDir(dir string) bool {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	return len(matches) > 0
+}
+
+func testRegoRule(ctx context.Context, rulePath string, isDir bool) ([]ruletest.Result, error) {
+	// Only the rule under test: built-in policies would add their own
+	// findings to the fixtures and their files to the coverage
+	cfg := &policy.Config{NoBuiltin: true}
+	if isDir {
+		cfg.PolicyDirs = []string{rulePath}
+	} else {
+		cfg.PolicyFiles = []string{rulePath}
+		// Tests conventionally live next to the policy in <name>_test.rego
+		testFile := strings.TrimSuffix(rulePath, ".rego") + "_test.rego"
+		if !strings.HasSuffix(rulePath, "_test.rego") {
+			if _, err := os.Stat(testFile); err == nil {
+				cfg.PolicyFiles = append(cfg.PolicyFiles, testFile)
+			}
+		}
 	}
+	engine := policy.New(cfg)
 
-	if len(fixtures) == 0 {
-		fmt.Printf("No fixtures found in %s\n", testRuleFixtures)
-		fmt.Println()
-		fmt.Println("Create test fixtures:")
-		fmt.Printf("  mkdir -p %s\n", testRuleFixtures)
-		fmt.Printf("  # Add .tf files to test against\n")
-		return nil
+	report, err := engine.Test(ctx, testRuleCoverage)
+	if err != nil {
+		return nil, fmt.Errorf("running policy tests: %w", err)
 	}
 
-	fmt.Printf("Found %d fixture file(s)\n\n", len(fixtures))
+	results := make([]ruletest.Result, 0, len(report.Results))
+	for _, r := range report.Results {
+		results = append(results, ruletest.Result{
+			Suite:    "rego",
+			Name:     r.Name,
+			File:     fmt.Sprintf("%s:%d", r.File, r.Line),
+			Duration: r.Duration,
+			Skipped:  r.Skipped,
+			Failure:  r.Failure,
+			Output:   r.Output,
+		})
+	}
+	if len(results) > 0 {
+		_, _ = fmt.Fprintf(testOutput, "Policy tests: %d test rule(s)\n\n", len(results))
+		printTestResults(results)
+	}
+	if report.Coverage != nil {
+		printCoverage(report.Coverage)
+	}
 
-	// Create policy engine with the rule
-	engine := policy.New(&policy.Config{
-		PolicyFiles: []string{rulePath},
+	fixtureResults, err := runFixtures(func(files []string) ([]sdk.Finding, error) {
+		return engine.Run(ctx, files)
 	})
-
-	// Run the rule against fixtures
-	ctx := context.Background()
-	findings, err := engine.Run(ctx, fixtures)
 	if err != nil {
-		return fmt.Errorf("running rule: %w", err)
+		return nil, err
 	}
+	return append(results, fixtureResults...), nil
+}
 
-	// Display findings
-	fmt.Printf("Results: %d finding(s)\n\n", len(findings))
-
-	for _, finding := range findings {
-		icon := "i"
-		switch finding.Severity {
-		case sdk.SeverityError:
-			icon = "!"
-		case sdk.SeverityWarning:
-			icon = "!"
+func testYAMLRule(rulePath string) ([]ruletest.Result, error) {
+	rule, err := plugins.LoadYAMLRule(rulePath)
+	if err != nil {
+		return nil, err
+	}
+	rules := []sdk.Rule{rule}
+
+	// The examples in the rule are its unit tests
+	var results []ruletest.Result
+	examples := []struct {
+		name, src string
+		fails     bool
+	}{
+		{"examples/good", rule.Examples.Good, false},
+		{"examples/bad", rule.Examples.Bad, true},
+	}
+	for _, ex := range examples {
+		if strings.TrimSpace(ex.src) == "" {
+			continue
 		}
-
-		fmt.Printf("  [%s] %s\n", icon, finding.Rule)
-		fmt.Printf("      %s\n", finding.Message)
-		if finding.File != "" {
-			fmt.Printf("      File: %s\n", finding.File)
+		start := time.Now()
+		result := ruletest.Result{Suite: "examples", Name: ex.name, File: rulePath}
+		findings, err := ruletest.CheckSource(rules, ex.name+".tf", []byte(ex.src))
+		switch {
+		case err != nil:
+			result.Failure = err.Error()
+		case ex.fails && len(findings) == 0:
+			result.Failure = "expected the bad example to produce findings"
+		case !ex.fails && len(findings) > 0:
+			result.Failure = fmt.Sprintf("expected no findings for the good example, got: %s", findings[0].Message)
 		}
-		fmt.Println()
+		result.Duration = time.Since(start)
+		results = append(results, result)
 	}
-
-	// Compare with expected findings if provided
-	if testRuleExpect != "" {
-		return compareExpected(findings, testRuleExpect)
+	if len(results) > 0 {
+		_, _ = fmt.Fprintf(testOutput, "Rule examples: %d example(s)\n\n", len(results))
+		printTestResults(results)
 	}
 
-	return nil
+	fixtureResults, err := runFixtures(func(files []string) ([]sdk.Finding, error) {
+		return ruletest.RunRules(rules, files)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(results, fixtureResults...), nil
 }
 
-func findFixtures(dir string) ([]string, error) {
-	var fixtures []string
+func testGoPluginRule(rulePath string) ([]ruletest.Result, error) {
+	manager := plugins.NewManager(nil)
+	if err := manager.LoadPlugin(rulePath); err != nil {
+		return nil, err
+	}
 
-	if _, err := os.Stat(dir); os.IsNotExist(err) {
-		return fixtures, nil
+	var names []string
+	ruleMap := manager.GetRules()
+	for name := range ruleMap {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s provides no rules", rulePath)
 	}
+	sort.Strings(names)
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-		if !info.IsDir() && isHCLFile(path) {
-			fixtures = append(fixtures, path)
-		}
-		return nil
-	})
+	rules := make([]sdk.Rule, 0, len(names))
+	for _, name := range names {
+		rules = append(rules, ruleMap[name])
+	}
+	_, _ = fmt.Fprintf(testOutput, "Plugin rules: %s\n\n", strings.Join(names, ", "))
 
-	return fixtures, err
+	return runFixtures(func(files []string) ([]sdk.Finding, error) {
+		return ruletest.RunRules(rules, files)
+	})
 }
 
-func compareExpected(findings []sdk.Finding, expectFile string) error {
-	// Load expected findings
-	data, err := os.ReadFile(expectFile)
+func testGoSourceRule(ctx context.Context, dir string) ([]ruletest.Result, error) {
+	_, _ = fmt.Fprintln(testOutput, "Running go test...")
+	_, _ = fmt.Fprintln(testOutput)
+
+	results, err := ruletest.RunGoTests(ctx, dir)
 	if err != nil {
-		return fmt.Errorf("reading expected file: %w", err)
+		return nil, err
 	}
+	printTestResults(results)
+	return results, nil
+}
 
-	var expected ExpectedResults
-	ext := strings.ToLower(filepath.Ext(expectFile))
+// runFixtures checks each fixture case and compares the findings with the
+// expected ones. Without case directories, the fixture files are checked
+// together against --expect.
+func runFixtures(check func(files []string) ([]sdk.Finding, error)) ([]ruletest.Result, error) {
+	cases, err := ruletest.FindCases(testRuleFixtures)
+	if err != nil {
+		return nil, fmt.Errorf("finding fixtures: %w", err)
+	}
 
-	switch ext {
-	case ".json":
-		if err := json.Unmarshal(data, &expected); err != nil {
-			return fmt.Errorf("parsing JSON: %w", err)
+	if len(cases) == 0 {
+		files, err := ruletest.FindFiles(testRuleFixtures)
+		if err != nil {
+			return nil, fmt.Errorf("finding fixtures: %w", err)
 		}
-	case ".yaml", ".yml":
-		if err := yaml.Unmarshal(data, &expected); err != nil {
-			return fmt.Errorf("parsing YAML: %w", err)
+		if len(files) == 0 {
+			_, _ = fmt.Fprintf(testOutput, "No fixtures found in %s\n\n", testRuleFixtures)
+			_, _ = fmt.Fprintln(testOutput, "Create test fixtures:")
+			_, _ = fmt.Fprintf(testOutput, "  mkdir -p %s\n", filepath.Join(testRuleFixtures, "my-case", "input"))
+			_, _ = fmt.Fprintln(testOutput, "  # Add .tf files to input/ and the expected findings to expected.yaml")
+			_, _ = fmt.Fprintln(testOutput)
+			return nil, nil
 		}
-	default:
-		return fmt.Errorf("unsupported expected file format: %s", ext)
+
+		c := ruletest.Case{Name: testRuleFixtures, Dir: testRuleFixtures, Files: files}
+		if testRuleExpect != "" {
+			if c.Expected, err = ruletest.LoadExpected(testRuleExpect); err != nil {
+				return nil, err
+			}
+		}
+		cases = append(cases, c)
 	}
 
-	// Compare findings
-	fmt.Println("---")
-	fmt.Println("Comparing with expected findings...")
-	fmt.Println()
+	_, _ = fmt.Fprintf(testOutput, "Fixtures: %d case(s)\n\n", len(cases))
 
-	passed := true
-	matched := make(map[int]bool)
+	var results []ruletest.Result
+	for _, c := range cases {
+		start := time.Now()
+		findings, err := check(c.Files)
+		duration := time.Since(start)
 
-	// Check each expected finding
-	for _, exp := range expected.Findings {
-		found := false
-		for i, actual := range findings {
-			if matched[i] {
-				continue
-			}
-			if matchesFinding(exp, actual) {
-				matched[i] = true
-				found = true
-				break
+		if c.Expected == nil {
+			// Nothing to compare with: show what the rule reports
+			if err != nil {
+				return nil, fmt.Errorf("running rule: %w", err)
 			}
+			printFindings(findings)
+			continue
 		}
 
-		if found {
-			fmt.Printf("  [+] Expected finding matched: %s\n", exp.Rule)
+		result := ruletest.Result{Suite: "fixtures", Name: c.Name, File: c.Dir, Duration: duration}
+		if err != nil {
+			result.Failure = err.Error()
 		} else {
-			fmt.Printf("  [-] Expected finding NOT found: %s\n", exp.Rule)
-			if exp.Message != "" {
-				fmt.Printf("      Message: %s\n", exp.Message)
-			}
-			passed = false
+			result.Failure = strings.Join(ruletest.Compare(c.Expected, findings, c.Dir), "\n")
 		}
+		if testRuleVerbose {
+			printFindings(findings)
+		}
+		results = append(results, result)
 	}
 
-	// Check for unexpected findings
-	for i, actual := range findings {
-		if !matched[i] {
-			fmt.Printf("  [?] Unexpected finding: %s\n", actual.Rule)
-			fmt.Printf("      Message: %s\n", actual.Message)
-			passed = false
+	printTestResults(results)
+	return results, nil
+}
+
+func printFindings(findings []sdk.Finding) {
+	_, _ = fmt.Fprintf(testOutput, "Results: %d finding(s)\n\n", len(findings))
+
+	for _, finding := range findings {
+		icon := "i"
+		switch finding.Severity {
+		case sdk.SeverityError:
+			icon = "!"
+		case sdk.SeverityWarning:
+			icon = "!"
+		}
+
+		_, _ = fmt.Fprintf(testOutput, "  [%s] %s\n", icon, finding.Rule)
+		_, _ = fmt.Fprintf(testOutput, "      %s\n", finding.Message)
+		if finding.File != "" {
+			_, _ = fmt.Fprintf(testOutput, "      File: %s\n", finding.File)
 		}
+		_, _ = fmt.Fprintln(testOutput)
 	}
+}
 
-	fmt.Println()
+func printTestResults(results []ruletest.Result) {
+	for _, r := range results {
+		status := "PASS"
+		switch {
+		case r.Skipped:
+			status = "SKIP"
+		case r.Failure != "":
+			status = "FAIL"
+		}
 
-	if passed {
-		fmt.Println("All tests passed!")
-		return nil
+		_, _ = fmt.Fprintf(testOutput, "  [%s] %s", status, r.Name)
+		if !r.Skipped {
+			_, _ = fmt.Fprintf(testOutput, " (%s)", r.Duration.Round(time.Microsecond))
+		}
+		_, _ = fmt.Fprintln(testOutput)
+		if r.Failure != "" {
+			for _, line := range strings.Split(r.Failure, "\n") {
+				_, _ = fmt.Fprintf(testOutput, "      %s\n", line)
+			}
+		}
+		if r.Output != "" && (testRuleVerbose || r.Failure != "") {
+			for _, line := range strings.Split(strings.TrimRight(r.Output, "\n"), "\n") {
+				_, _ = fmt.Fprintf(testOutput, "      | %s\n", line)
+			}
+		}
+	}
+	if len(results) > 0 {
+		_, _ = fmt.Fprintln(testOutput)
 	}
+}
 
-	return fmt.Errorf("test failed: expected findings do not match actual findings")
+// printCoverage prints the coverage of each policy file and the lines no test
+// reached.
+func printCoverage(report *cover.Report) {
+	files := make([]string, 0, len(report.Files))
+	for file := range report.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	_, _ = fmt.Fprintf(testOutput, "Coverage: %.1f%%\n", report.Coverage)
+	for _, file := range files {
+		fr := report.Files[file]
+		_, _ = fmt.Fprintf(testOutput, "  %6.1f%%  %s", fr.Coverage, file)
+		var lines []string
+		for _, r := range fr.NotCovered {
+			if r.Start.Row == r.End.Row {
+				lines = append(lines, fmt.Sprint(r.Start.Row))
+			} else {
+				lines = append(lines, fmt.Sprintf("%d-%d", r.Start.Row, r.End.Row))
+			}
+		}
+		if len(lines) > 0 {
+			_, _ = fmt.Fprintf(testOutput, " (not covered: %s)", strings.Join(lines, ", "))
+		}
+		_, _ = fmt.Fprintln(testOutput)
+	}
+	_, _ = fmt.Fprintln(testOutput)
 }
 
-func matchesFinding(expected ExpectedFinding, actual sdk.Finding) bool {
-	// Rule must match
-	if expected.Rule != "" && !strings.HasSuffix(actual.Rule, expected.Rule) {
-		return false
+// reportTestResults prints the summary, writes the JUnit report and fails
+// when any test failed.
+func reportTestResults(results []ruletest.Result) error {
+	if testRuleJUnit != "" {
+		if err := writeJUnitReport(results); err != nil {
+			return err
+		}
+	}
+	if len(results) == 0 {
+		return nil
 	}
 
-	// Severity must match if specified
-	if expected.Severity != "" && string(actual.Severity) != expected.Severity {
-		return false
+	passed, failed, skipped := 0, 0, 0
+	for _, r := range results {
+		switch {
+		case r.Skipped:
+			skipped++
+		case r.Failure != "":
+			failed++
+		default:
+			passed++
+		}
 	}
 
-	// Message must contain if specified
-	if expected.Message != "" && !strings.Contains(actual.Message, expected.Message) {
-		return false
+	_, _ = fmt.Fprintln(testOutput, "---")
+	_, _ = fmt.Fprintf(testOutput, "Test summary: %d passed, %d failed, %d skipped\n", passed, failed, skipped)
+
+	if failed > 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	_, _ = fmt.Fprintln(testOutput, "All tests passed!")
+	return nil
+}
+
+func writeJUnitReport(results []ruletest.Result) error {
+	if testRuleJUnit == "-" {
+		return ruletest.WriteJUnit(os.Stdout, results)
 	}
 
-	return true
+	f, err := os.Create(testRuleJUnit)
+	if err != nil {
+		return fmt.Errorf("creating JUnit report: %w", err)
+	}
+	if err := ruletest.WriteJUnit(f, results); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing JUnit report: %w", err)
+	}
+	return f.Close()
 }
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTestRegoRule_OnlyRuleUnderTest(t *testing.T) {
+	dir := t.TempDir()
+	rulePath := filepath.Join(dir, "buckets.rego")
+	writeTestFile(t, rulePath, `package terratidy.buckets
+
+import rego.v1
+
+deny contains msg if {
+    some r in input.resources
+    r.type == "aws_s3_bucket"
+    msg := {"msg": sprintf("bucket %s", [r.name]), "rule": "no-buckets"}
+}
+`)
+	writeTestFile(t, filepath.Join(dir, "buckets_test.rego"), `package terratidy.buckets_test
+
+import rego.v1
+
+import data.terratidy.buckets
+
+test_bucket if {
+    count(buckets.deny) == 1 with input as {"resources": [{"type": "aws_s3_bucket", "name": "logs"}]}
+}
+`)
+
+	// No terraform block: a built-in policy would report it and fail the case
+	fixtures := filepath.Join(dir, "test_fixtures")
+	writeTestFile(t, filepath.Join(fixtures, "bucket", "input", "main.tf"), `resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+}
+`)
+	writeTestFile(t, filepath.Join(fixtures, "bucket", "expected.yaml"), `findings:
+  - rule: no-buckets
+    message: bucket logs
+`)
+
+	oldFixtures, oldCoverage, oldOutput := testRuleFixtures, testRuleCoverage, testOutput
+	testRuleFixtures, testRuleCoverage, testOutput = fixtures, true, io.Discard
+	t.Cleanup(func() {
+		testRuleFixtures, testRuleCoverage, testOutput = oldFixtures, oldCoverage, oldOutput
+	})
+
+	results, err := testRegoRule(context.Background(), rulePath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected the rego test and the fixture case, got %d results", len(results))
+	}
+	for _, r := range results {
+		if !r.Passed() {
+			t.Errorf("%s/%s failed: %s", r.Suite, r.Name, r.Failure)
+		}
+	}
+}
@@ -36,6 +36,9 @@ type Config struct {
 
 	// Custom rules
 	CustomRules map[string]RuleConfig `yaml:"custom_rules,omitempty"`
+
+	// Reports written by each run; --output replaces them
+	Outputs []OutputConfig `yaml:"outputs,omitempty"`
 }
 
 // Engines configuration for each engine
@@ -74,6 +77,13 @@ type RuleConfig struct {
 	Config   map[string]interface{} `yaml:"config,omitempty"`
 }
 
+// OutputConfig represents a report written by a run
+type OutputConfig struct {
+	Format   string `yaml:"format"`
+	Path     string `yaml:"path,omitempty"`     // File to write; empty or "-" for stdout
+	Template string `yaml:"template,omitempty"` // Template file of the template format
+}
+
 // PluginsConfig represents plugin settings
 type PluginsConfig struct {
 	Enabled     bool     `yaml:"enabled"`
@@ -261,6 +271,11 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("plugins validation: %w", err)
 	}
 
+	// Validate outputs
+	if err := ValidateOutputs(c.Outputs); err != nil {
+		return fmt.Errorf("outputs validation: %w", err)
+	}
+
 	return nil
 }
 
@@ -354,6 +369,34 @@ func (c *Config) validatePlugins() error {
 	return nil
 }
 
+// ValidateOutputs validates a list of outputs: each has a format, at most one
+// writes to stdout and no two write to the same file
+func ValidateOutputs(outputs []OutputConfig) error {
+	stdout := ""
+	paths := make(map[string]bool)
+	for _, out := range outputs {
+		if out.Format == "" {
+			return fmt.Errorf("output format cannot be empty")
+		}
+
+		if out.Path == "" || out.Path == "-" {
+			if stdout != "" {
+				return fmt.Errorf("outputs %s and %s both write to stdout", stdout, out.Format)
+			}
+			stdout = out.Format
+			continue
+		}
+
+		path := filepath.Clean(out.Path)
+		if paths[path] {
+			return fmt.Errorf("several outputs write to %s", out.Path)
+		}
+		paths[path] = true
+	}
+
+	return nil
+}
+
 // GetProfile returns a profile with all inherited settings resolved
 func (c *Config) GetProfile(name string) (*Profile, error) {
 	profile, exists := c.Profiles[name]
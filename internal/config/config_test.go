@@ -409,6 +409,51 @@ func TestValidate_EmptyPluginDirectory(t *testing.T) {
 	assert.Contains(t, err.Error(), "plugin directory cannot be empty")
 }
 
+func TestValidate_Outputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs []OutputConfig
+		wantErr string
+	}{
+		{
+			name: "valid",
+			outputs: []OutputConfig{
+				{Format: "text"},
+				{Format: "sarif", Path: "results.sarif"},
+				{Format: "junit", Path: "reports/junit.xml"},
+			},
+		},
+		{
+			name:    "missing format",
+			outputs: []OutputConfig{{Path: "results.sarif"}},
+			wantErr: "output format cannot be empty",
+		},
+		{
+			name:    "two stdout outputs",
+			outputs: []OutputConfig{{Format: "text"}, {Format: "json", Path: "-"}},
+			wantErr: "outputs text and json both write to stdout",
+		},
+		{
+			name:    "same file",
+			outputs: []OutputConfig{{Format: "json", Path: "out.json"}, {Format: "sarif", Path: "./out.json"}},
+			wantErr: "several outputs write to ./out.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{Version: 1, Outputs: tt.outputs}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestValidate_ValidProfileInheritance(t *testing.T) {
 	cfg := &Config{
 		Version: 1,
@@ -0,0 +1,363 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// diffContext is the number of unchanged lines shown around each hunk
+const diffContext = 3
+
+// LineKind identifies the kind of a line in a diff hunk
+type LineKind string
+
+// Line kinds used in diff hunks
+const (
+	LineContext LineKind = "context"
+	LineDelete  LineKind = "delete"
+	LineInsert  LineKind = "insert"
+)
+
+// Diff is a structured unified diff between the original and formatted content of a file
+type Diff struct {
+	File  string `json:"file"`
+	Hunks []Hunk `json:"hunks"`
+
+	edits []sdk.TextEdit
+}
+
+// Hunk is a contiguous group of changes with surrounding context lines
+type Hunk struct {
+	OldStart int        `json:"old_start"`
+	OldLines int        `json:"old_lines"`
+	NewStart int        `json:"new_start"`
+	NewLines int        `json:"new_lines"`
+	Lines    []DiffLine `json:"lines"`
+}
+
+// DiffLine is a single line in a hunk. Text includes the trailing newline, if any
+type DiffLine struct {
+	Kind LineKind `json:"kind"`
+	Text string   `json:"text"`
+}
+
+// ComputeDiff returns the line diff between before and after
+func ComputeDiff(file string, before, after []byte) *Diff {
+	oldLines := splitLines(string(before))
+	newLines := splitLines(string(after))
+	ops := diffLines(oldLines, newLines)
+
+	return &Diff{
+		File:  file,
+		Hunks: buildHunks(ops, diffContext),
+		edits: buildEdits(file, ops),
+	}
+}
+
+// Empty reports whether the diff has no changes
+func (d *Diff) Empty() bool {
+	return len(d.Hunks) == 0
+}
+
+// Fix returns the diff as text edits against the original content
+func (d *Diff) Fix() *sdk.Fix {
+	if d.Empty() {
+		return nil
+	}
+	return &sdk.Fix{
+		Description: "Format file",
+		Edits:       d.edits,
+	}
+}
+
+// String renders the diff in unified diff format
+func (d *Diff) String() string {
+	if d.Empty() {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", d.File, d.File)
+	for _, h := range d.Hunks {
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", h.OldStart, h.OldLines, h.NewStart, h.NewLines)
+		for _, line := range h.Lines {
+			prefix := " "
+			switch line.Kind {
+			case LineDelete:
+				prefix = "-"
+			case LineInsert:
+				prefix = "+"
+			}
+			b.WriteString(prefix + line.Text)
+			if !strings.HasSuffix(line.Text, "\n") {
+				b.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// splitLines splits content into lines, keeping the trailing newline on each line
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// lineOp is a single step of an edit script
+type lineOp struct {
+	kind LineKind
+	text string
+}
+
+// diffLines computes a shortest edit script from a to b with the linear-space
+// variant of Myers' algorithm: the middle snake of the edit graph splits the
+// problem in two halves that are diffed recursively, so memory stays O(n+m)
+// however many lines change. Within each run of changes, deletions come first.
+func diffLines(a, b []string) []lineOp {
+	size := 2*(len(a)+len(b)) + 3
+	d := &differ{
+		a:      a,
+		b:      b,
+		ops:    make([]lineOp, 0, len(a)+len(b)),
+		vf:     make([]int, size),
+		vb:     make([]int, size),
+		offset: len(a) + len(b) + 1,
+	}
+	d.compare(0, len(a), 0, len(b))
+	return orderChanges(d.ops)
+}
+
+// differ holds the state of a linear-space diff. vf and vb are the furthest
+// reaching x of the forward and backward searches, indexed by diagonal.
+type differ struct {
+	a, b   []string
+	ops    []lineOp
+	vf, vb []int
+	offset int
+}
+
+// compare appends the edit script from a[aLo:aHi] to b[bLo:bHi]
+func (d *differ) compare(aLo, aHi, bLo, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		d.ops = append(d.ops, lineOp{kind: LineContext, text: d.a[aLo]})
+		aLo++
+		bLo++
+	}
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && d.a[aHi-suffix-1] == d.b[bHi-suffix-1] {
+		suffix++
+	}
+	aHi, bHi = aHi-suffix, bHi-suffix
+
+	switch {
+	case aLo == aHi:
+		for _, line := range d.b[bLo:bHi] {
+			d.ops = append(d.ops, lineOp{kind: LineInsert, text: line})
+		}
+	case bLo == bHi:
+		for _, line := range d.a[aLo:aHi] {
+			d.ops = append(d.ops, lineOp{kind: LineDelete, text: line})
+		}
+	default:
+		x, y, u, v := d.middleSnake(aLo, aHi, bLo, bHi)
+		d.compare(aLo, x, bLo, y)
+		for ; x < u; x++ {
+			d.ops = append(d.ops, lineOp{kind: LineContext, text: d.a[x]})
+		}
+		d.compare(u, aHi, v, bHi)
+	}
+
+	for _, line := range d.a[aHi : aHi+suffix] {
+		d.ops = append(d.ops, lineOp{kind: LineContext, text: line})
+	}
+}
+
+// middleSnake finds the snake in the middle of a shortest edit path from
+// a[aLo:aHi] to b[bLo:bHi] by searching forwards from the start and backwards
+// from the end until the searches overlap. It returns the snake's start (x, y)
+// and end (u, v). Both ranges must be non-empty.
+func (d *differ) middleSnake(aLo, aHi, bLo, bHi int) (x, y, u, v int) {
+	n, m := aHi-aLo, bHi-bLo
+	delta := n - m
+	odd := delta%2 != 0
+	vf, vb, off := d.vf, d.vb, d.offset
+	vf[off+1], vb[off+1] = 0, 0
+
+	for step := 0; step <= (n+m+1)/2; step++ {
+		for k := -step; k <= step; k += 2 {
+			var fx int
+			if k == -step || (k != step && vf[off+k-1] < vf[off+k+1]) {
+				fx = vf[off+k+1]
+			} else {
+				fx = vf[off+k-1] + 1
+			}
+			fy := fx - k
+			sx, sy := fx, fy
+			for fx < n && fy < m && d.a[aLo+fx] == d.b[bLo+fy] {
+				fx++
+				fy++
+			}
+			vf[off+k] = fx
+			if kr := delta - k; odd && kr >= -(step-1) && kr <= step-1 && fx+vb[off+kr] >= n {
+				return aLo + sx, bLo + sy, aLo + fx, bLo + fy
+			}
+		}
+
+		for k := -step; k <= step; k += 2 {
+			var rx int
+			if k == -step || (k != step && vb[off+k-1] < vb[off+k+1]) {
+				rx = vb[off+k+1]
+			} else {
+				rx = vb[off+k-1] + 1
+			}
+			ry := rx - k
+			sx, sy := rx, ry
+			for rx < n && ry < m && d.a[aHi-rx-1] == d.b[bHi-ry-1] {
+				rx++
+				ry++
+			}
+			vb[off+k] = rx
+			if kf := delta - k; !odd && kf >= -step && kf <= step && rx+vf[off+kf] >= n {
+				return aHi - rx, bHi - ry, aHi - sx, bHi - sy
+			}
+		}
+	}
+
+	// Not reached, the searches meet within (n+m+1)/2 steps. An empty snake
+	// after all of b and before all of a makes the caller replace every line.
+	return aLo, bHi, aLo, bHi
+}
+
+// orderChanges moves the deletions of each run of changes before its insertions
+func orderChanges(ops []lineOp) []lineOp {
+	for i := 0; i < len(ops); {
+		if ops[i].kind == LineContext {
+			i++
+			continue
+		}
+		end := i
+		for end < len(ops) && ops[end].kind != LineContext {
+			end++
+		}
+		sort.SliceStable(ops[i:end], func(p, q int) bool {
+			return ops[i+p].kind == LineDelete && ops[i+q].kind == LineInsert
+		})
+		i = end
+	}
+	return ops
+}
+
+// buildHunks groups an edit script into hunks with the given number of context lines
+func buildHunks(ops []lineOp, context int) []Hunk {
+	// oldPos/newPos hold the 1-based line numbers at which each op applies
+	oldPos := make([]int, len(ops)+1)
+	newPos := make([]int, len(ops)+1)
+	oldLine, newLine := 1, 1
+	for i, op := range ops {
+		oldPos[i], newPos[i] = oldLine, newLine
+		if op.kind != LineInsert {
+			oldLine++
+		}
+		if op.kind != LineDelete {
+			newLine++
+		}
+	}
+	oldPos[len(ops)], newPos[len(ops)] = oldLine, newLine
+
+	var hunks []Hunk
+	i := 0
+	for i < len(ops) {
+		if ops[i].kind == LineContext {
+			i++
+			continue
+		}
+
+		start := max(0, i-context)
+		end := i
+		for {
+			for end < len(ops) && ops[end].kind != LineContext {
+				end++
+			}
+			run := 0
+			for end+run < len(ops) && ops[end+run].kind == LineContext {
+				run++
+			}
+			if end+run < len(ops) && run <= 2*context {
+				end += run
+				continue
+			}
+			end += min(run, context)
+			break
+		}
+
+		hunk := Hunk{OldStart: oldPos[start], NewStart: newPos[start]}
+		for _, op := range ops[start:end] {
+			hunk.Lines = append(hunk.Lines, DiffLine{Kind: op.kind, Text: op.text})
+			if op.kind != LineInsert {
+				hunk.OldLines++
+			}
+			if op.kind != LineDelete {
+				hunk.NewLines++
+			}
+		}
+		if hunk.OldLines == 0 {
+			hunk.OldStart--
+		}
+		if hunk.NewLines == 0 {
+			hunk.NewStart--
+		}
+
+		hunks = append(hunks, hunk)
+		i = end
+	}
+
+	return hunks
+}
+
+// buildEdits converts each run of changed lines into a text edit on the original content
+func buildEdits(file string, ops []lineOp) []sdk.TextEdit {
+	var edits []sdk.TextEdit
+	line, offset := 1, 0
+
+	for i := 0; i < len(ops); {
+		if ops[i].kind == LineContext {
+			line++
+			offset += len(ops[i].text)
+			i++
+			continue
+		}
+
+		start := hcl.Pos{Line: line, Column: 1, Byte: offset}
+		var newText strings.Builder
+		for ; i < len(ops) && ops[i].kind != LineContext; i++ {
+			switch ops[i].kind {
+			case LineDelete:
+				line++
+				offset += len(ops[i].text)
+			case LineInsert:
+				newText.WriteString(ops[i].text)
+			}
+		}
+
+		edits = append(edits, sdk.TextEdit{
+			Range: hcl.Range{
+				Filename: file,
+				Start:    start,
+				End:      hcl.Pos{Line: line, Column: 1, Byte: offset},
+			},
+			NewText: newText.String(),
+		})
+	}
+
+	return edits
+}
@@ -0,0 +1,167 @@
+package format
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestComputeDiff(t *testing.T) {
+	before := "a\nb\nc\n"
+	after := "a\nB\nc\n"
+
+	d := ComputeDiff("main.tf", []byte(before), []byte(after))
+	if d.Empty() {
+		t.Fatal("expected a non-empty diff")
+	}
+
+	want := `--- main.tf
++++ main.tf
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+`
+	if got := d.String(); got != want {
+		t.Errorf("String() mismatch:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestComputeDiff_NoChanges(t *testing.T) {
+	d := ComputeDiff("main.tf", []byte("a\n"), []byte("a\n"))
+	if !d.Empty() {
+		t.Errorf("expected empty diff, got %d hunks", len(d.Hunks))
+	}
+	if d.String() != "" {
+		t.Errorf("expected empty string, got %q", d.String())
+	}
+	if d.Fix() != nil {
+		t.Error("expected nil fix for empty diff")
+	}
+}
+
+func TestComputeDiff_SeparateHunks(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20; i++ {
+		line := strings.Repeat("x", i+1) + "\n"
+		before = append(before, line)
+		if i == 1 || i == 18 {
+			line = "changed\n"
+		}
+		after = append(after, line)
+	}
+
+	d := ComputeDiff("main.tf", []byte(strings.Join(before, "")), []byte(strings.Join(after, "")))
+	if len(d.Hunks) != 2 {
+		t.Fatalf("expected 2 hunks, got %d", len(d.Hunks))
+	}
+	if d.Hunks[0].OldStart != 1 || d.Hunks[0].OldLines != 5 {
+		t.Errorf("first hunk = -%d,%d, want -1,5", d.Hunks[0].OldStart, d.Hunks[0].OldLines)
+	}
+	if d.Hunks[1].OldStart != 16 || d.Hunks[1].OldLines != 5 {
+		t.Errorf("second hunk = -%d,%d, want -16,5", d.Hunks[1].OldStart, d.Hunks[1].OldLines)
+	}
+}
+
+func TestComputeDiff_NoNewlineAtEOF(t *testing.T) {
+	d := ComputeDiff("main.tf", []byte("a\nb"), []byte("a\nb\n"))
+	if !strings.Contains(d.String(), "-b\n\\ No newline at end of file\n+b\n") {
+		t.Errorf("missing no-newline marker:\n%s", d.String())
+	}
+}
+
+func TestDiff_Fix(t *testing.T) {
+	before := "a\nb\nc\nd\n"
+	after := "a\nB\nc\nd\ne\n"
+
+	fix := ComputeDiff("main.tf", []byte(before), []byte(after)).Fix()
+	if fix == nil {
+		t.Fatal("expected a fix")
+	}
+	if len(fix.Edits) != 2 {
+		t.Fatalf("expected 2 edits, got %d", len(fix.Edits))
+	}
+
+	// Applying the edits back to front must reproduce the formatted content
+	result := before
+	for i := len(fix.Edits) - 1; i >= 0; i-- {
+		edit := fix.Edits[i]
+		result = result[:edit.Range.Start.Byte] + edit.NewText + result[edit.Range.End.Byte:]
+	}
+	if result != after {
+		t.Errorf("applying edits mismatch:\ngot:\n%s\nwant:\n%s", result, after)
+	}
+
+	if fix.Edits[0].Range.Start.Line != 2 || fix.Edits[0].Range.End.Line != 3 {
+		t.Errorf("first edit lines = %d-%d, want 2-3",
+			fix.Edits[0].Range.Start.Line, fix.Edits[0].Range.End.Line)
+	}
+}
+
+func TestDiffLines_ShortestScript(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		changes int
+	}{
+		{"abcabba", "cbabac", 5},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"abc", "abc", 0},
+		{"axbxc", "aybyc", 4},
+		{"abcdef", "fedcba", 10},
+	}
+	for _, tt := range tests {
+		a, b := strings.Split(tt.a, ""), strings.Split(tt.b, "")
+		ops := diffLines(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, op := range ops {
+			if op.kind != LineInsert {
+				gotA = append(gotA, op.text)
+			}
+			if op.kind != LineDelete {
+				gotB = append(gotB, op.text)
+			}
+			if op.kind != LineContext {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != tt.a || strings.Join(gotB, "") != tt.b {
+			t.Errorf("diffLines(%q, %q) does not reproduce its inputs: %q, %q",
+				tt.a, tt.b, strings.Join(gotA, ""), strings.Join(gotB, ""))
+		}
+		if changes != tt.changes {
+			t.Errorf("diffLines(%q, %q) made %d changes, want %d", tt.a, tt.b, changes, tt.changes)
+		}
+	}
+}
+
+func TestComputeDiff_Large(t *testing.T) {
+	// 12000 lines with every third line changed: the edit distance is 8000
+	var before, after strings.Builder
+	for i := 0; i < 12000; i++ {
+		line := fmt.Sprintf("  attribute_%d = %d\n", i, i)
+		before.WriteString(line)
+		if i%3 == 0 {
+			line = fmt.Sprintf("  attribute_%d  = %d\n", i, i)
+		}
+		after.WriteString(line)
+	}
+
+	var start, end runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&start)
+	fix := ComputeDiff("main.tf", []byte(before.String()), []byte(after.String())).Fix()
+	runtime.ReadMemStats(&end)
+
+	if fix == nil || len(fix.Edits) != 4000 {
+		t.Fatalf("expected 4000 edits, got %v", fix)
+	}
+	// The diff allocates a few copies of the lines, not one per edit step
+	if allocated := end.TotalAlloc - start.TotalAlloc; allocated > 64<<20 {
+		t.Errorf("ComputeDiff allocated %d MB", allocated>>20)
+	}
+}
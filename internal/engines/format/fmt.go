@@ -1,10 +1,13 @@
 // Package format provides the formatting engine for TerraTidy.
 // It uses HCL's hclwrite package to format Terraform configuration files
-// according to the canonical HCL style.
+// according to the canonical HCL style, and canonicalises JSON configuration
+// files (.tf.json, .tfvars.json) with stable key order and indentation.
 package format
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -17,12 +20,17 @@ import (
 // Engine represents the formatter engine
 type Engine struct {
 	config *Config
+	format func(path string, content []byte) ([]byte, error)
 }
 
 // Config holds the formatter configuration
 type Config struct {
-	Check bool // Check mode (don't modify files)
-	Diff  bool // Show diff of changes
+	Check   bool                   // Check mode (don't modify files)
+	Diff    bool                   // Show diff of changes
+	Verify  bool                   // Reformat the output and report non-idempotent results
+	Fixes   bool                   // Attach text edits to needs-formatting findings in check mode
+	Ranges  map[string][]LineRange // Only format the changes touching these lines of the given files
+	Options *Options               // Formatting policies (nil uses the hclwrite defaults)
 }
 
 // New creates a new formatter engine
@@ -30,7 +38,11 @@ func New(config *Config) *Engine {
 	if config == nil {
 		config = &Config{}
 	}
-	return &Engine{config: config}
+	opts := DefaultOptions()
+	if config.Options != nil {
+		opts = *config.Options
+	}
+	return &Engine{config: config, format: formatterFor(opts)}
 }
 
 // Name returns the engine name
@@ -49,8 +61,8 @@ func (e *Engine) Run(ctx context.Context, files []string) ([]sdk.Finding, error)
 		default:
 		}
 
-		// Skip non-HCL files
-		if !isHCLFile(file) {
+		// Skip files that cannot be formatted
+		if !isHCLFile(file) && !isJSONFile(file) {
 			continue
 		}
 
@@ -75,22 +87,62 @@ func (e *Engine) formatFile(path string) (*sdk.Finding, error) {
 		return nil, fmt.Errorf("reading file: %w", err)
 	}
 
-	// Format using hclwrite
-	formatted := hclwrite.Format(content)
+	formatted, edited, err := e.formatContent(path, content, e.config.Ranges[path])
+	if err != nil {
+		return &sdk.Finding{
+			Rule:     "fmt.parse-error",
+			Message:  fmt.Sprintf("Failed to parse file: %v", err),
+			File:     path,
+			Severity: sdk.SeverityError,
+			Fixable:  false,
+		}, nil
+	}
 
 	// Check if formatting changed anything
-	if string(formatted) == string(content) {
+	if bytes.Equal(formatted, content) {
 		return nil, nil // Already formatted
 	}
 
-	// In check mode, return a finding
+	// Never write output that no longer parses
+	if err := Validate(path, formatted); err != nil {
+		return &sdk.Finding{
+			Rule:     "fmt.invalid-output",
+			Message:  fmt.Sprintf("Formatter produced invalid output, file left unchanged: %v", err),
+			File:     path,
+			Severity: sdk.SeverityError,
+			Fixable:  false,
+		}, nil
+	}
+
+	// Formatting the output again must not change it. With line ranges, only
+	// the lines just edited are formatted again.
+	if e.config.Verify {
+		again, _, err := e.formatContent(path, formatted, edited)
+		if err != nil || !bytes.Equal(again, formatted) {
+			return &sdk.Finding{
+				Rule:     "fmt.non-idempotent",
+				Message:  "Formatting is not idempotent for this file, file left unchanged",
+				File:     path,
+				Severity: sdk.SeverityError,
+				Fixable:  false,
+			}, nil
+		}
+	}
+
+	// In check mode, return a finding. Its text edits are only computed on
+	// request, the diff of a large file being costly.
 	if e.config.Check {
+		var fix *sdk.Fix
+		if e.config.Fixes {
+			fix = ComputeDiff(path, content, formatted).Fix()
+		}
 		return &sdk.Finding{
 			Rule:     "fmt.needs-formatting",
 			Message:  "File needs formatting",
 			File:     path,
 			Severity: sdk.SeverityError,
 			Fixable:  true,
+			Fix:      fix,
 			FixFunc: func() ([]byte, error) {
 				return formatted, nil
 			},
@@ -111,13 +163,88 @@ func (e *Engine) formatFile(path string) (*sdk.Finding, error) {
 	}, nil
 }
 
-// isHCLFile checks if a file is an HCL file (.tf or .hcl)
+// FormatContent formats content as Run formats the file at path: restricted to
+// the configured line ranges of path and, with Verify, checked to be idempotent.
+// An error is returned if content cannot be parsed or the result is invalid.
+func (e *Engine) FormatContent(path string, content []byte) ([]byte, error) {
+	formatted, edited, err := e.formatContent(path, content, e.config.Ranges[path])
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(formatted, content) {
+		return formatted, nil
+	}
+	if err := Validate(path, formatted); err != nil {
+		return nil, fmt.Errorf("formatting produced invalid output: %w", err)
+	}
+	if e.config.Verify {
+		again, _, err := e.formatContent(path, formatted, edited)
+		if err != nil || !bytes.Equal(again, formatted) {
+			return nil, fmt.Errorf("formatting is not idempotent")
+		}
+	}
+	return formatted, nil
+}
+
+// formatContent formats content, restricted to the given line ranges unless
+// ranges is nil. It also returns the ranges of the result that were edited,
+// nil when the whole content was formatted.
+func (e *Engine) formatContent(path string, content []byte, ranges []LineRange) ([]byte, []LineRange, error) {
+	formatted, err := e.format(path, content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if ranges != nil {
+		formatted, edited := restrictToRanges(path, content, formatted, ranges)
+		return formatted, edited, nil
+	}
+	return formatted, nil, nil
+}
+
+// isHCLFile checks if a file is an HCL file (.tf, .tfvars or .hcl)
 func isHCLFile(path string) bool {
 	ext := strings.ToLower(filepath.Ext(path))
-	return ext == ".tf" || ext == ".hcl"
+	return ext == ".tf" || ext == ".hcl" || ext == ".tfvars"
+}
+
+// isJSONFile checks if a file is a JSON configuration file (.tf.json or .tfvars.json)
+func isJSONFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return strings.HasSuffix(name, ".tf.json") || strings.HasSuffix(name, ".tfvars.json")
 }
 
 // Format formats the given content and returns the formatted result
 func Format(content []byte) []byte {
 	return hclwrite.Format(content)
 }
+
+// FormatJSON canonicalises JSON content: object keys are sorted, indentation is
+// two spaces and the document ends with a newline. Numbers are preserved as written.
+func FormatJSON(content []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("parsing JSON: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("parsing JSON: unexpected content after top-level value")
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return nil, fmt.Errorf("encoding JSON: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// FormatFile formats content according to the file type implied by path.
+// JSON configuration files are canonicalised; everything else is formatted as HCL.
+// An error is returned if the content cannot be parsed.
+func FormatFile(path string, content []byte) ([]byte, error) {
+	return FormatFileWithOptions(path, content, DefaultOptions())
+}
@@ -160,6 +160,7 @@ func TestIsHCLFile(t *testing.T) {
 	}{
 		{"terraform file", "main.tf", true},
 		{"terragrunt file", "terragrunt.hcl", true},
+		{"tfvars file", "prod.tfvars", true},
 		{"uppercase tf", "main.TF", true},
 		{"go file", "main.go", false},
 		{"json file", "config.json", false},
@@ -174,3 +175,97 @@ func TestIsHCLFile(t *testing.T) {
 		})
 	}
 }
+
+func TestIsJSONFile(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{"terraform json", "main.tf.json", true},
+		{"tfvars json", "prod.tfvars.json", true},
+		{"plain json", "package.json", false},
+		{"terraform file", "main.tf", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isJSONFile(tt.path); got != tt.want {
+				t.Errorf("isJSONFile(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatJSON(t *testing.T) {
+	input := `{"variable":{"region":{"default":"us-east-1"}},"locals":{"ratio":1.50,"url":"a<b>"}}`
+	want := `{
+  "locals": {
+    "ratio": 1.50,
+    "url": "a<b>"
+  },
+  "variable": {
+    "region": {
+      "default": "us-east-1"
+    }
+  }
+}
+`
+	got, err := FormatJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("FormatJSON() error = %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("FormatJSON() mismatch:\ngot:\n%s\nwant:\n%s", string(got), want)
+	}
+
+	if _, err := FormatJSON([]byte(`{"a": 1} {"b": 2}`)); err == nil {
+		t.Error("expected error for trailing content")
+	}
+}
+
+func TestEngine_Run_FileTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"prod.tfvars":  "region=\"us-east-1\"\ninstance_count =   2\n",
+		"main.tf.json": `{"b":1,"a":2}`,
+		"bad.tf.json":  `{"a":`,
+		"package.json": `{"b":1,"a":2}`,
+	}
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("failed to create temp file: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	engine := New(&Config{Check: true, Fixes: true})
+	findings, err := engine.Run(context.Background(), paths)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	rules := make(map[string]string)
+	for _, f := range findings {
+		rules[filepath.Base(f.File)] = f.Rule
+		if f.Rule == "fmt.needs-formatting" && f.Fix == nil {
+			t.Errorf("%s: expected structured fix", f.File)
+		}
+	}
+
+	want := map[string]string{
+		"prod.tfvars":  "fmt.needs-formatting",
+		"main.tf.json": "fmt.needs-formatting",
+		"bad.tf.json":  "fmt.parse-error",
+	}
+	if len(rules) != len(want) {
+		t.Errorf("expected %d findings, got %v", len(want), rules)
+	}
+	for file, rule := range want {
+		if rules[file] != rule {
+			t.Errorf("%s: rule = %q, want %q", file, rules[file], rule)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+package format
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Map key normalisation modes
+const (
+	MapKeysPreserve = ""       // leave map keys as written
+	MapKeysBare     = "bare"   // unquote keys that are valid identifiers and not keywords
+	MapKeysQuoted   = "quoted" // quote bare identifier keys
+)
+
+// NoBlankLineLimit leaves runs of blank lines as written
+const NoBlankLineLimit = -1
+
+// Options holds the formatting policies layered on top of the canonical HCL
+// style. They are read from engines.fmt.config and only apply to HCL files.
+type Options struct {
+	// AlignEquals aligns "=" across consecutive attributes (the hclwrite default)
+	AlignEquals bool
+	// MaxBlankLines limits consecutive blank lines: 0 removes them all and
+	// NoBlankLineLimit keeps them as written
+	MaxBlankLines int
+	// BlankLineBeforeNestedBlocks requires a blank line before nested blocks that do not open their parent's body
+	BlankLineBeforeNestedBlocks bool
+	// MapKeys normalises object keys: "", "bare" or "quoted"
+	MapKeys string
+}
+
+// DefaultOptions returns the options that reproduce plain hclwrite formatting
+func DefaultOptions() Options {
+	return Options{AlignEquals: true, MaxBlankLines: NoBlankLineLimit}
+}
+
+// isDefault reports whether the options leave hclwrite output unchanged
+func (o Options) isDefault() bool {
+	return o == DefaultOptions()
+}
+
+// ParseOptions reads formatting options from an engine config map
+func ParseOptions(cfg map[string]interface{}) (Options, error) {
+	opts := DefaultOptions()
+
+	for key, value := range cfg {
+		switch key {
+		case "align_equals":
+			b, ok := value.(bool)
+			if !ok {
+				return opts, fmt.Errorf("align_equals: expected boolean, got %T", value)
+			}
+			opts.AlignEquals = b
+		case "max_blank_lines":
+			n, ok := intValue(value)
+			if !ok {
+				return opts, fmt.Errorf("max_blank_lines: expected integer, got %v", value)
+			}
+			// Any negative value means no limit
+			opts.MaxBlankLines = max(n, NoBlankLineLimit)
+		case "blank_line_before_nested_blocks":
+			b, ok := value.(bool)
+			if !ok {
+				return opts, fmt.Errorf("blank_line_before_nested_blocks: expected boolean, got %T", value)
+			}
+			opts.BlankLineBeforeNestedBlocks = b
+		case "map_keys":
+			s, ok := value.(string)
+			if !ok {
+				return opts, fmt.Errorf("map_keys: expected string, got %T", value)
+			}
+			switch s {
+			case MapKeysPreserve, "preserve":
+				opts.MapKeys = MapKeysPreserve
+			case MapKeysBare, MapKeysQuoted:
+				opts.MapKeys = s
+			default:
+				return opts, fmt.Errorf("map_keys: unknown mode %q (expected preserve, bare or quoted)", s)
+			}
+		default:
+			return opts, fmt.Errorf("unknown fmt option %q", key)
+		}
+	}
+
+	return opts, nil
+}
+
+// intValue reads an integer config value. YAML decodes numbers as int, JSON as
+// float64, and environment variable expansion can leave them as strings.
+func intValue(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), v == math.Trunc(v)
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// FormatWithOptions formats HCL content with the given policies applied.
+// Content that does not parse is formatted without policies.
+func FormatWithOptions(content []byte, opts Options) []byte {
+	if opts.isDefault() {
+		return Format(content)
+	}
+
+	// Structural policies run before formatting so hclwrite realigns the result
+	if opts.MapKeys != MapKeysPreserve {
+		content = normalizeMapKeys(content, opts.MapKeys)
+	}
+	if opts.BlankLineBeforeNestedBlocks {
+		content = insertBlankLinesBeforeNestedBlocks(content)
+	}
+
+	formatted := Format(content)
+
+	if opts.MaxBlankLines >= 0 {
+		formatted = limitBlankLines(formatted, opts.MaxBlankLines)
+	}
+	if !opts.AlignEquals {
+		formatted = unalignEquals(formatted)
+	}
+	return formatted
+}
+
+// parseBody parses HCL content into a syntax body, or returns nil if it does not parse
+func parseBody(content []byte) *hclsyntax.Body {
+	file, diags := hclsyntax.ParseConfig(content, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil
+	}
+	body, _ := file.Body.(*hclsyntax.Body)
+	return body
+}
+
+// byteEdit returns a text edit replacing the given source range
+func byteEdit(rng hcl.Range, text string) sdk.TextEdit {
+	return sdk.TextEdit{Range: rng, NewText: text}
+}
+
+// hclKeywords are identifiers that are literal values as bare keys
+var hclKeywords = map[string]bool{"null": true, "true": true, "false": true}
+
+// normalizeMapKeys quotes or unquotes object constructor keys
+func normalizeMapKeys(content []byte, mode string) []byte {
+	body := parseBody(content)
+	if body == nil {
+		return content
+	}
+
+	var edits []sdk.TextEdit
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		key, ok := node.(*hclsyntax.ObjectConsKeyExpr)
+		if !ok || key.ForceNonLiteral {
+			return nil
+		}
+
+		switch wrapped := key.Wrapped.(type) {
+		case *hclsyntax.ScopeTraversalExpr:
+			if mode == MapKeysQuoted && len(wrapped.Traversal) == 1 {
+				name := wrapped.Traversal.RootName()
+				edits = append(edits, byteEdit(wrapped.SrcRange, `"`+name+`"`))
+			}
+		case *hclsyntax.TemplateExpr:
+			if mode != MapKeysBare || len(wrapped.Parts) != 1 {
+				return nil
+			}
+			lit, ok := wrapped.Parts[0].(*hclsyntax.LiteralValueExpr)
+			if !ok || !lit.Val.Type().Equals(cty.String) {
+				return nil
+			}
+			name := lit.Val.AsString()
+			src := wrapped.SrcRange.SliceBytes(content)
+			if hclsyntax.ValidIdentifier(name) && !hclKeywords[name] && string(src) == `"`+name+`"` {
+				edits = append(edits, byteEdit(wrapped.SrcRange, name))
+			}
+		}
+		return nil
+	})
+
+	return applyEdits(content, edits)
+}
+
+// insertBlankLinesBeforeNestedBlocks separates nested blocks from the preceding
+// attribute or block. Leading comments stay attached to their block.
+func insertBlankLinesBeforeNestedBlocks(content []byte) []byte {
+	body := parseBody(content)
+	if body == nil {
+		return content
+	}
+
+	lines := splitLines(string(content))
+	offsets := make([]int, len(lines)+1)
+	for i, line := range lines {
+		offsets[i+1] = offsets[i] + len(line)
+	}
+
+	var edits []sdk.TextEdit
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		parent, ok := node.(*hclsyntax.Block)
+		if !ok {
+			return nil
+		}
+		for _, block := range parent.Body.Blocks {
+			// Walk up over comment lines attached to the block (0-based index)
+			idx := block.TypeRange.Start.Line - 1
+			for idx > 0 && isCommentLine(lines[idx-1]) {
+				idx--
+			}
+			if idx <= parent.OpenBraceRange.Start.Line || strings.TrimSpace(lines[idx-1]) == "" {
+				continue
+			}
+			pos := hcl.Pos{Line: idx + 1, Column: 1, Byte: offsets[idx]}
+			edits = append(edits, byteEdit(hcl.Range{Start: pos, End: pos}, "\n"))
+		}
+		return nil
+	})
+
+	return applyEdits(content, edits)
+}
+
+// isCommentLine reports whether a line holds only a comment
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//")
+}
+
+// limitBlankLines collapses runs of blank lines longer than max, leaving heredoc content untouched
+func limitBlankLines(content []byte, max int) []byte {
+	heredoc := heredocLines(content)
+	lines := splitLines(string(content))
+
+	var b strings.Builder
+	blank := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" && !heredoc[i+1] {
+			blank++
+			if blank > max {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		b.WriteString(line)
+	}
+	return []byte(b.String())
+}
+
+// heredocLines returns the 1-based line numbers that are heredoc content
+func heredocLines(content []byte) map[int]bool {
+	lines := make(map[int]bool)
+	tokens, _ := hclsyntax.LexConfig(content, "", hcl.InitialPos)
+
+	for i, tok := range tokens {
+		if tok.Type != hclsyntax.TokenOHeredoc {
+			continue
+		}
+		for j := i + 1; j < len(tokens); j++ {
+			if tokens[j].Type == hclsyntax.TokenCHeredoc {
+				for l := tok.Range.End.Line; l < tokens[j].Range.Start.Line; l++ {
+					lines[l] = true
+				}
+				break
+			}
+		}
+	}
+	return lines
+}
+
+// unalignEquals replaces the alignment padding before "=" with a single space
+func unalignEquals(content []byte) []byte {
+	tokens, diags := hclsyntax.LexConfig(content, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return content
+	}
+
+	var edits []sdk.TextEdit
+	for i := 1; i < len(tokens); i++ {
+		tok, prev := tokens[i], tokens[i-1]
+		if tok.Type != hclsyntax.TokenEqual || prev.Range.End.Line != tok.Range.Start.Line {
+			continue
+		}
+		if tok.Range.Start.Byte-prev.Range.End.Byte > 1 {
+			edits = append(edits, byteEdit(hcl.Range{Start: prev.Range.End, End: tok.Range.Start}, " "))
+		}
+	}
+
+	return applyEdits(content, edits)
+}
+
+// FormatFileWithOptions is FormatFile with formatting policies applied to HCL files
+func FormatFileWithOptions(path string, content []byte, opts Options) ([]byte, error) {
+	if isJSONFile(path) {
+		return FormatJSON(content)
+	}
+	if err := Validate(path, content); err != nil {
+		return nil, err
+	}
+	return FormatWithOptions(content, opts), nil
+}
+
+// formatterFor returns the format function for the engine's options
+func formatterFor(opts Options) func(path string, content []byte) ([]byte, error) {
+	return func(path string, content []byte) ([]byte, error) {
+		return FormatFileWithOptions(path, content, opts)
+	}
+}
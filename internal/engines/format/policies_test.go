@@ -0,0 +1,257 @@
+package format
+
+import (
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     map[string]interface{}
+		want    Options
+		wantErr bool
+	}{
+		{"nil config", nil, DefaultOptions(), false},
+		{
+			name: "all options",
+			cfg: map[string]interface{}{
+				"align_equals":                    false,
+				"max_blank_lines":                 1,
+				"blank_line_before_nested_blocks": true,
+				"map_keys":                        "quoted",
+			},
+			want: Options{MaxBlankLines: 1, BlankLineBeforeNestedBlocks: true, MapKeys: MapKeysQuoted},
+		},
+		{"preserve map keys", map[string]interface{}{"map_keys": "preserve"}, DefaultOptions(), false},
+		{"wrong type", map[string]interface{}{"align_equals": "no"}, Options{}, true},
+		{"no blank line limit", map[string]interface{}{"max_blank_lines": -1}, DefaultOptions(), false},
+		{"any negative blank lines", map[string]interface{}{"max_blank_lines": -5}, DefaultOptions(), false},
+		{"no blank lines", map[string]interface{}{"max_blank_lines": 0}, Options{AlignEquals: true}, false},
+		{"blank lines from JSON", map[string]interface{}{"max_blank_lines": float64(2)}, Options{AlignEquals: true, MaxBlankLines: 2}, false},
+		{"blank lines as string", map[string]interface{}{"max_blank_lines": "2"}, Options{AlignEquals: true, MaxBlankLines: 2}, false},
+		{"fractional blank lines", map[string]interface{}{"max_blank_lines": 1.5}, Options{}, true},
+		{"invalid blank lines", map[string]interface{}{"max_blank_lines": "two"}, Options{}, true},
+		{"blank lines wrong type", map[string]interface{}{"max_blank_lines": true}, Options{}, true},
+		{"unknown map_keys mode", map[string]interface{}{"map_keys": "single"}, Options{}, true},
+		{"unknown option", map[string]interface{}{"indent": 4}, Options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseOptions(tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseOptions() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatWithOptions(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  Options
+		input string
+		want  string
+	}{
+		{
+			name: "no alignment",
+			opts: Options{MaxBlankLines: NoBlankLineLimit},
+			input: `resource "aws_instance" "web" {
+  ami           = "ami-1"
+  instance_type = "t3.micro"
+  tags = {
+    Name        = "web"
+    Environment = "prod"
+  }
+}
+`,
+			want: `resource "aws_instance" "web" {
+  ami = "ami-1"
+  instance_type = "t3.micro"
+  tags = {
+    Name = "web"
+    Environment = "prod"
+  }
+}
+`,
+		},
+		{
+			name: "max blank lines",
+			opts: Options{AlignEquals: true, MaxBlankLines: 1},
+			input: `locals {
+  a = 1
+}
+
+
+
+locals {
+  script = <<EOT
+x
+
+
+
+y
+EOT
+}
+`,
+			want: `locals {
+  a = 1
+}
+
+locals {
+  script = <<EOT
+x
+
+
+
+y
+EOT
+}
+`,
+		},
+		{
+			name: "no blank lines",
+			opts: Options{AlignEquals: true},
+			input: `locals {
+  a = 1
+
+  b = 2
+}
+
+
+locals {
+  script = <<EOT
+x
+
+y
+EOT
+}
+`,
+			want: `locals {
+  a = 1
+  b = 2
+}
+locals {
+  script = <<EOT
+x
+
+y
+EOT
+}
+`,
+		},
+		{
+			name: "blank line before nested blocks",
+			opts: Options{AlignEquals: true, MaxBlankLines: NoBlankLineLimit, BlankLineBeforeNestedBlocks: true},
+			input: `resource "aws_security_group" "sg" {
+  ingress {
+    from_port = 443
+  }
+  name = "sg"
+  # egress rule
+  egress {
+    from_port = 0
+  }
+
+  egress {
+    from_port = 1
+  }
+}
+`,
+			want: `resource "aws_security_group" "sg" {
+  ingress {
+    from_port = 443
+  }
+  name = "sg"
+
+  # egress rule
+  egress {
+    from_port = 0
+  }
+
+  egress {
+    from_port = 1
+  }
+}
+`,
+		},
+		{
+			name: "bare map keys",
+			opts: Options{AlignEquals: true, MaxBlankLines: NoBlankLineLimit, MapKeys: MapKeysBare},
+			input: `locals {
+  tags = {
+    "Name" = "web"
+    "cost-center" = "42"
+    "${var.key}" = "x"
+  }
+}
+`,
+			want: `locals {
+  tags = {
+    Name         = "web"
+    cost-center  = "42"
+    "${var.key}" = "x"
+  }
+}
+`,
+		},
+		{
+			name: "bare map keys keep keywords quoted",
+			opts: Options{AlignEquals: true, MaxBlankLines: NoBlankLineLimit, MapKeys: MapKeysBare},
+			input: `locals {
+  flags = {
+    "null" = 0
+    "true" = 1
+    "false" = 2
+    "nullable" = 3
+  }
+}
+`,
+			want: `locals {
+  flags = {
+    "null"   = 0
+    "true"   = 1
+    "false"  = 2
+    nullable = 3
+  }
+}
+`,
+		},
+		{
+			name: "quoted map keys",
+			opts: Options{AlignEquals: true, MaxBlankLines: NoBlankLineLimit, MapKeys: MapKeysQuoted},
+			input: `locals {
+  tags = {
+    Name = "web"
+    (var.key) = "x"
+  }
+}
+`,
+			want: `locals {
+  tags = {
+    "Name"    = "web"
+    (var.key) = "x"
+  }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FormatWithOptions([]byte(tt.input), tt.opts)
+			if string(got) != tt.want {
+				t.Errorf("FormatWithOptions() mismatch:\ngot:\n%s\nwant:\n%s", string(got), tt.want)
+			}
+
+			// Policies must be idempotent
+			again := FormatWithOptions(got, tt.opts)
+			if string(again) != string(got) {
+				t.Errorf("second pass changed output:\n%s", string(again))
+			}
+		})
+	}
+}
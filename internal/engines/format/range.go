@@ -0,0 +1,131 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// LineRange is an inclusive, 1-based range of lines in the original content
+type LineRange struct {
+	Start int `json:"start" yaml:"start"`
+	End   int `json:"end" yaml:"end"`
+}
+
+// ParseLineRange parses a line range written as START-END or as a single line N
+func ParseLineRange(s string) (LineRange, error) {
+	start, end, found := strings.Cut(s, "-")
+	if !found {
+		end = start
+	}
+	first, err1 := strconv.Atoi(strings.TrimSpace(start))
+	last, err2 := strconv.Atoi(strings.TrimSpace(end))
+	if err1 != nil || err2 != nil || first < 1 || last < first {
+		return LineRange{}, fmt.Errorf("invalid line range %q (expected START-END or N, 1-based)", s)
+	}
+	return LineRange{Start: first, End: last}, nil
+}
+
+// overlaps reports whether a text edit touches the range. An edit replaces the
+// original lines [Start.Line, End.Line); a pure insertion touches Start.Line only.
+func (r LineRange) overlaps(edit sdk.TextEdit) bool {
+	first := edit.Range.Start.Line
+	last := edit.Range.End.Line - 1
+	if last < first {
+		last = first
+	}
+	return first <= r.End && last >= r.Start
+}
+
+// FormatRange formats only the parts of content that overlap the given line
+// ranges. Formatting changes are computed for the whole file and every change
+// that touches a range is applied in full, so the edited region may extend
+// slightly beyond the requested lines. An error is returned if the input
+// cannot be parsed or the partially formatted result is no longer valid.
+func FormatRange(path string, content []byte, ranges ...LineRange) ([]byte, error) {
+	return FormatRangeWithOptions(path, content, DefaultOptions(), ranges...)
+}
+
+// FormatRangeWithOptions is FormatRange with formatting policies applied
+func FormatRangeWithOptions(path string, content []byte, opts Options, ranges ...LineRange) ([]byte, error) {
+	if ranges == nil {
+		ranges = []LineRange{}
+	}
+	engine := New(&Config{Options: &opts, Ranges: map[string][]LineRange{path: ranges}})
+	return engine.FormatContent(path, content)
+}
+
+// restrictToRanges applies only the formatting changes that touch the given
+// ranges. It also returns the lines of the result that these changes produced.
+func restrictToRanges(path string, content, formatted []byte, ranges []LineRange) ([]byte, []LineRange) {
+	fix := ComputeDiff(path, content, formatted).Fix()
+	if fix == nil {
+		return content, []LineRange{}
+	}
+
+	selected := []sdk.TextEdit{}
+	for _, edit := range fix.Edits {
+		for _, r := range ranges {
+			if r.overlaps(edit) {
+				selected = append(selected, edit)
+				break
+			}
+		}
+	}
+	return applyEdits(content, selected), editedLines(selected)
+}
+
+// editedLines returns the lines that edits, sorted by position, occupy once
+// applied. A deletion is represented by the line following it.
+func editedLines(edits []sdk.TextEdit) []LineRange {
+	ranges := make([]LineRange, 0, len(edits))
+	shift := 0
+	for _, edit := range edits {
+		start := edit.Range.Start.Line + shift
+		lines := strings.Count(edit.NewText, "\n")
+		if edit.NewText != "" && !strings.HasSuffix(edit.NewText, "\n") {
+			lines++
+		}
+		ranges = append(ranges, LineRange{Start: start, End: start + max(lines, 1) - 1})
+		shift += lines - (edit.Range.End.Line - edit.Range.Start.Line)
+	}
+	return ranges
+}
+
+// applyEdits applies non-overlapping text edits to content
+func applyEdits(content []byte, edits []sdk.TextEdit) []byte {
+	sorted := append([]sdk.TextEdit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Range.Start.Byte > sorted[j].Range.Start.Byte
+	})
+
+	result := append([]byte(nil), content...)
+	for _, edit := range sorted {
+		tail := append([]byte(edit.NewText), result[edit.Range.End.Byte:]...)
+		result = append(result[:edit.Range.Start.Byte], tail...)
+	}
+	return result
+}
+
+// Validate checks that content parses as HCL, or as JSON configuration for
+// .tf.json and .tfvars.json files
+func Validate(path string, content []byte) error {
+	parser := hclparse.NewParser()
+	if isJSONFile(path) {
+		_, diags := parser.ParseJSON(content, path)
+		if diags.HasErrors() {
+			return diags
+		}
+		return nil
+	}
+
+	_, diags := parser.ParseHCL(content, path)
+	if diags.HasErrors() {
+		return diags
+	}
+	return nil
+}
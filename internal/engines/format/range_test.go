@@ -0,0 +1,226 @@
+package format
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const rangeInput = `resource "aws_instance" "a" {
+ami="ami-1"
+}
+
+resource "aws_instance" "b" {
+ami="ami-2"
+}
+`
+
+func TestFormatRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		ranges []LineRange
+		want   string
+	}{
+		{
+			name:   "first block only",
+			ranges: []LineRange{{Start: 1, End: 3}},
+			want: `resource "aws_instance" "a" {
+  ami = "ami-1"
+}
+
+resource "aws_instance" "b" {
+ami="ami-2"
+}
+`,
+		},
+		{
+			name:   "second block only",
+			ranges: []LineRange{{Start: 6, End: 6}},
+			want: `resource "aws_instance" "a" {
+ami="ami-1"
+}
+
+resource "aws_instance" "b" {
+  ami = "ami-2"
+}
+`,
+		},
+		{
+			name:   "untouched range",
+			ranges: []LineRange{{Start: 4, End: 4}},
+			want:   rangeInput,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatRange("main.tf", []byte(rangeInput), tt.ranges...)
+			if err != nil {
+				t.Fatalf("FormatRange() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FormatRange() mismatch:\ngot:\n%s\nwant:\n%s", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatRange_InvalidInput(t *testing.T) {
+	if _, err := FormatRange("main.tf", []byte("resource \"a\" {\n"), LineRange{Start: 1, End: 1}); err == nil {
+		t.Error("expected error for invalid input")
+	}
+}
+
+func TestEngine_Run_Ranges(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(tmpFile, []byte(rangeInput), 0o644); err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+
+	engine := New(&Config{Ranges: map[string][]LineRange{tmpFile: {{Start: 5, End: 7}}}})
+	if _, err := engine.Run(context.Background(), []string{tmpFile}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	want, _ := FormatRange(tmpFile, []byte(rangeInput), LineRange{Start: 5, End: 7})
+	if string(content) != string(want) {
+		t.Errorf("content mismatch:\ngot:\n%s\nwant:\n%s", string(content), string(want))
+	}
+}
+
+func TestEngine_Run_RangesVerify(t *testing.T) {
+	// Collapsing the blank lines moves the unformatted c=3 into the requested
+	// lines; verification must only look at the lines that were edited
+	const input = "locals {\n  a = 1\n\n\n\nb=2\n}\nlocals {\nc=3\n}\n"
+	const want = "locals {\n  a = 1\n\n  b = 2\n}\nlocals {\nc=3\n}\n"
+
+	tmpFile := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(tmpFile, []byte(input), 0o644); err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+
+	engine := New(&Config{
+		Verify:  true,
+		Options: &Options{AlignEquals: true, MaxBlankLines: 1},
+		Ranges:  map[string][]LineRange{tmpFile: {{Start: 4, End: 8}}},
+	})
+	findings, err := engine.Run(context.Background(), []string{tmpFile})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "fmt.formatted" {
+		t.Fatalf("expected one fmt.formatted finding, got %+v", findings)
+	}
+
+	content, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("failed to read file: %v", err)
+	}
+	if string(content) != want {
+		t.Errorf("content mismatch:\ngot:\n%s\nwant:\n%s", string(content), want)
+	}
+}
+
+func TestEngine_FormatContent_Ranges(t *testing.T) {
+	engine := New(&Config{Ranges: map[string][]LineRange{"main.tf": {{Start: 6, End: 6}}}})
+	got, err := engine.FormatContent("main.tf", []byte(rangeInput))
+	if err != nil {
+		t.Fatalf("FormatContent() error = %v", err)
+	}
+	want, _ := FormatRange("main.tf", []byte(rangeInput), LineRange{Start: 6, End: 6})
+	if string(got) != string(want) {
+		t.Errorf("FormatContent() mismatch:\ngot:\n%s\nwant:\n%s", string(got), string(want))
+	}
+}
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    LineRange
+		wantErr bool
+	}{
+		{"3-7", LineRange{Start: 3, End: 7}, false},
+		{"5", LineRange{Start: 5, End: 5}, false},
+		{"7-3", LineRange{}, true},
+		{"0-2", LineRange{}, true},
+		{"a-b", LineRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLineRange(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseLineRange(%q) = %v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func TestEngine_Run_Verify(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   func(path string, content []byte) ([]byte, error)
+		wantRule string
+	}{
+		{
+			name: "non-idempotent",
+			format: func(_ string, content []byte) ([]byte, error) {
+				return append(content, "# again\n"...), nil
+			},
+			wantRule: "fmt.non-idempotent",
+		},
+		{
+			name: "invalid output",
+			format: func(_ string, content []byte) ([]byte, error) {
+				return append(content, "resource {\n"...), nil
+			},
+			wantRule: "fmt.invalid-output",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile := filepath.Join(t.TempDir(), "main.tf")
+			if err := os.WriteFile(tmpFile, []byte(rangeInput), 0o644); err != nil {
+				t.Fatalf("failed to create temp file: %v", err)
+			}
+
+			engine := New(&Config{Verify: true})
+			engine.format = tt.format
+
+			findings, err := engine.Run(context.Background(), []string{tmpFile})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if len(findings) != 1 || findings[0].Rule != tt.wantRule {
+				t.Fatalf("expected one %s finding, got %+v", tt.wantRule, findings)
+			}
+
+			// The file must be left untouched
+			content, err := os.ReadFile(tmpFile)
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			if string(content) != rangeInput {
+				t.Errorf("file was modified:\n%s", string(content))
+			}
+		})
+	}
+}
+
+func TestEngine_Run_ParseError(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "main.tf")
+	if err := os.WriteFile(tmpFile, []byte("resource \"a\" {\n"), 0o644); err != nil {
+		t.Fatalf("failed to create temp file: %v", err)
+	}
+
+	findings, err := New(nil).Run(context.Background(), []string{tmpFile})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "fmt.parse-error" {
+		t.Errorf("expected fmt.parse-error, got %+v", findings)
+	}
+}
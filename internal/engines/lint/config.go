@@ -0,0 +1,126 @@
+package lint
+
+import "fmt"
+
+// ParseConfig reads the lint engine configuration from engines.lint.config
+func ParseConfig(cfg map[string]interface{}) (*Config, error) {
+	config := &Config{
+		ConfigFile: ".tflint.hcl",
+		Rules:      make(map[string]RuleConfig),
+	}
+
+	for key, value := range cfg {
+		var err error
+		switch key {
+		case "config_file":
+			config.ConfigFile, err = stringValue(key, value)
+		case "tflint_config":
+			config.TFLintConfig, err = stringValue(key, value)
+		case "rulesets":
+			config.Plugins, err = stringList(key, value)
+		case "use_tflint":
+			config.UseTFLint, err = boolValue(key, value)
+		case "tflint_path":
+			config.TFLintPath, err = stringValue(key, value)
+		case "fallback_builtin":
+			config.FallbackBuiltin, err = boolValue(key, value)
+		case "rules":
+			config.Rules, err = ruleConfigs(key, value)
+		default:
+			return nil, fmt.Errorf("unknown lint option %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// stringList reads a list of strings from a config value
+func stringList(key string, value interface{}) ([]string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected list of strings, got %T", key, value)
+	}
+
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected list of strings, got %T element", key, item)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// stringValue reads a string from a config value
+func stringValue(key string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: expected string, got %T", key, value)
+	}
+	return s, nil
+}
+
+// boolValue reads a boolean from a config value
+func boolValue(key string, value interface{}) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: expected boolean, got %T", key, value)
+	}
+	return b, nil
+}
+
+// ruleConfigs reads the rules map. Each rule is either a boolean (enabled) or
+// a map with enabled (default true), severity and options.
+func ruleConfigs(key string, value interface{}) (map[string]RuleConfig, error) {
+	items, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected map of rules, got %T", key, value)
+	}
+
+	rules := make(map[string]RuleConfig, len(items))
+	for id, item := range items {
+		rule := RuleConfig{Enabled: true, Severity: "warning", Options: make(map[string]interface{})}
+		switch v := item.(type) {
+		case bool:
+			rule.Enabled = v
+		case map[string]interface{}:
+			for field, fv := range v {
+				switch field {
+				case "enabled":
+					enabled, err := boolValue(key+"."+id+".enabled", fv)
+					if err != nil {
+						return nil, err
+					}
+					rule.Enabled = enabled
+				case "severity":
+					severity, err := stringValue(key+"."+id+".severity", fv)
+					if err != nil {
+						return nil, err
+					}
+					switch severity {
+					case "error", "warning", "info":
+					default:
+						return nil, fmt.Errorf("%s.%s.severity: invalid severity %q (use info, warning or error)", key, id, severity)
+					}
+					rule.Severity = severity
+				case "options":
+					options, ok := fv.(map[string]interface{})
+					if !ok {
+						return nil, fmt.Errorf("%s.%s.options: expected map, got %T", key, id, fv)
+					}
+					rule.Options = options
+				default:
+					return nil, fmt.Errorf("%s.%s: unknown option %q", key, id, field)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s.%s: expected boolean or map, got %T", key, id, item)
+		}
+		rules[id] = rule
+	}
+	return rules, nil
+}
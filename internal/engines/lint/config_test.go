@@ -0,0 +1,52 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(nil)
+	require.NoError(t, err)
+	assert.Equal(t, ".tflint.hcl", cfg.ConfigFile)
+	assert.Empty(t, cfg.Rules)
+
+	cfg, err = ParseConfig(map[string]interface{}{
+		"config_file":   "lint.hcl",
+		"tflint_config": "ci/.tflint.hcl",
+		"rulesets":      []interface{}{"aws"},
+		"rules": map[string]interface{}{
+			"lint.terraform-required-version": false,
+			"lint.terraform-deprecated-syntax": map[string]interface{}{
+				"severity": "error",
+				"options":  map[string]interface{}{"target_version": "1.5"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "lint.hcl", cfg.ConfigFile)
+	assert.Equal(t, "ci/.tflint.hcl", cfg.TFLintConfig)
+	assert.Equal(t, []string{"aws"}, cfg.Plugins)
+	assert.False(t, cfg.Rules["lint.terraform-required-version"].Enabled)
+	assert.Equal(t, RuleConfig{
+		Enabled:  true,
+		Severity: "error",
+		Options:  map[string]interface{}{"target_version": "1.5"},
+	}, cfg.Rules["lint.terraform-deprecated-syntax"])
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"unknown": true},
+		{"rulesets": "aws"},
+		{"use_tflint": "yes"},
+		{"rules": map[string]interface{}{"x": map[string]interface{}{"severity": "fatal"}}},
+		{"rules": map[string]interface{}{"x": map[string]interface{}{"level": 1}}},
+	}
+	for _, cfg := range tests {
+		_, err := ParseConfig(cfg)
+		assert.Error(t, err, "%v", cfg)
+	}
+}
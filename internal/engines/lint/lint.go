@@ -37,6 +37,7 @@ type Config struct {
 	TFLintPath      string                 // Custom path to TFLint binary
 	TFLintConfig    string                 // Path to TFLint config file
 	FallbackBuiltin bool                   // Use built-in rules if TFLint unavailable
+	Fix             bool                   // Apply fixes for fixable findings
 }
 
 // RuleConfig holds configuration for a single rule
@@ -142,7 +143,15 @@ func (e *Engine) lintModule(ctx context.Context, dir string, files []string) ([]
 			continue
 		}
 
-		hclFile, diags := e.parser.ParseHCL(content, file)
+		var hclFile *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(strings.ToLower(file), ".json") {
+			// JSON configuration files are parsed for cross-file analysis only;
+			// the built-in rules operate on native HCL syntax.
+			hclFile, diags = e.parser.ParseJSON(content, file)
+		} else {
+			hclFile, diags = e.parser.ParseHCL(content, file)
+		}
 		if diags.HasErrors() {
 			findings = append(findings, sdk.Finding{
 				Rule:     "lint.parse-error",
@@ -189,6 +198,7 @@ func (e *Engine) lintModule(ctx context.Context, dir string, files []string) ([]
 		}
 
 		// Run all enabled rules
+		var fileFindings []sdk.Finding
 		for _, rule := range e.rules {
 			ruleConfig := e.getRuleConfig(rule.Name())
 			if !ruleConfig.Enabled {
@@ -197,13 +207,42 @@ func (e *Engine) lintModule(ctx context.Context, dir string, files []string) ([]
 
 			ruleCtx.Config = ruleConfig
 			ruleFindings := rule.Check(ruleCtx)
-			findings = append(findings, ruleFindings...)
+			fileFindings = append(fileFindings, ruleFindings...)
+		}
+
+		if e.config.Fix {
+			if err := e.applyFixes(file, fileFindings); err != nil {
+				return nil, err
+			}
 		}
+		findings = append(findings, fileFindings...)
 	}
 
 	return findings, nil
 }
 
+// applyFixes applies the fixes for fixable findings in a file. Fix functions
+// rewrite every occurrence a rule reports, so each rule is applied once and the
+// result written before the next rule reads the file.
+func (e *Engine) applyFixes(file string, findings []sdk.Finding) error {
+	seenRules := make(map[string]bool)
+	for _, f := range findings {
+		if !f.Fixable || f.FixFunc == nil || seenRules[f.Rule] {
+			continue
+		}
+		seenRules[f.Rule] = true
+
+		fixed, err := f.FixFunc()
+		if err != nil {
+			return fmt.Errorf("fixing %s in %s: %w", f.Rule, file, err)
+		}
+		if err := os.WriteFile(file, fixed, 0o644); err != nil {
+			return fmt.Errorf("writing fixed file %s: %w", file, err)
+		}
+	}
+	return nil
+}
+
 // getRuleConfig returns the configuration for a rule
 func (e *Engine) getRuleConfig(ruleName string) RuleConfig {
 	if cfg, ok := e.config.Rules[ruleName]; ok {
@@ -359,71 +398,6 @@ func (r *TerraformRequiredProvidersRule) Check(ctx *RuleContext) []sdk.Finding {
 	return findings
 }
 
-// TerraformDeprecatedSyntaxRule checks for deprecated interpolation syntax.
-type TerraformDeprecatedSyntaxRule struct{}
-
-// Name returns the rule identifier.
-func (r *TerraformDeprecatedSyntaxRule) Name() string {
-	return "lint.terraform-deprecated-syntax"
-}
-
-// Description returns a human-readable description of the rule.
-func (r *TerraformDeprecatedSyntaxRule) Description() string {
-	return "Detects deprecated interpolation-only expressions like \"${var.x}\""
-}
-
-// deprecatedInterpolationRegex matches "${...}" patterns that should be simplified.
-var deprecatedInterpolationRegex = regexp.MustCompile(`"\$\{([^}]+)\}"`)
-
-// Check examines files for deprecated syntax patterns.
-func (r *TerraformDeprecatedSyntaxRule) Check(ctx *RuleContext) []sdk.Finding {
-	var findings []sdk.Finding
-	lines := strings.Split(string(ctx.Content), "\n")
-
-	for i, line := range lines {
-		matches := deprecatedInterpolationRegex.FindAllStringSubmatchIndex(line, -1)
-		for _, match := range matches {
-			if len(match) >= 4 {
-				inner := line[match[2]:match[3]]
-				// Only flag if it's a simple reference (var.x, local.x, etc.)
-				if isSimpleReference(inner) {
-					msg := fmt.Sprintf(
-						"Deprecated interpolation-only expression: use %s instead of \"${%s}\"",
-						inner, inner,
-					)
-					findings = append(findings, sdk.Finding{
-						Rule:    r.Name(),
-						Message: msg,
-						File:    ctx.File,
-						Location: hcl.Range{
-							Filename: ctx.File,
-							Start:    hcl.Pos{Line: i + 1, Column: match[0] + 1},
-						},
-						Severity: sdk.SeverityWarning,
-						Fixable:  true,
-					})
-				}
-			}
-		}
-	}
-
-	return findings
-}
-
-// isSimpleReference checks if the string is a simple variable/local reference.
-func isSimpleReference(s string) bool {
-	s = strings.TrimSpace(s)
-	// Simple references: var.x, local.x, data.x.y, module.x.y
-	parts := strings.Split(s, ".")
-	if len(parts) < 2 {
-		return false
-	}
-
-	prefix := parts[0]
-	return prefix == "var" || prefix == "local" || prefix == "data" ||
-		prefix == "module" || prefix == "each" || prefix == "count"
-}
-
 // TerraformDocumentedVariablesRule ensures variables have descriptions.
 type TerraformDocumentedVariablesRule struct{}
 
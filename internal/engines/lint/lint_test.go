@@ -523,30 +523,6 @@ func TestTerraformResourceCountRule(t *testing.T) {
 	assert.True(t, found, "should find resource count warning")
 }
 
-func TestIsSimpleReference(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected bool
-	}{
-		{"var.name", true},
-		{"local.value", true},
-		{"data.aws_ami.latest", true},
-		{"module.vpc.id", true},
-		{"each.key", true},
-		{"count.index", true},
-		{"aws_instance.example.id", false},
-		{"hello", false},
-		{"", false},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.input, func(t *testing.T) {
-			result := isSimpleReference(tt.input)
-			assert.Equal(t, tt.expected, result)
-		})
-	}
-}
-
 func TestTerraformDocumentedOutputsRule(t *testing.T) {
 	tests := []struct {
 		name        string
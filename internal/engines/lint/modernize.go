@@ -0,0 +1,491 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// terraformVersion is a major.minor.patch Terraform version.
+type terraformVersion [3]int
+
+var (
+	// version012 introduced first-class expressions; all modernizations need it.
+	version012 = terraformVersion{0, 12, 0}
+	// version015 removed the list() and map() functions.
+	version015 = terraformVersion{0, 15, 0}
+)
+
+// parseTerraformVersion parses versions like "1.5", "0.12.31" or "v1.0.0".
+func parseTerraformVersion(s string) (terraformVersion, bool) {
+	var v terraformVersion
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) == 0 || len(parts) > 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+// less reports whether v is lower than other.
+func (v terraformVersion) less(other terraformVersion) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+	return false
+}
+
+// minimumVersion returns the lowest Terraform version a required_version
+// constraint such as ">= 0.12, < 2.0" or "~> 1.3" allows.
+func minimumVersion(constraint string) (terraformVersion, bool) {
+	var lowest terraformVersion
+	found := false
+
+	for _, part := range strings.Split(constraint, ",") {
+		part = strings.TrimSpace(part)
+		op := ""
+		for _, candidate := range []string{">=", "<=", "!=", "~>", ">", "<", "="} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				break
+			}
+		}
+		if op == "<" || op == "<=" || op == "!=" {
+			continue
+		}
+
+		v, ok := parseTerraformVersion(strings.TrimPrefix(part, op))
+		if !ok {
+			continue
+		}
+		if !found || lowest.less(v) {
+			lowest = v
+			found = true
+		}
+	}
+
+	return lowest, found
+}
+
+// moduleTargetVersion returns the minimum Terraform version required by the
+// module's terraform { required_version } setting.
+func moduleTargetVersion(files map[string]*hcl.File) (terraformVersion, bool) {
+	schema := &hcl.BodySchema{Blocks: []hcl.BlockHeaderSchema{{Type: "terraform"}}}
+	attrSchema := &hcl.BodySchema{Attributes: []hcl.AttributeSchema{{Name: "required_version"}}}
+
+	// Sort paths so the result does not depend on map order
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := files[path]
+		if file == nil {
+			continue
+		}
+		content, _, _ := file.Body.PartialContent(schema)
+		for _, block := range content.Blocks {
+			attrs, _, _ := block.Body.PartialContent(attrSchema)
+			attr, ok := attrs.Attributes["required_version"]
+			if !ok {
+				continue
+			}
+			val, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() || !val.Type().Equals(cty.String) || val.IsNull() {
+				continue
+			}
+			if v, ok := minimumVersion(val.AsString()); ok {
+				return v, true
+			}
+		}
+	}
+
+	return terraformVersion{}, false
+}
+
+// modernization is a deprecated construct and its replacement.
+type modernization struct {
+	message  string
+	severity sdk.Severity
+	edit     sdk.TextEdit
+}
+
+// modernizer finds deprecated syntax that can be rewritten for a target version.
+type modernizer struct {
+	content    []byte
+	target     terraformVersion
+	found      []modernization
+	objectKeys map[*hclsyntax.TemplateWrapExpr]bool // Templates used as object keys
+}
+
+// findModernizations returns the deprecated constructs in body, outermost first.
+func findModernizations(content []byte, body *hclsyntax.Body, target terraformVersion) []modernization {
+	m := &modernizer{content: content, target: target, objectKeys: make(map[*hclsyntax.TemplateWrapExpr]bool)}
+	m.walkBody(body, "")
+
+	sort.SliceStable(m.found, func(i, j int) bool {
+		return m.found[i].edit.Range.Start.Byte < m.found[j].edit.Range.Start.Byte
+	})
+	return m.found
+}
+
+// walkBody visits the attributes and nested blocks of a body.
+func (m *modernizer) walkBody(body *hclsyntax.Body, blockType string) {
+	for _, attr := range body.Attributes {
+		m.checkAttribute(attr, blockType)
+		// Type constraints such as list(string) are not function calls
+		if blockType == "variable" && attr.Name == "type" {
+			continue
+		}
+		_ = hclsyntax.VisitAll(attr.Expr, func(node hclsyntax.Node) hcl.Diagnostics {
+			if expr, ok := node.(hclsyntax.Expression); ok {
+				m.checkExpression(expr)
+			}
+			return nil
+		})
+	}
+	for _, block := range body.Blocks {
+		m.walkBody(block.Body, block.Type)
+	}
+}
+
+// add records a modernization replacing rng with text.
+func (m *modernizer) add(rng hcl.Range, text, message string) {
+	m.found = append(m.found, modernization{
+		message:  message,
+		severity: sdk.SeverityWarning,
+		edit:     sdk.TextEdit{Range: rng, NewText: text},
+	})
+}
+
+// source returns the source text of a range.
+func (m *modernizer) source(rng hcl.Range) string {
+	return string(rng.SliceBytes(m.content))
+}
+
+// checkAttribute handles deprecated forms that depend on where an attribute appears.
+func (m *modernizer) checkAttribute(attr *hclsyntax.Attribute, blockType string) {
+	switch {
+	case blockType == "variable" && attr.Name == "type":
+		m.checkQuotedType(attr)
+	case attr.Name == "depends_on":
+		m.checkQuotedReferences(attr, false)
+	case blockType == "lifecycle" && attr.Name == "ignore_changes":
+		m.checkQuotedReferences(attr, true)
+	}
+}
+
+// legacyTypes maps quoted type constraints to their first-class equivalents.
+var legacyTypes = map[string]string{
+	"string": "string",
+	"list":   "list(string)",
+	"map":    "map(string)",
+}
+
+// checkQuotedType converts type = "string" into type = string.
+func (m *modernizer) checkQuotedType(attr *hclsyntax.Attribute) {
+	name, ok := stringLiteral(attr.Expr)
+	if !ok {
+		return
+	}
+	replacement, ok := legacyTypes[name]
+	if !ok {
+		return
+	}
+	m.add(attr.Expr.Range(), replacement,
+		fmt.Sprintf("Quoted type constraints are deprecated: use %s instead of %q", replacement, name))
+}
+
+// checkQuotedReferences converts ["aws_instance.web"] into [aws_instance.web].
+func (m *modernizer) checkQuotedReferences(attr *hclsyntax.Attribute, ignoreChanges bool) {
+	tuple, ok := attr.Expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return
+	}
+
+	for _, elem := range tuple.Exprs {
+		ref, ok := stringLiteral(elem)
+		if !ok {
+			continue
+		}
+
+		replacement := ref
+		if ignoreChanges && ref == "*" {
+			replacement = "all"
+		} else if _, diags := hclsyntax.ParseTraversalAbs([]byte(ref), "", hcl.InitialPos); diags.HasErrors() {
+			continue
+		}
+
+		if ignoreChanges && replacement == "all" {
+			m.add(attr.Expr.Range(), replacement,
+				fmt.Sprintf("Quoted references in %s are deprecated: use all instead of [\"*\"]", attr.Name))
+			return
+		}
+		m.add(elem.Range(), replacement,
+			fmt.Sprintf("Quoted references in %s are deprecated: use %s instead of %q", attr.Name, replacement, ref))
+	}
+}
+
+// checkExpression handles deprecated expression forms.
+func (m *modernizer) checkExpression(expr hclsyntax.Expression) {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsKeyExpr:
+		// Visited before the key itself, which keeps parentheses: a bare
+		// reference would be taken as a literal key
+		if wrap, ok := e.Wrapped.(*hclsyntax.TemplateWrapExpr); ok {
+			m.objectKeys[wrap] = true
+		}
+	case *hclsyntax.TemplateWrapExpr:
+		inner := m.source(e.Wrapped.Range())
+		replacement := inner
+		if m.objectKeys[e] {
+			replacement = "(" + inner + ")"
+		}
+		m.add(e.SrcRange, replacement,
+			fmt.Sprintf("Interpolation-only expressions are deprecated: use %s instead of \"${%s}\"", replacement, inner))
+	case *hclsyntax.FunctionCallExpr:
+		switch e.Name {
+		case "list":
+			m.checkListCall(e)
+		case "map":
+			m.checkMapCall(e)
+		case "element":
+			m.checkElementCall(e)
+		}
+	}
+}
+
+// checkListCall converts list(a, b) into [a, b].
+func (m *modernizer) checkListCall(call *hclsyntax.FunctionCallExpr) {
+	if call.ExpandFinal {
+		return
+	}
+
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = m.source(arg.Range())
+	}
+	m.addRemovedFunction(call, "["+strings.Join(args, ", ")+"]", "list")
+}
+
+// checkMapCall converts map("a", 1) into { "a" = 1 }.
+func (m *modernizer) checkMapCall(call *hclsyntax.FunctionCallExpr) {
+	if call.ExpandFinal || len(call.Args)%2 != 0 {
+		return
+	}
+	if len(call.Args) == 0 {
+		m.addRemovedFunction(call, "{}", "map")
+		return
+	}
+
+	items := make([]string, 0, len(call.Args)/2)
+	for i := 0; i < len(call.Args); i += 2 {
+		key := m.source(call.Args[i].Range())
+		if _, ok := stringLiteral(call.Args[i]); !ok {
+			key = "(" + key + ")"
+		}
+		items = append(items, key+" = "+m.source(call.Args[i+1].Range()))
+	}
+	m.addRemovedFunction(call, "{ "+strings.Join(items, ", ")+" }", "map")
+}
+
+// addRemovedFunction records a replacement for list() or map(), which were
+// deprecated in Terraform 0.12 and removed in 0.15.
+func (m *modernizer) addRemovedFunction(call *hclsyntax.FunctionCallExpr, replacement, name string) {
+	message := fmt.Sprintf("The %s() function is deprecated: use %s instead", name, replacement)
+	severity := sdk.SeverityWarning
+	if !m.target.less(version015) {
+		message = fmt.Sprintf("The %s() function was removed in Terraform 0.15: use %s instead", name, replacement)
+		severity = sdk.SeverityError
+	}
+
+	m.found = append(m.found, modernization{
+		message:  message,
+		severity: severity,
+		edit:     sdk.TextEdit{Range: call.Range(), NewText: replacement},
+	})
+}
+
+// checkElementCall converts element(list, n) into list[n] when both forms are
+// guaranteed to agree: element() wraps around, index syntax does not, so the
+// index must be 0 or lower than the length of a literal list.
+func (m *modernizer) checkElementCall(call *hclsyntax.FunctionCallExpr) {
+	if call.ExpandFinal || len(call.Args) != 2 {
+		return
+	}
+
+	lit, ok := call.Args[1].(*hclsyntax.LiteralValueExpr)
+	if !ok || !lit.Val.Type().Equals(cty.Number) || lit.Val.IsNull() {
+		return
+	}
+	index, accuracy := lit.Val.AsBigFloat().Int64()
+	if accuracy != 0 || index < 0 {
+		return
+	}
+
+	list := call.Args[0]
+	if tuple, ok := list.(*hclsyntax.TupleConsExpr); ok {
+		if index >= int64(len(tuple.Exprs)) {
+			return
+		}
+	} else if index != 0 {
+		return
+	}
+
+	listSrc := m.source(list.Range())
+	switch list.(type) {
+	case *hclsyntax.ScopeTraversalExpr, *hclsyntax.RelativeTraversalExpr, *hclsyntax.IndexExpr,
+		*hclsyntax.FunctionCallExpr, *hclsyntax.TupleConsExpr, *hclsyntax.ParenthesesExpr:
+	default:
+		listSrc = "(" + listSrc + ")"
+	}
+	replacement := fmt.Sprintf("%s[%d]", listSrc, index)
+
+	m.found = append(m.found, modernization{
+		message:  fmt.Sprintf("Use index syntax %s instead of element()", replacement),
+		severity: sdk.SeverityInfo,
+		edit:     sdk.TextEdit{Range: call.Range(), NewText: replacement},
+	})
+}
+
+// stringLiteral returns the value of a quoted string without interpolations.
+func stringLiteral(expr hclsyntax.Expression) (string, bool) {
+	tmpl, ok := expr.(*hclsyntax.TemplateExpr)
+	if !ok || len(tmpl.Parts) != 1 {
+		return "", false
+	}
+	lit, ok := tmpl.Parts[0].(*hclsyntax.LiteralValueExpr)
+	if !ok || !lit.Val.Type().Equals(cty.String) {
+		return "", false
+	}
+	return lit.Val.AsString(), true
+}
+
+// applyModernizations applies non-overlapping edits; nested edits are left
+// for the next pass.
+func applyModernizations(content []byte, found []modernization) []byte {
+	var out []byte
+	pos := 0
+	for _, mod := range found {
+		start, end := mod.edit.Range.Start.Byte, mod.edit.Range.End.Byte
+		if start < pos {
+			continue
+		}
+		out = append(out, content[pos:start]...)
+		out = append(out, mod.edit.NewText...)
+		pos = end
+	}
+	return append(out, content[pos:]...)
+}
+
+// maxModernizePasses bounds the rewrites needed for nested deprecated syntax.
+const maxModernizePasses = 10
+
+// modernize rewrites all deprecated syntax in content. Each pass re-parses the
+// result so nested constructs are handled once their parent is rewritten.
+func modernize(filename string, content []byte, target terraformVersion) ([]byte, error) {
+	for pass := 0; pass < maxModernizePasses; pass++ {
+		file, diags := hclsyntax.ParseConfig(content, filename, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, fmt.Errorf("parsing %s: %s", filename, diags.Error())
+		}
+		body, ok := file.Body.(*hclsyntax.Body)
+		if !ok {
+			return content, nil
+		}
+
+		found := findModernizations(content, body, target)
+		if len(found) == 0 {
+			return content, nil
+		}
+		content = applyModernizations(content, found)
+	}
+	return content, nil
+}
+
+// TerraformDeprecatedSyntaxRule detects and rewrites syntax deprecated since Terraform 0.12.
+type TerraformDeprecatedSyntaxRule struct{}
+
+// Name returns the rule identifier.
+func (r *TerraformDeprecatedSyntaxRule) Name() string {
+	return "lint.terraform-deprecated-syntax"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *TerraformDeprecatedSyntaxRule) Description() string {
+	return "Detects deprecated syntax (interpolation-only expressions, list()/map(), " +
+		"quoted types and references) and rewrites it for the module's Terraform version"
+}
+
+// Check examines files for deprecated syntax. The target Terraform version is the
+// minimum allowed by the module's required_version; modules that still allow
+// Terraform older than 0.12 are skipped because the rewrites would break them.
+//
+// Options:
+//   - target_version: Terraform version to target instead of required_version
+func (r *TerraformDeprecatedSyntaxRule) Check(ctx *RuleContext) []sdk.Finding {
+	var findings []sdk.Finding
+
+	target, ok := r.targetVersion(ctx)
+	if ok && target.less(version012) {
+		return findings
+	}
+
+	filePath := ctx.File
+	for _, mod := range findModernizations(ctx.Content, ctx.Body, target) {
+		findings = append(findings, sdk.Finding{
+			Rule:     r.Name(),
+			Message:  mod.message,
+			File:     ctx.File,
+			Location: mod.edit.Range,
+			Severity: mod.severity,
+			Fixable:  true,
+			Fix: &sdk.Fix{
+				Description: "Modernize deprecated syntax",
+				Edits:       []sdk.TextEdit{mod.edit},
+			},
+			FixFunc: func() ([]byte, error) {
+				content, err := os.ReadFile(filePath)
+				if err != nil {
+					return nil, err
+				}
+				return modernize(filePath, content, target)
+			},
+		})
+	}
+
+	return findings
+}
+
+// targetVersion returns the configured target version or the module's minimum
+// required version. A zero version with ok=false means no constraint was found.
+func (r *TerraformDeprecatedSyntaxRule) targetVersion(ctx *RuleContext) (terraformVersion, bool) {
+	if s, ok := ctx.Config.Options["target_version"].(string); ok {
+		if v, ok := parseTerraformVersion(s); ok {
+			return v, true
+		}
+	}
+	return moduleTargetVersion(ctx.AllFiles)
+}
@@ -0,0 +1,212 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const legacyConfig = `variable "names" {
+  type    = "list"
+  default = "${list("a", "b")}"
+}
+
+resource "aws_instance" "web" {
+  ami        = "${var.ami}"
+  tags       = "${map("Name", var.name, var.key, "x")}"
+  subnet_id  = "${element(var.subnets, 0)}"
+  zone       = element(var.zones, count.index)
+  depends_on = ["aws_vpc.main", "module.network"]
+
+  lifecycle {
+    ignore_changes = ["tags", "ami"]
+  }
+}
+
+resource "aws_eip" "ip" {
+  lifecycle {
+    ignore_changes = ["*"]
+  }
+}
+`
+
+const modernConfig = `variable "names" {
+  type    = list(string)
+  default = ["a", "b"]
+}
+
+resource "aws_instance" "web" {
+  ami        = var.ami
+  tags       = { "Name" = var.name, (var.key) = "x" }
+  subnet_id  = var.subnets[0]
+  zone       = element(var.zones, count.index)
+  depends_on = [aws_vpc.main, module.network]
+
+  lifecycle {
+    ignore_changes = [tags, ami]
+  }
+}
+
+resource "aws_eip" "ip" {
+  lifecycle {
+    ignore_changes = all
+  }
+}
+`
+
+func runDeprecatedSyntaxRule(t *testing.T, files map[string]string, options map[string]interface{}) []sdk.Finding {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	var paths []string
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		paths = append(paths, path)
+	}
+
+	engine := New(&Config{Rules: map[string]RuleConfig{
+		"lint.terraform-deprecated-syntax": {Enabled: true, Options: options},
+	}})
+	findings, err := engine.Run(context.Background(), paths)
+	require.NoError(t, err)
+
+	var result []sdk.Finding
+	for _, f := range findings {
+		if f.Rule == "lint.terraform-deprecated-syntax" {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+func TestTerraformDeprecatedSyntaxRule_Fix(t *testing.T) {
+	findings := runDeprecatedSyntaxRule(t, map[string]string{"main.tf": legacyConfig}, nil)
+	require.NotEmpty(t, findings)
+
+	for _, f := range findings {
+		assert.True(t, f.Fixable)
+		require.NotNil(t, f.Fix)
+		assert.Len(t, f.Fix.Edits, 1)
+	}
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Equal(t, modernConfig, string(fixed))
+}
+
+func TestTerraformDeprecatedSyntaxRule_RequiredVersion(t *testing.T) {
+	const content = `locals {
+  zones = list("a", "b")
+}
+`
+	tests := []struct {
+		name         string
+		version      string
+		options      map[string]interface{}
+		wantFindings bool
+		wantSeverity sdk.Severity
+	}{
+		{"no required_version", "", nil, true, sdk.SeverityWarning},
+		{"pre-0.12 module is skipped", "~> 0.11.0", nil, false, ""},
+		{"0.12 module", ">= 0.12, < 0.14", nil, true, sdk.SeverityWarning},
+		{"list() removed in 0.15", ">= 1.3.0", nil, true, sdk.SeverityError},
+		{"target_version option wins", "~> 0.11.0", map[string]interface{}{"target_version": "1.0"}, true, sdk.SeverityError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]string{"main.tf": content}
+			if tt.version != "" {
+				files["versions.tf"] = "terraform {\n  required_version = \"" + tt.version + "\"\n}\n"
+			}
+
+			findings := runDeprecatedSyntaxRule(t, files, tt.options)
+			if !tt.wantFindings {
+				assert.Empty(t, findings)
+				return
+			}
+			require.Len(t, findings, 1)
+			assert.Equal(t, tt.wantSeverity, findings[0].Severity)
+		})
+	}
+}
+
+func TestTerraformDeprecatedSyntaxRule_ElementSafety(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`element(var.list, 0)`, `var.list[0]`},
+		{`element(["a", "b"], 1)`, `["a", "b"][1]`},
+		{`element(concat(var.a, var.b), 0)`, `concat(var.a, var.b)[0]`},
+		{`element(var.list, 1)`, ""},
+		{`element(["a"], 1)`, ""},
+		{`element(var.list, count.index)`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			findings := runDeprecatedSyntaxRule(t, map[string]string{
+				"main.tf": "locals {\n  x = " + tt.expr + "\n}\n",
+			}, nil)
+
+			if tt.want == "" {
+				assert.Empty(t, findings)
+				return
+			}
+			require.Len(t, findings, 1)
+			assert.Equal(t, tt.want, findings[0].Fix.Edits[0].NewText)
+		})
+	}
+}
+
+func TestTerraformDeprecatedSyntaxRule_ObjectKey(t *testing.T) {
+	findings := runDeprecatedSyntaxRule(t, map[string]string{
+		"main.tf": "locals {\n  tags = { \"${var.k}\" = 1 }\n}\n",
+	}, nil)
+
+	require.Len(t, findings, 1)
+	assert.Equal(t, "(var.k)", findings[0].Fix.Edits[0].NewText)
+}
+
+func TestEngine_FixMode(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(legacyConfig), 0o644))
+
+	_, err := New(&Config{Fix: true}).Run(context.Background(), []string{tmpFile})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(tmpFile)
+	require.NoError(t, err)
+	assert.Equal(t, modernConfig, string(content))
+}
+
+func TestMinimumVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       terraformVersion
+		wantOK     bool
+	}{
+		{">= 0.12", terraformVersion{0, 12, 0}, true},
+		{"~> 1.3.0", terraformVersion{1, 3, 0}, true},
+		{">= 0.13, < 2.0", terraformVersion{0, 13, 0}, true},
+		{"1.5.7", terraformVersion{1, 5, 7}, true},
+		{">= 0.12, >= 1.0", terraformVersion{1, 0, 0}, true},
+		{"< 2.0", terraformVersion{}, false},
+		{"latest", terraformVersion{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			got, ok := minimumVersion(tt.constraint)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/open-policy-agent/opa/v1/keys"
+)
+
+// Bundle verification defaults, matching `opa build --signing-key`.
+const (
+	DefaultBundleKeyID     = "default"
+	DefaultBundleAlgorithm = "RS256"
+)
+
+// manifestFile marks a directory as an OPA bundle.
+const manifestFile = ".manifest"
+
+// BundleVerification configures signature verification of OPA bundles.
+// Bundles are only verified when PublicKey is set; unsigned bundles are then
+// rejected.
+type BundleVerification struct {
+	PublicKey string // Path to a PEM public key (or shared secret for HS256)
+	KeyID     string // Key ID the bundles are signed with; defaults to DefaultBundleKeyID
+	Algorithm string // Signing algorithm; defaults to DefaultBundleAlgorithm
+}
+
+// isBundleDir reports whether a directory is an OPA bundle (has a .manifest).
+func isBundleDir(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, manifestFile))
+	return err == nil
+}
+
+// readBundle reads a bundle from a .tar.gz file or a bundle directory,
+// verifying its signature when a public key is configured.
+func readBundle(path string, verify BundleVerification) (*bundle.Bundle, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var reader *bundle.Reader
+	if info.IsDir() {
+		reader = bundle.NewCustomReader(bundle.NewDirectoryLoader(path))
+	} else {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		reader = bundle.NewReader(bytes.NewReader(content))
+	}
+
+	b, err := readWith(reader, verify)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle %s: %w", path, err)
+	}
+	return b, nil
+}
+
+// readWith reads a bundle, verifying it when a public key is configured.
+func readWith(reader *bundle.Reader, verify BundleVerification) (*bundle.Bundle, error) {
+	if verify.PublicKey == "" {
+		reader = reader.WithSkipBundleVerification(true)
+	} else {
+		config, err := verify.config()
+		if err != nil {
+			return nil, err
+		}
+		reader = reader.WithBundleVerificationConfig(config)
+	}
+
+	b, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// config builds the OPA verification config from the key file.
+func (v BundleVerification) config() (*bundle.VerificationConfig, error) {
+	key, err := os.ReadFile(v.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle public key: %w", err)
+	}
+
+	keyID := v.KeyID
+	if keyID == "" {
+		keyID = DefaultBundleKeyID
+	}
+	alg := v.Algorithm
+	if alg == "" {
+		alg = DefaultBundleAlgorithm
+	}
+
+	// keys.NewKeyConfig would stat the PEM content as a path, which fails for
+	// long base64 lines; the key is already read
+	keyConfig := &keys.Config{Key: string(key), Algorithm: alg}
+	return bundle.NewVerificationConfig(map[string]*bundle.KeyConfig{keyID: keyConfig}, keyID, "", nil), nil
+}
+
+// bundleModules returns the policies of a bundle, named after the bundle path.
+func bundleModules(path string, b *bundle.Bundle) []policyModule {
+	modules := make([]policyModule, 0, len(b.Modules))
+	for _, mf := range b.Modules {
+		name := mf.Path
+		if !strings.HasPrefix(name, path) {
+			name = path + "/" + strings.TrimPrefix(mf.Path, "/")
+		}
+		modules = append(modules, policyModule{path: name, content: string(mf.Raw)})
+	}
+	return modules
+}
+
+// loadBundles reads the configured bundles and the policy directories that
+// are bundles. Results are cached for the life of the engine.
+func (e *Engine) loadBundles() ([]*loadedBundle, error) {
+	if e.bundles != nil {
+		return e.bundles, nil
+	}
+
+	var paths []string
+	for _, ref := range e.config.Bundles {
+		path, err := resolveBundle(ref, e.config.BundleCache)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	for _, dir := range e.config.PolicyDirs {
+		if isBundleDir(dir) {
+			paths = append(paths, dir)
+		}
+	}
+
+	bundles := make([]*loadedBundle, 0, len(paths))
+	for _, path := range paths {
+		b, err := readBundle(path, e.config.BundleVerification)
+		if err != nil {
+			return nil, err
+		}
+		bundles = append(bundles, &loadedBundle{path: path, bundle: b})
+	}
+
+	e.bundles = bundles
+	return bundles, nil
+}
+
+// loadedBundle is a bundle read from path.
+type loadedBundle struct {
+	path   string
+	bundle *bundle.Bundle
+}
+
+// DefaultBundleCache returns the directory pulled bundles are cached in.
+func DefaultBundleCache() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "terratidy", "bundles")
+}
+
+// bundleRefPattern matches cache references such as security or security@1.2.0.
+var bundleRefPattern = regexp.MustCompile(`^([A-Za-z0-9_.-]+)(?:@([A-Za-z0-9_.+-]+))?$`)
+
+// resolveBundle returns the path of a bundle reference. Existing paths are used
+// as is; otherwise name or name@revision is looked up in the cache, name alone
+// meaning the most recently pulled revision.
+func resolveBundle(ref, cacheDir string) (string, error) {
+	if _, err := os.Stat(ref); err == nil {
+		return ref, nil
+	}
+
+	m := bundleRefPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", fmt.Errorf("bundle %s not found", ref)
+	}
+	if cacheDir == "" {
+		cacheDir = DefaultBundleCache()
+	}
+
+	name, revision := m[1], m[2]
+	if revision == "" {
+		latest, err := os.ReadFile(filepath.Join(cacheDir, name, "latest"))
+		if err != nil {
+			return "", fmt.Errorf("bundle %s not found (run terratidy policy pull)", ref)
+		}
+		revision = strings.TrimSpace(string(latest))
+	}
+
+	path := filepath.Join(cacheDir, name, revision+".tar.gz")
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("bundle %s not found in %s (run terratidy policy pull)", ref, cacheDir)
+	}
+	return path, nil
+}
+
+// PullOptions configures PullBundle.
+type PullOptions struct {
+	Name     string // Cache name; defaults to the source file name without .tar.gz
+	Revision string // Cache revision; defaults to the manifest revision, then the content hash
+	CacheDir string // Defaults to DefaultBundleCache
+	Verify   BundleVerification
+}
+
+// PulledBundle describes a bundle stored in the cache.
+type PulledBundle struct {
+	Name     string
+	Revision string
+	Path     string
+	Modules  int
+}
+
+// PullBundle copies a bundle from a file path or an http(s) URL into the
+// versioned cache, after checking that it reads (and verifies, when a public
+// key is configured).
+func PullBundle(ctx context.Context, source string, opts PullOptions) (*PulledBundle, error) {
+	content, err := fetchBundle(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate before anything enters the cache
+	b, err := readWith(bundle.NewReader(bytes.NewReader(content)), opts.Verify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle %s: %w", source, err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(source), ".gz"), ".tar")
+	}
+	revision := opts.Revision
+	if revision == "" {
+		revision = b.Manifest.Revision
+	}
+	if revision == "" {
+		sum := sha256.Sum256(content)
+		revision = hex.EncodeToString(sum[:])[:12]
+	}
+	if !bundleRefPattern.MatchString(name + "@" + revision) {
+		return nil, fmt.Errorf("invalid bundle name or revision %q", name+"@"+revision)
+	}
+
+	cacheDir := opts.CacheDir
+	if cacheDir == "" {
+		cacheDir = DefaultBundleCache()
+	}
+	dir := filepath.Join(cacheDir, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache: %w", err)
+	}
+
+	path := filepath.Join(dir, revision+".tar.gz")
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "latest"), []byte(revision+"\n"), 0o644); err != nil {
+		return nil, fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	return &PulledBundle{Name: name, Revision: revision, Path: path, Modules: len(b.Modules)}, nil
+}
+
+// fetchBundle reads a bundle from a file or an http(s) URL.
+func fetchBundle(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		content, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", source, err)
+		}
+		return content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching %s: %s", source, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
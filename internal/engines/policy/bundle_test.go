@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bundlePolicy = `package terratidy.mandated
+
+import rego.v1
+
+deny contains msg if {
+    some r in input.resources
+    r.type == "aws_instance"
+    not r.instance_type in data.mandated.instance_types
+    msg := {"msg": sprintf("%s uses a forbidden instance type", [r.name]), "rule": "mandated-instance-type"}
+}
+`
+
+// testBundle builds a bundle tarball, signed when privateKey is set.
+func testBundle(t *testing.T, revision string, privateKey []byte) []byte {
+	t.Helper()
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Revision: revision},
+		Data:     map[string]any{"mandated": map[string]any{"instance_types": []any{"t3.micro"}}},
+		Modules: []bundle.ModuleFile{{
+			Path:   "/mandated/policy.rego",
+			URL:    "/mandated/policy.rego",
+			Raw:    []byte(bundlePolicy),
+			Parsed: nil,
+		}},
+	}
+	if privateKey != nil {
+		signing := bundle.NewSigningConfig(string(privateKey), DefaultBundleAlgorithm, "")
+		require.NoError(t, b.GenerateSignature(signing, DefaultBundleKeyID, false))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, bundle.NewWriter(&buf).Write(b))
+	return buf.Bytes()
+}
+
+// testKeyPair returns PEM encoded RSA private and public keys.
+func testKeyPair(t *testing.T) (private, public []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	private = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	public = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub})
+	return private, public
+}
+
+func writeInstanceModule(t *testing.T, dir string) string {
+	t.Helper()
+	tfFile := filepath.Join(dir, "main.tf")
+	writeFile(t, tfFile, `resource "aws_instance" "web" {
+  instance_type = "m5.large"
+}
+`)
+	return tfFile
+}
+
+func TestEngine_BundleTarball(t *testing.T) {
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "mandated.tar.gz")
+	require.NoError(t, os.WriteFile(bundlePath, testBundle(t, "1.0.0", nil), 0o644))
+	tfFile := writeInstanceModule(t, dir)
+
+	engine := New(&Config{Bundles: []string{bundlePath}, NoBuiltin: true})
+	findings, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	require.Len(t, findings, 1, "bundles replace the built-in policies: %+v", findings)
+	assert.Equal(t, "policy.mandated-instance-type", findings[0].Rule)
+}
+
+func TestEngine_BundleDirectory(t *testing.T) {
+	dir := t.TempDir()
+	bundleDir := filepath.Join(dir, "bundle")
+	writeFile(t, filepath.Join(bundleDir, ".manifest"), `{"revision": "dir-1"}`)
+	writeFile(t, filepath.Join(bundleDir, "mandated", "policy.rego"), bundlePolicy)
+	writeFile(t, filepath.Join(bundleDir, "mandated", "data.json"), `{"instance_types": ["m5.large"]}`)
+	tfFile := writeInstanceModule(t, dir)
+
+	// The bundle's data.json allows m5.large, so nothing is reported
+	engine := New(&Config{PolicyDirs: []string{bundleDir}, NoBuiltin: true})
+	findings, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+
+	rules, err := engine.Rules(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, "policy.mandated-instance-type", rules[0].ID)
+}
+
+func TestEngine_BundleSignature(t *testing.T) {
+	dir := t.TempDir()
+	private, public := testKeyPair(t)
+	_, otherPublic := testKeyPair(t)
+
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(keyFile, public, 0o644))
+	otherKeyFile := filepath.Join(dir, "other.pem")
+	require.NoError(t, os.WriteFile(otherKeyFile, otherPublic, 0o644))
+
+	signed := filepath.Join(dir, "signed.tar.gz")
+	require.NoError(t, os.WriteFile(signed, testBundle(t, "1.0.0", private), 0o644))
+	unsigned := filepath.Join(dir, "unsigned.tar.gz")
+	require.NoError(t, os.WriteFile(unsigned, testBundle(t, "1.0.0", nil), 0o644))
+	tfFile := writeInstanceModule(t, dir)
+
+	run := func(bundlePath, key string) error {
+		engine := New(&Config{
+			Bundles:            []string{bundlePath},
+			BundleVerification: BundleVerification{PublicKey: key},
+		})
+		_, err := engine.Run(context.Background(), []string{tfFile})
+		return err
+	}
+
+	require.NoError(t, run(signed, keyFile))
+	assert.Error(t, run(signed, otherKeyFile), "wrong key")
+	assert.Error(t, run(unsigned, keyFile), "unsigned bundle with a key configured")
+	assert.NoError(t, run(unsigned, ""), "no key configured")
+}
+
+func TestPullBundle(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "cache")
+	source := filepath.Join(dir, "security.tar.gz")
+	require.NoError(t, os.WriteFile(source, testBundle(t, "1.2.0", nil), 0o644))
+
+	pulled, err := PullBundle(context.Background(), source, PullOptions{CacheDir: cache})
+	require.NoError(t, err)
+	assert.Equal(t, "security", pulled.Name)
+	assert.Equal(t, "1.2.0", pulled.Revision)
+	assert.Equal(t, 1, pulled.Modules)
+	assert.FileExists(t, filepath.Join(cache, "security", "1.2.0.tar.gz"))
+
+	// A local HTTP server stands in for the distribution endpoint
+	v2 := testBundle(t, "2.0.0", nil)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(v2)
+	}))
+	defer server.Close()
+
+	pulled, err = PullBundle(context.Background(), server.URL+"/bundles/security.tar.gz", PullOptions{CacheDir: cache})
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", pulled.Revision)
+
+	// Cache references resolve to a revision or the latest pull
+	path, err := resolveBundle("security@1.2.0", cache)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cache, "security", "1.2.0.tar.gz"), path)
+	path, err = resolveBundle("security", cache)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(cache, "security", "2.0.0.tar.gz"), path)
+	_, err = resolveBundle("security@9.9.9", cache)
+	assert.Error(t, err)
+
+	tfFile := writeInstanceModule(t, dir)
+	findings, err := New(&Config{Bundles: []string{"security"}, BundleCache: cache, NoBuiltin: true}).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+	assert.Len(t, findings, 1)
+}
+
+func TestPullBundle_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	source := filepath.Join(dir, "broken.tar.gz")
+	require.NoError(t, os.WriteFile(source, []byte("not a bundle"), 0o644))
+
+	_, err := PullBundle(context.Background(), source, PullOptions{CacheDir: filepath.Join(dir, "cache")})
+	require.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(dir, "cache", "broken"))
+
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	_, err = PullBundle(context.Background(), server.URL+"/missing.tar.gz", PullOptions{CacheDir: filepath.Join(dir, "cache")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
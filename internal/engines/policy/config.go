@@ -0,0 +1,131 @@
+package policy
+
+import "fmt"
+
+// ParseConfig reads the policy engine configuration from an engine config map
+// (engines.policy.config).
+func ParseConfig(cfg map[string]interface{}) (*Config, error) {
+	config := &Config{Rules: make(map[string]RuleConfig)}
+
+	for key, value := range cfg {
+		var err error
+		switch key {
+		case "policy_dirs":
+			config.PolicyDirs, err = stringList(key, value)
+		case "policy_files":
+			config.PolicyFiles, err = stringList(key, value)
+		case "data_files":
+			config.DataFiles, err = stringList(key, value)
+		case "bundles":
+			config.Bundles, err = stringList(key, value)
+		case "bundle_cache":
+			config.BundleCache, err = stringValue(key, value)
+		case "bundle_public_key":
+			config.BundleVerification.PublicKey, err = stringValue(key, value)
+		case "bundle_key_id":
+			config.BundleVerification.KeyID, err = stringValue(key, value)
+		case "bundle_key_algorithm":
+			config.BundleVerification.Algorithm, err = stringValue(key, value)
+		case "entrypoints":
+			config.Entrypoints, err = stringList(key, value)
+		case "exceptions_file":
+			config.ExceptionsFile, err = stringValue(key, value)
+		case "library":
+			config.Library, err = stringList(key, value)
+		case "builtin":
+			builtin, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("%s: expected boolean, got %T", key, value)
+			}
+			config.NoBuiltin = !builtin
+		case "rules":
+			config.Rules, err = ruleConfigs(key, value)
+		default:
+			return nil, fmt.Errorf("unknown policy option %q", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
+
+// stringList reads a list of strings from a config value
+func stringList(key string, value interface{}) ([]string, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected list of strings, got %T", key, value)
+	}
+
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected list of strings, got %T element", key, item)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+// stringValue reads a string from a config value
+func stringValue(key string, value interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: expected string, got %T", key, value)
+	}
+	return s, nil
+}
+
+// ruleConfigs reads the rules map. Each rule is either a boolean (enabled) or
+// a map with enabled (default true), severity and options.
+func ruleConfigs(key string, value interface{}) (map[string]RuleConfig, error) {
+	items, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected map of rules, got %T", key, value)
+	}
+
+	rules := make(map[string]RuleConfig, len(items))
+	for id, item := range items {
+		rule := RuleConfig{Enabled: true}
+		switch v := item.(type) {
+		case bool:
+			rule.Enabled = v
+		case map[string]interface{}:
+			for field, fv := range v {
+				switch field {
+				case "enabled":
+					enabled, ok := fv.(bool)
+					if !ok {
+						return nil, fmt.Errorf("%s.%s.enabled: expected boolean, got %T", key, id, fv)
+					}
+					rule.Enabled = enabled
+				case "severity":
+					severity, err := stringValue(key+"."+id+".severity", fv)
+					if err != nil {
+						return nil, err
+					}
+					switch severity {
+					case "error", "warning", "info":
+					default:
+						return nil, fmt.Errorf("%s.%s.severity: invalid severity %q (use info, warning or error)", key, id, severity)
+					}
+					rule.Severity = severity
+				case "options":
+					options, ok := fv.(map[string]interface{})
+					if !ok {
+						return nil, fmt.Errorf("%s.%s.options: expected map, got %T", key, id, fv)
+					}
+					rule.Options = options
+				default:
+					return nil, fmt.Errorf("%s.%s: unknown option %q", key, id, field)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s.%s: expected boolean or map, got %T", key, id, item)
+		}
+		rules[id] = rule
+	}
+	return rules, nil
+}
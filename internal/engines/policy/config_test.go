@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(map[string]interface{}{
+		"policy_dirs":  []interface{}{"policies"},
+		"policy_files": []interface{}{"extra.rego"},
+		"entrypoints":  []interface{}{"data.myorg.deny"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"policies"}, cfg.PolicyDirs)
+	assert.Equal(t, []string{"extra.rego"}, cfg.PolicyFiles)
+	assert.Equal(t, []string{"data.myorg.deny"}, cfg.Entrypoints)
+
+	cfg, err = ParseConfig(map[string]interface{}{
+		"bundles":              []interface{}{"security@1.2.0"},
+		"bundle_cache":         "/tmp/bundles",
+		"bundle_public_key":    "key.pem",
+		"bundle_key_algorithm": "ES256",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"security@1.2.0"}, cfg.Bundles)
+	assert.Equal(t, "/tmp/bundles", cfg.BundleCache)
+	assert.Equal(t, BundleVerification{PublicKey: "key.pem", Algorithm: "ES256"}, cfg.BundleVerification)
+
+	cfg, err = ParseConfig(map[string]interface{}{
+		"library":         []interface{}{"aws", "generic/tags"},
+		"exceptions_file": "exceptions.yaml",
+		"rules": map[string]interface{}{
+			"aws-s3-access-logging": false,
+			"generic-required-tags": map[string]interface{}{
+				"severity": "error",
+				"options":  map[string]interface{}{"tags": []interface{}{"CostCenter"}},
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aws", "generic/tags"}, cfg.Library)
+	assert.Equal(t, "exceptions.yaml", cfg.ExceptionsFile)
+	assert.Equal(t, map[string]RuleConfig{
+		"aws-s3-access-logging": {Enabled: false},
+		"generic-required-tags": {
+			Enabled:  true,
+			Severity: "error",
+			Options:  map[string]interface{}{"tags": []interface{}{"CostCenter"}},
+		},
+	}, cfg.Rules)
+
+	cfg, err = ParseConfig(map[string]interface{}{"builtin": false})
+	require.NoError(t, err)
+	assert.True(t, cfg.NoBuiltin)
+
+	cfg, err = ParseConfig(nil)
+	require.NoError(t, err)
+	assert.Empty(t, cfg.PolicyDirs)
+	assert.False(t, cfg.NoBuiltin)
+}
+
+func TestParseConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  map[string]interface{}
+		want string
+	}{
+		{"unknown key", map[string]interface{}{"policy_dir": "policies"}, `unknown policy option "policy_dir"`},
+		{"not a list", map[string]interface{}{"policy_dirs": "policies"}, "policy_dirs: expected list of strings"},
+		{"bad element", map[string]interface{}{"entrypoints": []interface{}{1}}, "entrypoints: expected list of strings"},
+		{"builtin not a boolean", map[string]interface{}{"builtin": "no"}, "builtin: expected boolean"},
+		{"rules not a map", map[string]interface{}{"rules": []interface{}{"a"}}, "rules: expected map of rules"},
+		{
+			"bad rule severity",
+			map[string]interface{}{"rules": map[string]interface{}{"a": map[string]interface{}{"severity": "fatal"}}},
+			`rules.a.severity: invalid severity "fatal"`,
+		},
+		{
+			"unknown rule option",
+			map[string]interface{}{"rules": map[string]interface{}{"a": map[string]interface{}{"enable": true}}},
+			`rules.a: unknown option "enable"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(tt.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
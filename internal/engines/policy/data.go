@@ -0,0 +1,239 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dataDirName is the name of the folders next to policies that hold data documents.
+const dataDirName = "data"
+
+// overlayFiles are the per-directory data overlays, in order of precedence.
+var overlayFiles = []string{".terratidy-data.yaml", ".terratidy-data.yml", ".terratidy-data.json"}
+
+// isDataFile reports whether a file is a JSON or YAML data document.
+func isDataFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// loadDataDocument reads a JSON or YAML document. The top-level value must be
+// an object; its keys become documents under data.
+func loadDataDocument(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var doc any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, &doc)
+	} else {
+		err = yaml.Unmarshal(content, &doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if doc == nil {
+		return map[string]any{}, nil
+	}
+
+	// Round-trip through JSON so YAML values have the types OPA expects
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s: %w", path, err)
+	}
+	var obj map[string]any
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, fmt.Errorf("%s: top-level value must be an object", path)
+	}
+	return obj, nil
+}
+
+// mergeData merges src into dst. Objects are merged recursively; any other
+// value in src replaces the one in dst.
+func mergeData(dst, src map[string]any) map[string]any {
+	for key, value := range src {
+		srcObj, srcIsObj := value.(map[string]any)
+		dstObj, dstIsObj := dst[key].(map[string]any)
+		if srcIsObj && dstIsObj {
+			dst[key] = mergeData(dstObj, srcObj)
+			continue
+		}
+		dst[key] = value
+	}
+	return dst
+}
+
+// dataFiles returns the configured data files followed by the documents in
+// data/ folders of the policy directories and next to the policy files.
+func (e *Engine) dataFiles() ([]string, error) {
+	files := append([]string{}, e.config.DataFiles...)
+
+	var dataDirs []string
+	for _, dir := range e.config.PolicyDirs {
+		if isBundleDir(dir) {
+			continue
+		}
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() && info.Name() == dataDirName {
+				dataDirs = append(dataDirs, path)
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("walking %s: %w", dir, err)
+		}
+	}
+	for _, file := range e.config.PolicyFiles {
+		dataDirs = append(dataDirs, filepath.Join(filepath.Dir(file), dataDirName))
+	}
+
+	seen := make(map[string]bool)
+	for _, dir := range dataDirs {
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+
+		var docs []string
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && isDataFile(path) {
+				docs = append(docs, path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("walking %s: %w", dir, err)
+		}
+		sort.Strings(docs)
+		files = append(files, docs...)
+	}
+
+	return files, nil
+}
+
+// loadData merges bundle data and all data documents into the base data for
+// evaluation.
+func (e *Engine) loadData() (map[string]any, error) {
+	bundles, err := e.loadBundles()
+	if err != nil {
+		return nil, err
+	}
+	files, err := e.dataFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	data := make(map[string]any)
+	for _, b := range bundles {
+		mergeData(data, b.bundle.Data)
+	}
+	for _, file := range files {
+		doc, err := loadDataDocument(file)
+		if err != nil {
+			return nil, err
+		}
+		mergeData(data, doc)
+	}
+
+	// Rule options are available to policies as data.rule_options[<rule id>]
+	options := make(map[string]any)
+	for rule, cfg := range e.config.Rules {
+		if len(cfg.Options) > 0 {
+			options[strings.TrimPrefix(rule, "policy.")] = cfg.Options
+		}
+	}
+	if len(options) > 0 {
+		options, err := deepCopy(options)
+		if err != nil {
+			return nil, fmt.Errorf("converting rule options: %w", err)
+		}
+		mergeData(data, map[string]any{"rule_options": options})
+	}
+	return data, nil
+}
+
+// overlayData returns the data for a module directory: the base data with the
+// overlays of the directory and its parents merged on top, innermost last. It
+// returns nil when no overlay applies.
+func overlayData(base map[string]any, dir string) (map[string]any, error) {
+	var overlays []string
+	for _, d := range overlayDirs(dir) {
+		for _, name := range overlayFiles {
+			path := filepath.Join(d, name)
+			if _, err := os.Stat(path); err == nil {
+				overlays = append(overlays, path)
+				break
+			}
+		}
+	}
+	if len(overlays) == 0 {
+		return nil, nil
+	}
+
+	// Copy the base so overlays of one module do not leak into another
+	data, err := deepCopy(base)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range overlays {
+		doc, err := loadDataDocument(path)
+		if err != nil {
+			return nil, err
+		}
+		mergeData(data, doc)
+	}
+	return data, nil
+}
+
+// overlayDirs lists the directories whose overlays apply to dir, outermost
+// first: from the working directory down to dir when dir is inside it,
+// otherwise dir alone.
+func overlayDirs(dir string) []string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return []string{dir}
+	}
+	cwd, err := os.Getwd()
+	if err != nil || !isWithin(abs, cwd) {
+		return []string{abs}
+	}
+
+	var dirs []string
+	for d := abs; ; d = filepath.Dir(d) {
+		dirs = append([]string{d}, dirs...)
+		if d == cwd || d == filepath.Dir(d) {
+			break
+		}
+	}
+	return dirs
+}
+
+func deepCopy(data map[string]any) (map[string]any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
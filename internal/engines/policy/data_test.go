@@ -0,0 +1,161 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dataPolicy = `package terratidy.data
+
+import rego.v1
+
+deny contains msg if {
+    some r in input.resources
+    r.type == "aws_instance"
+    not r.instance_type in data.allowed_instance_types
+    msg := {"msg": sprintf("%s: instance type %s is not allowed", [r.name, r.instance_type]), "rule": "instance-type"}
+}
+
+deny contains msg if {
+    some r in input.resources
+    some tag in data.tags.required
+    not r.tags[tag]
+    msg := {"msg": sprintf("%s: missing tag %s", [r.name, tag]), "rule": "required-tag"}
+}
+`
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func messages(t *testing.T, engine *Engine, files ...string) []string {
+	t.Helper()
+	findings, err := engine.Run(context.Background(), files)
+	require.NoError(t, err)
+
+	msgs := make([]string, 0, len(findings))
+	for _, f := range findings {
+		msgs = append(msgs, f.Message)
+	}
+	sort.Strings(msgs)
+	return msgs
+}
+
+func TestEngine_DataDocuments(t *testing.T) {
+	dir := t.TempDir()
+	policyDir := filepath.Join(dir, "policies")
+	writeFile(t, filepath.Join(policyDir, "data.rego"), dataPolicy)
+	writeFile(t, filepath.Join(policyDir, "data", "instances.json"), `{"allowed_instance_types": ["t3.micro", "t3.small"]}`)
+	dataFile := filepath.Join(dir, "tags.yaml")
+	writeFile(t, dataFile, "tags:\n  required: [Owner]\n")
+
+	tfFile := filepath.Join(dir, "main.tf")
+	writeFile(t, tfFile, `resource "aws_instance" "web" {
+  instance_type = "m5.large"
+  tags = {
+    Owner = "platform"
+  }
+}
+
+resource "aws_instance" "db" {
+  instance_type = "t3.micro"
+}
+`)
+
+	engine := New(&Config{PolicyDirs: []string{policyDir}, DataFiles: []string{dataFile}, NoBuiltin: true})
+	assert.Equal(t, []string{
+		"db: missing tag Owner",
+		"web: instance type m5.large is not allowed",
+	}, messages(t, engine, tfFile))
+}
+
+func TestEngine_DataOverlays(t *testing.T) {
+	dir := t.TempDir()
+	policyFile := filepath.Join(dir, "policies", "data.rego")
+	writeFile(t, policyFile, dataPolicy)
+	writeFile(t, filepath.Join(dir, "policies", "data", "base.yaml"), `allowed_instance_types: [t3.micro]
+tags:
+  required: [Owner]
+`)
+
+	module := `resource "aws_instance" "web" {
+  instance_type = "m5.large"
+}
+`
+	prod := filepath.Join(dir, "live", "prod", "main.tf")
+	dev := filepath.Join(dir, "live", "dev", "main.tf")
+	writeFile(t, prod, module)
+	writeFile(t, dev, module)
+
+	// prod requires more tags and allows larger instances; objects merge, lists replace
+	writeFile(t, filepath.Join(dir, "live", "prod", ".terratidy-data.yaml"), `allowed_instance_types: [m5.large]
+tags:
+  required: [Owner, CostCenter]
+`)
+
+	engine := New(&Config{PolicyFiles: []string{policyFile}, NoBuiltin: true})
+	assert.Equal(t, []string{
+		"web: instance type m5.large is not allowed",
+		"web: missing tag Owner",
+	}, messages(t, engine, dev))
+	assert.Equal(t, []string{
+		"web: missing tag CostCenter",
+		"web: missing tag Owner",
+	}, messages(t, engine, prod))
+
+	// Both modules in one run do not share overlays
+	assert.Len(t, messages(t, engine, dev, prod), 4)
+}
+
+func TestEngine_InvalidDataDocument(t *testing.T) {
+	dir := t.TempDir()
+	dataFile := filepath.Join(dir, "bad.json")
+	writeFile(t, dataFile, `["not", "an", "object"]`)
+	tfFile := filepath.Join(dir, "main.tf")
+	writeFile(t, tfFile, `resource "aws_instance" "web" {}`)
+
+	_, err := New(&Config{DataFiles: []string{dataFile}}).Run(context.Background(), []string{tfFile})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "top-level value must be an object")
+}
+
+func TestMergeData(t *testing.T) {
+	dst := map[string]any{
+		"tags":  map[string]any{"required": []any{"Owner"}, "optional": []any{"Team"}},
+		"limit": float64(1),
+	}
+	src := map[string]any{
+		"tags":  map[string]any{"required": []any{"Owner", "Env"}},
+		"extra": true,
+	}
+
+	assert.Equal(t, map[string]any{
+		"tags":  map[string]any{"required": []any{"Owner", "Env"}, "optional": []any{"Team"}},
+		"limit": float64(1),
+		"extra": true,
+	}, mergeData(dst, src))
+}
+
+func TestOverlayDirs(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		cwd,
+		filepath.Join(cwd, "live"),
+		filepath.Join(cwd, "live", "prod"),
+	}, overlayDirs(filepath.Join("live", "prod")))
+
+	outside := t.TempDir()
+	assert.Equal(t, []string{outside}, overlayDirs(outside))
+}
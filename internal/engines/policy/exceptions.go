@@ -0,0 +1,235 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// exceptionDateLayout is the format of exception expiry dates.
+const exceptionDateLayout = "2006-01-02"
+
+// Exception waives a policy rule for matching resources until it expires.
+// Exceptions live in a central register (exceptions.yaml) so that every waiver
+// has a justification and an approver on record.
+type Exception struct {
+	Rule          string `yaml:"rule"`     // Rule ID, with or without the policy. prefix; may be a glob
+	Resource      string `yaml:"resource"` // Configuration address or glob, e.g. module.app.aws_s3_bucket.*
+	Justification string `yaml:"justification"`
+	Approver      string `yaml:"approver"`
+	Expires       string `yaml:"expires"` // Last day the exception is valid, YYYY-MM-DD
+
+	expires time.Time
+}
+
+// exceptionsFile is the content of an exceptions register.
+type exceptionsFile struct {
+	Exceptions []Exception `yaml:"exceptions"`
+}
+
+// LoadExceptions reads and validates an exceptions register. Every exception
+// needs a rule, a resource, a justification, an approver and an expiry date.
+func LoadExceptions(file string) ([]Exception, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", file, err)
+	}
+
+	var doc exceptionsFile
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	for i := range doc.Exceptions {
+		ex := &doc.Exceptions[i]
+		var missing []string
+		for _, field := range []struct{ name, value string }{
+			{"rule", ex.Rule},
+			{"resource", ex.Resource},
+			{"justification", ex.Justification},
+			{"approver", ex.Approver},
+			{"expires", ex.Expires},
+		} {
+			if strings.TrimSpace(field.value) == "" {
+				missing = append(missing, field.name)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%s: exception %d: missing %s", file, i+1, strings.Join(missing, ", "))
+		}
+		for _, pattern := range []string{ex.Rule, ex.Resource} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("%s: exception %d: invalid pattern %q", file, i+1, pattern)
+			}
+		}
+
+		expires, err := time.Parse(exceptionDateLayout, ex.Expires)
+		if err != nil {
+			return nil, fmt.Errorf("%s: exception %d: expires must be a date (YYYY-MM-DD), got %q", file, i+1, ex.Expires)
+		}
+		ex.expires = expires
+	}
+
+	return doc.Exceptions, nil
+}
+
+// Expired reports whether the exception is no longer valid at now. An
+// exception is valid through the whole of its expiry day.
+func (ex Exception) Expired(now time.Time) bool {
+	return !now.Before(ex.expires.AddDate(0, 0, 1))
+}
+
+// Matches reports whether the exception covers a finding of rule on the
+// resource at address.
+func (ex Exception) Matches(rule, address string) bool {
+	pattern := strings.TrimPrefix(ex.Rule, "policy.")
+	if ok, _ := path.Match(pattern, strings.TrimPrefix(rule, "policy.")); !ok {
+		return false
+	}
+	ok, _ := path.Match(ex.Resource, address)
+	return ok
+}
+
+// loadExceptions reads the configured exceptions register, if any.
+func (e *Engine) loadExceptions() ([]Exception, error) {
+	if e.config.ExceptionsFile == "" {
+		return nil, nil
+	}
+	exceptions, err := LoadExceptions(e.config.ExceptionsFile)
+	if err != nil {
+		return nil, fmt.Errorf("loading exceptions: %w", err)
+	}
+	return exceptions, nil
+}
+
+// exceptionsData is the exceptions register as exposed to policies in
+// data.exceptions.
+func exceptionsData(exceptions []Exception, now time.Time) []any {
+	list := make([]any, len(exceptions))
+	for i, ex := range exceptions {
+		list[i] = map[string]any{
+			"rule":          ex.Rule,
+			"resource":      ex.Resource,
+			"justification": ex.Justification,
+			"approver":      ex.Approver,
+			"expires":       ex.Expires,
+			"expired":       ex.Expired(now),
+		}
+	}
+	return list
+}
+
+// applyExceptions downgrades findings covered by a valid exception to info and
+// reports findings covered only by expired exceptions as errors. The resource
+// of a finding is the innermost block of sources that contains it.
+func applyExceptions(findings []sdk.Finding, exceptions []Exception, sources sourceIndex, now time.Time) {
+	if len(exceptions) == 0 {
+		return
+	}
+
+	for i := range findings {
+		f := &findings[i]
+		address := sources.addressAt(f.File, f.Location.Start.Line)
+
+		var valid, expired *Exception
+		for j := range exceptions {
+			ex := &exceptions[j]
+			if !ex.Matches(f.Rule, address) {
+				continue
+			}
+			if ex.Expired(now) {
+				if expired == nil {
+					expired = ex
+				}
+			} else if valid == nil {
+				valid = ex
+			}
+		}
+
+		switch {
+		case valid != nil:
+			f.Severity = sdk.SeverityInfo
+			f.Message = fmt.Sprintf("%s (exception until %s approved by %s: %s)",
+				f.Message, valid.Expires, valid.Approver, valid.Justification)
+		case expired != nil:
+			f.Severity = sdk.SeverityError
+			f.Message = fmt.Sprintf("%s (exception expired on %s, approved by %s: %s)",
+				f.Message, expired.Expires, expired.Approver, expired.Justification)
+		}
+	}
+}
+
+// moduleSources indexes the resources, data sources and module calls of a
+// module's input by configuration address.
+func moduleSources(moduleData map[string]any) sourceIndex {
+	sources := make(sourceIndex)
+	for _, kind := range []struct{ key, prefix string }{
+		{"resources", ""},
+		{"data", "data."},
+		{"modules", "module."},
+	} {
+		blocks, _ := moduleData[kind.key].([]any)
+		for _, b := range blocks {
+			block, _ := b.(map[string]any)
+			address := kind.prefix
+			if t, ok := block["type"].(string); ok && kind.key != "modules" {
+				address += t + "."
+			}
+			name, ok := block["name"].(string)
+			if !ok {
+				continue
+			}
+			rng, ok := inputRange(block["_range"])
+			if !ok {
+				continue
+			}
+			sources[address+name] = rng
+		}
+	}
+	return sources
+}
+
+// inputRange converts a _range value of the input back to a source range.
+func inputRange(v any) (hcl.Range, bool) {
+	r, ok := v.(map[string]any)
+	if !ok {
+		return hcl.Range{}, false
+	}
+	file, _ := r["file"].(string)
+	startLine, ok1 := intValue(r["start_line"])
+	endLine, ok2 := intValue(r["end_line"])
+	if file == "" || !ok1 || !ok2 {
+		return hcl.Range{}, false
+	}
+	return hcl.Range{
+		Filename: file,
+		Start:    hcl.Pos{Line: startLine},
+		End:      hcl.Pos{Line: endLine},
+	}, true
+}
+
+// addressAt returns the address of the innermost block containing a line of
+// file, or "" when there is none.
+func (s sourceIndex) addressAt(file string, line int) string {
+	best := ""
+	bestSize := -1
+	if line <= 0 {
+		return best
+	}
+	for address, rng := range s {
+		if rng.Filename != file || line < rng.Start.Line || line > rng.End.Line {
+			continue
+		}
+		size := rng.End.Line - rng.Start.Line
+		if bestSize < 0 || size < bestSize || size == bestSize && address < best {
+			best, bestSize = address, size
+		}
+	}
+	return best
+}
@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const exceptionsRegister = `exceptions:
+  - rule: aws-s3-encryption
+    resource: aws_s3_bucket.logs
+    justification: Access logs hold no customer data
+    approver: security@example.com
+    expires: 2999-12-31
+  - rule: policy.aws-ebs-*
+    resource: aws_ebs_volume.*
+    justification: Migration to encrypted volumes
+    approver: platform@example.com
+    expires: 2000-01-31
+`
+
+func writeExceptions(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "exceptions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadExceptions(t *testing.T) {
+	exceptions, err := LoadExceptions(writeExceptions(t, exceptionsRegister))
+	require.NoError(t, err)
+	require.Len(t, exceptions, 2)
+	assert.Equal(t, "aws_s3_bucket.logs", exceptions[0].Resource)
+	assert.Equal(t, "security@example.com", exceptions[0].Approver)
+	assert.Equal(t, "2000-01-31", exceptions[1].Expires)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			"missing fields",
+			"exceptions:\n  - rule: a\n    resource: b\n    expires: 2030-01-01\n",
+			"exception 1: missing justification, approver",
+		},
+		{
+			"bad date",
+			"exceptions:\n  - {rule: a, resource: b, justification: c, approver: d, expires: next year}\n",
+			`expires must be a date (YYYY-MM-DD), got "next year"`,
+		},
+		{
+			"bad pattern",
+			"exceptions:\n  - {rule: a, resource: \"[\", justification: c, approver: d, expires: 2030-01-01}\n",
+			`invalid pattern "["`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadExceptions(writeExceptions(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestException_Expired(t *testing.T) {
+	ex := Exception{expires: time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)}
+
+	assert.False(t, ex.Expired(time.Date(2030, 6, 30, 23, 59, 0, 0, time.UTC)), "valid through its expiry day")
+	assert.True(t, ex.Expired(time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestException_Matches(t *testing.T) {
+	ex := Exception{Rule: "aws-s3-*", Resource: "module.app.aws_s3_bucket.*"}
+
+	assert.True(t, ex.Matches("policy.aws-s3-encryption", "module.app.aws_s3_bucket.logs"))
+	assert.False(t, ex.Matches("policy.aws-ebs-encryption", "module.app.aws_s3_bucket.logs"))
+	assert.False(t, ex.Matches("policy.aws-s3-encryption", "aws_s3_bucket.logs"))
+
+	wildcard := Exception{Rule: "policy.required-version", Resource: "*"}
+	assert.True(t, wildcard.Matches("policy.required-version", ""), "findings outside resources")
+}
+
+func TestEngine_Exceptions(t *testing.T) {
+	dir := t.TempDir()
+	tfFile := filepath.Join(dir, "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`resource "aws_s3_bucket" "logs" {}
+
+resource "aws_s3_bucket" "data" {}
+
+resource "aws_ebs_volume" "cache" {
+  size = 10
+}
+`), 0o644))
+
+	// data.exceptions is available to policies
+	policyDir := filepath.Join(dir, "policies")
+	require.NoError(t, os.MkdirAll(policyDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "register.rego"), []byte(`package terratidy.register
+
+import rego.v1
+
+info contains sprintf("expired exception approved by %s", [ex.approver]) if {
+	some ex in data.exceptions
+	ex.expired
+}
+`), 0o644))
+
+	cfg := &Config{
+		PolicyDirs:     []string{policyDir},
+		Library:        []string{"aws/encryption"},
+		ExceptionsFile: writeExceptions(t, exceptionsRegister),
+		NoBuiltin:      true,
+	}
+	findings, err := New(cfg).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	byMessage := make(map[string]sdk.Finding)
+	for _, f := range findings {
+		byMessage[f.Message] = f
+	}
+	require.Len(t, byMessage, 4, "%v", findings)
+
+	waived := byMessage["S3 bucket logs does not configure server-side encryption"+
+		" (exception until 2999-12-31 approved by security@example.com: Access logs hold no customer data)"]
+	assert.Equal(t, sdk.SeverityInfo, waived.Severity)
+
+	assert.Equal(t, sdk.SeverityError, byMessage["S3 bucket data does not configure server-side encryption"].Severity)
+
+	expired := byMessage["EBS volume cache is not encrypted"+
+		" (exception expired on 2000-01-31, approved by platform@example.com: Migration to encrypted volumes)"]
+	assert.Equal(t, sdk.SeverityError, expired.Severity)
+
+	assert.Contains(t, byMessage, "expired exception approved by platform@example.com")
+}
+
+func TestEngine_ExceptionsRepository(t *testing.T) {
+	_, files := writeRepository(t)
+
+	policyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "calls.rego"), []byte(`package terratidy.calls
+
+import rego.v1
+
+# METADATA
+# custom:
+#   id: shared-module-call
+deny_repo contains msg if {
+	some m in input.modules
+	some c in m.module_calls
+	c.local
+	msg := {"msg": sprintf("%s calls %s", [m.path, c.path]), "file": c._file, "line": c._range.start_line}
+}
+`), 0o644))
+
+	// The address repeats in both modules: the exception follows the finding's file
+	register := `exceptions:
+  - rule: shared-module-call
+    resource: module.app
+    justification: Shared application module
+    approver: platform@example.com
+    expires: 2999-12-31
+`
+	cfg := &Config{
+		PolicyDirs:     []string{policyDir},
+		ExceptionsFile: writeExceptions(t, register),
+		NoBuiltin:      true,
+	}
+	findings, err := New(cfg).Run(context.Background(), files)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+
+	for _, f := range findings {
+		assert.Equal(t, "policy.shared-module-call", f.Rule)
+		assert.Equal(t, sdk.SeverityInfo, f.Severity)
+		assert.Contains(t, f.Message, "(exception until 2999-12-31 approved by platform@example.com")
+	}
+}
+
+func TestEngine_ExceptionsBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	tfFile := filepath.Join(dir, "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`resource "aws_security_group" "bastion" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_security_group" "admin" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+`), 0o644))
+
+	cfg := &Config{ExceptionsFile: writeExceptions(t, `exceptions:
+  - rule: no-public-ssh
+    resource: aws_security_group.bastion
+    justification: Bastion host
+    approver: security@example.com
+    expires: 2999-12-31
+`)}
+	findings, err := New(cfg).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	severities := make(map[int]sdk.Severity)
+	for _, f := range findings {
+		if f.Rule == "policy.no-public-ssh" {
+			severities[f.Location.Start.Line] = f.Severity
+		}
+	}
+	assert.Equal(t, map[int]sdk.Severity{1: sdk.SeverityInfo, 9: sdk.SeverityError}, severities)
+}
+
+func TestEngine_ExceptionsInvalid(t *testing.T) {
+	cfg := &Config{ExceptionsFile: filepath.Join(t.TempDir(), "missing.yaml")}
+	_, err := New(cfg).Run(context.Background(), []string{"main.tf"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "loading exceptions")
+}
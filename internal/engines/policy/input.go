@@ -0,0 +1,281 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// InputSchemaVersion is the version of the policy input document. It is
+// exposed to policies as input._schema_version and changes whenever the
+// shape of the input changes incompatibly.
+const InputSchemaVersion = 1
+
+// moduleFile is a parsed Terraform file of a module.
+type moduleFile struct {
+	path    string
+	content []byte
+	body    *hclsyntax.Body
+}
+
+// parseFile reads and parses a file as native HCL syntax, or returns nil.
+func (e *Engine) parseFile(path string) *moduleFile {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	hclFile, diags := e.parser.ParseHCL(content, path)
+	if diags.HasErrors() {
+		return nil
+	}
+
+	body, ok := hclFile.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil
+	}
+	return &moduleFile{path: path, content: content, body: body}
+}
+
+// policyFunctions is the subset of the Terraform function library available
+// when evaluating expressions for policy input.
+var policyFunctions = map[string]function.Function{
+	"abs":        stdlib.AbsoluteFunc,
+	"ceil":       stdlib.CeilFunc,
+	"coalesce":   stdlib.CoalesceFunc,
+	"compact":    stdlib.CompactFunc,
+	"concat":     stdlib.ConcatFunc,
+	"contains":   stdlib.ContainsFunc,
+	"distinct":   stdlib.DistinctFunc,
+	"flatten":    stdlib.FlattenFunc,
+	"floor":      stdlib.FloorFunc,
+	"format":     stdlib.FormatFunc,
+	"join":       stdlib.JoinFunc,
+	"jsonencode": stdlib.JSONEncodeFunc,
+	"keys":       stdlib.KeysFunc,
+	"length":     stdlib.LengthFunc,
+	"lookup":     stdlib.LookupFunc,
+	"lower":      stdlib.LowerFunc,
+	"max":        stdlib.MaxFunc,
+	"merge":      stdlib.MergeFunc,
+	"min":        stdlib.MinFunc,
+	"replace":    stdlib.ReplaceFunc,
+	"reverse":    stdlib.ReverseListFunc,
+	"sort":       stdlib.SortFunc,
+	"split":      stdlib.SplitFunc,
+	"substr":     stdlib.SubstrFunc,
+	"title":      stdlib.TitleFunc,
+	"trimspace":  stdlib.TrimSpaceFunc,
+	"upper":      stdlib.UpperFunc,
+	"values":     stdlib.ValuesFunc,
+	"zipmap":     stdlib.ZipmapFunc,
+}
+
+// moduleEvalContext builds the evaluation context for a module. Locals that
+// only depend on literals, other such locals and policyFunctions are resolved;
+// everything else (variables, resources, data sources) stays unknown.
+func moduleEvalContext(files []*moduleFile) *hcl.EvalContext {
+	pending := make(map[string]hcl.Expression)
+	for _, file := range files {
+		for _, block := range file.body.Blocks {
+			if block.Type != "locals" {
+				continue
+			}
+			for name, attr := range block.Body.Attributes {
+				pending[name] = attr.Expr
+			}
+		}
+	}
+
+	names := make([]string, 0, len(pending))
+	for name := range pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	resolved := make(map[string]cty.Value)
+	evalCtx := &hcl.EvalContext{
+		Variables: map[string]cty.Value{"local": cty.EmptyObjectVal},
+		Functions: policyFunctions,
+	}
+
+	// Locals may reference each other in any order, so resolve until no progress
+	for progress := true; progress; {
+		progress = false
+		for _, name := range names {
+			expr, ok := pending[name]
+			if !ok {
+				continue
+			}
+			val, diags := expr.Value(evalCtx)
+			if diags.HasErrors() || !val.IsWhollyKnown() {
+				continue
+			}
+			resolved[name] = val
+			delete(pending, name)
+			progress = true
+		}
+		evalCtx.Variables["local"] = cty.ObjectVal(resolved)
+	}
+
+	return evalCtx
+}
+
+// inputBuilder converts parsed files into the policy input document.
+type inputBuilder struct {
+	evalCtx *hcl.EvalContext
+}
+
+// blockData converts a block into an input node. Attributes are evaluated to
+// JSON values where possible and nested blocks are always lists.
+func (b *inputBuilder) blockData(block *hclsyntax.Block, file *moduleFile) map[string]any {
+	labels := make([]any, len(block.Labels))
+	for i, label := range block.Labels {
+		labels[i] = label
+	}
+
+	ranges := make(map[string]any, len(block.Body.Attributes))
+	data := map[string]any{
+		"_block_type": block.Type,
+		"_labels":     labels,
+		"_file":       file.path,
+		"_range":      rangeData(block.Range()),
+		"_ranges":     ranges,
+	}
+
+	for name, attr := range block.Body.Attributes {
+		data[name] = b.exprValue(attr.Expr, file.content)
+		ranges[name] = rangeData(attr.SrcRange)
+	}
+
+	nestedBlocks := make(map[string][]any)
+	for _, nested := range block.Body.Blocks {
+		nestedBlocks[nested.Type] = append(nestedBlocks[nested.Type], b.blockData(nested, file))
+	}
+	for blockType, list := range nestedBlocks {
+		data[blockType] = list
+	}
+
+	return data
+}
+
+// exprValue evaluates an expression to a JSON-compatible value. Object and
+// tuple constructors are converted element by element so that a single
+// unresolved item does not hide its statically known siblings.
+func (b *inputBuilder) exprValue(expr hclsyntax.Expression, content []byte) any {
+	switch e := expr.(type) {
+	case *hclsyntax.ObjectConsExpr:
+		obj := make(map[string]any, len(e.Items))
+		for _, item := range e.Items {
+			key, diags := item.KeyExpr.Value(b.evalCtx)
+			if diags.HasErrors() || !key.IsWhollyKnown() || key.IsNull() {
+				return unresolvedValue(expr, content)
+			}
+			key, err := convert.Convert(key, cty.String)
+			if err != nil {
+				return unresolvedValue(expr, content)
+			}
+			obj[key.AsString()] = b.exprValue(item.ValueExpr, content)
+		}
+		return obj
+
+	case *hclsyntax.TupleConsExpr:
+		list := make([]any, len(e.Exprs))
+		for i, elem := range e.Exprs {
+			list[i] = b.exprValue(elem, content)
+		}
+		return list
+	}
+
+	val, diags := expr.Value(b.evalCtx)
+	if diags.HasErrors() || !val.IsWhollyKnown() {
+		return unresolvedValue(expr, content)
+	}
+
+	converted, err := ctyToJSON(val)
+	if err != nil {
+		return unresolvedValue(expr, content)
+	}
+	return converted
+}
+
+// unresolvedValue describes an expression that cannot be evaluated statically.
+func unresolvedValue(expr hclsyntax.Expression, content []byte) map[string]any {
+	refs := make(map[string]bool)
+	for _, traversal := range expr.Variables() {
+		refs[traversalString(traversal)] = true
+	}
+
+	references := make([]string, 0, len(refs))
+	for ref := range refs {
+		references = append(references, ref)
+	}
+	sort.Strings(references)
+
+	list := make([]any, len(references))
+	for i, ref := range references {
+		list[i] = ref
+	}
+
+	return map[string]any{
+		"_expression": string(expr.Range().SliceBytes(content)),
+		"_references": list,
+		"_range":      rangeData(expr.Range()),
+	}
+}
+
+// traversalString renders a traversal such as aws_instance.web[0].id.
+func traversalString(traversal hcl.Traversal) string {
+	var b strings.Builder
+	for _, step := range traversal {
+		switch s := step.(type) {
+		case hcl.TraverseRoot:
+			b.WriteString(s.Name)
+		case hcl.TraverseAttr:
+			b.WriteString("." + s.Name)
+		case hcl.TraverseIndex:
+			key, err := ctyjson.Marshal(s.Key, s.Key.Type())
+			if err != nil {
+				b.WriteString("[?]")
+				continue
+			}
+			b.WriteString("[" + string(key) + "]")
+		case hcl.TraverseSplat:
+			b.WriteString("[*]")
+		}
+	}
+	return b.String()
+}
+
+// ctyToJSON converts a known cty value to plain JSON-compatible Go values.
+func ctyToJSON(val cty.Value) (any, error) {
+	raw, err := ctyjson.Marshal(val, val.Type())
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// rangeData converts a source range to its input representation.
+func rangeData(r hcl.Range) map[string]any {
+	return map[string]any{
+		"file":         r.Filename,
+		"start_line":   r.Start.Line,
+		"start_column": r.Start.Column,
+		"end_line":     r.End.Line,
+		"end_column":   r.End.Column,
+	}
+}
@@ -0,0 +1,186 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModuleToJSON_TypedValues(t *testing.T) {
+	tmpDir := t.TempDir()
+	mainContent := `locals {
+  env    = "prod"
+  prefix = "${local.env}-app"
+  ports  = [22, 443]
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "${local.prefix}-logs"
+  acl    = "public-read"
+  force  = true
+  count  = 2
+  arn    = aws_kms_key.main.arn
+  policy = jsonencode({ Version = "2012-10-17" })
+
+  tags = {
+    Name  = upper(local.env)
+    Owner = var.owner
+  }
+}
+
+resource "aws_security_group" "sg" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+`
+	// Locals in another file of the module are resolved too
+	localsContent := `locals {
+  team = upper(local.env)
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(mainContent), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "locals.tf"), []byte(localsContent), 0o644))
+
+	engine := New(nil)
+	data, err := engine.parseModuleToJSON([]string{
+		filepath.Join(tmpDir, "main.tf"),
+		filepath.Join(tmpDir, "locals.tf"),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, InputSchemaVersion, data["_schema_version"])
+
+	resources := data["resources"].([]any)
+	require.Len(t, resources, 2)
+
+	bucket := resources[0].(map[string]any)
+	assert.Equal(t, "prod-app-logs", bucket["bucket"])
+	assert.Equal(t, "public-read", bucket["acl"])
+	assert.Equal(t, true, bucket["force"])
+	assert.Equal(t, float64(2), bucket["count"])
+	assert.Equal(t, `{"Version":"2012-10-17"}`, bucket["policy"])
+
+	arn := bucket["arn"].(map[string]any)
+	assert.Equal(t, "aws_kms_key.main.arn", arn["_expression"])
+	assert.Equal(t, []any{"aws_kms_key.main.arn"}, arn["_references"])
+
+	tags := bucket["tags"].(map[string]any)
+	assert.Equal(t, "PROD", tags["Name"])
+	assert.Equal(t, []any{"var.owner"}, tags["Owner"].(map[string]any)["_references"])
+
+	// Every node carries its range
+	assert.Equal(t, 7, bucket["_range"].(map[string]any)["start_line"])
+	assert.Equal(t, 9, bucket["_ranges"].(map[string]any)["acl"].(map[string]any)["start_line"])
+
+	// Nested blocks are always lists
+	sg := resources[1].(map[string]any)
+	ingress := sg["ingress"].([]any)
+	require.Len(t, ingress, 1)
+	rule := ingress[0].(map[string]any)
+	assert.Equal(t, float64(22), rule["from_port"])
+	assert.Equal(t, []any{"0.0.0.0/0"}, rule["cidr_blocks"])
+	assert.Equal(t, filepath.Join(tmpDir, "main.tf"), rule["_file"])
+}
+
+func TestParseModuleToJSON_RepeatedNestedBlocks(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "main.tf")
+	content := `resource "aws_security_group" "sg" {
+  ingress {
+    from_port = 80
+  }
+  ingress {
+    from_port = 443
+  }
+}
+
+provider "aws" {
+  assume_role {
+    role_arn = "arn:aws:iam::123456789012:role/deploy"
+  }
+}
+`
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0o644))
+
+	data, err := New(nil).parseModuleToJSON([]string{tmpFile})
+	require.NoError(t, err)
+
+	sg := data["resources"].([]any)[0].(map[string]any)
+	assert.Len(t, sg["ingress"].([]any), 2)
+
+	provider := data["providers"].([]any)[0].(map[string]any)
+	assert.Len(t, provider["assume_role"].([]any), 1)
+}
+
+func TestBuiltinPolicies_TypedInput(t *testing.T) {
+	tmpDir := t.TempDir()
+	tmpFile := filepath.Join(tmpDir, "main.tf")
+	content := `resource "aws_s3_bucket" "public" {
+  acl = "public-read"
+}
+
+resource "aws_db_instance" "db" {
+  publicly_accessible = true
+}
+
+resource "aws_security_group" "ssh" {
+  ingress {
+    from_port   = 0
+    to_port     = 65535
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+module "vpc" {
+  source = "./modules/vpc"
+}
+`
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0o644))
+
+	findings, err := New(nil).Run(context.Background(), []string{tmpFile})
+	require.NoError(t, err)
+
+	rules := make(map[string]bool)
+	for _, f := range findings {
+		rules[f.Rule] = true
+	}
+	assert.True(t, rules["policy.no-public-s3"], "findings: %+v", findings)
+	assert.True(t, rules["policy.no-public-rds"], "findings: %+v", findings)
+	assert.True(t, rules["policy.no-public-ssh"], "findings: %+v", findings)
+	assert.False(t, rules["policy.module-version"], "local modules need no version")
+}
+
+func TestTraversalString(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"var.name", "var.name"},
+		{"aws_instance.web[0].id", "aws_instance.web[0].id"},
+		{`local.map["key"]`, `local.map["key"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			file := parseTestExpr(t, tt.expr)
+			vars := file.Variables()
+			require.Len(t, vars, 1)
+			assert.Equal(t, tt.want, traversalString(vars[0]))
+		})
+	}
+}
+
+func parseTestExpr(t *testing.T, src string) hclsyntax.Expression {
+	t.Helper()
+	expr, diags := hclsyntax.ParseExpression([]byte(src), "test.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	return expr
+}
@@ -0,0 +1,217 @@
+package policy
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+)
+
+// library holds the built-in policy library: one module per provider and
+// category, e.g. library/aws/encryption.rego in package
+// terratidy.library.aws.encryption. lib.rego holds helpers shared by all of
+// them.
+//
+//go:embed library
+var library embed.FS
+
+const (
+	libraryDir     = "library"
+	libraryPackage = "terratidy.library."
+	// LibraryAll selects the whole policy library.
+	LibraryAll = "all"
+)
+
+// libraryModule is a policy of the library with the IDs of its rules.
+type libraryModule struct {
+	policyModule
+	group string   // provider/category, e.g. aws/encryption; empty for helpers
+	ids   []string // custom.id of each rule
+}
+
+// LibraryRule is a rule of the built-in policy library.
+type LibraryRule struct {
+	RuleMetadata
+	Group   string // provider/category, e.g. aws/encryption
+	Enabled bool   // Selected by Config.Library or enabled in Config.Rules
+}
+
+// libraryModules reads the embedded library in path order.
+func libraryModules() ([]libraryModule, error) {
+	var modules []libraryModule
+	err := fs.WalkDir(library, libraryDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(p, ".rego") {
+			return nil
+		}
+		content, err := library.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		m := libraryModule{policyModule: policyModule{path: p, content: string(content)}}
+		rel := strings.TrimSuffix(strings.TrimPrefix(p, libraryDir+"/"), ".rego")
+		if strings.Contains(rel, "/") {
+			m.group = rel
+		}
+
+		module, err := ast.ParseModuleWithOpts(p, m.content, ast.ParserOptions{ProcessAnnotation: true})
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", p, err)
+		}
+		for _, a := range module.Annotations {
+			if id, ok := a.Custom["id"].(string); ok && a.Scope == "rule" {
+				m.ids = append(m.ids, id)
+			}
+		}
+
+		modules = append(modules, m)
+		return nil
+	})
+	return modules, err
+}
+
+// librarySelected reports whether a library group matches one of the
+// selectors: all, a provider such as aws, or a group such as aws/encryption.
+func librarySelected(group string, selectors []string) bool {
+	provider, _, _ := strings.Cut(group, "/")
+	for _, s := range selectors {
+		if s == LibraryAll || s == provider || s == group {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLibrarySelectors returns an error for selectors that match no group.
+func checkLibrarySelectors(modules []libraryModule, selectors []string) error {
+	for _, s := range selectors {
+		found := s == LibraryAll
+		for _, m := range modules {
+			if m.group != "" && librarySelected(m.group, []string{s}) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown policy library selector %q", s)
+		}
+	}
+	return nil
+}
+
+// ruleConfig returns the configuration of a rule, configured by its finding
+// name (policy.<id>) or by its bare ID.
+func (e *Engine) ruleConfig(rule string) (RuleConfig, bool) {
+	id := strings.TrimPrefix(rule, "policy.")
+	if cfg, ok := e.config.Rules["policy."+id]; ok {
+		return cfg, true
+	}
+	cfg, ok := e.config.Rules[id]
+	return cfg, ok
+}
+
+// libraryEnabled reports whether a library module is loaded: its group is
+// selected by Config.Library or one of its rules is enabled in Config.Rules.
+func (e *Engine) libraryEnabled(m libraryModule) bool {
+	if librarySelected(m.group, e.config.Library) {
+		return true
+	}
+	for _, id := range m.ids {
+		if cfg, ok := e.ruleConfig(id); ok && cfg.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// libraryPolicies returns the library modules to load, with the shared
+// helpers when any is selected. The rules of modules loaded for a single
+// enabled rule, not for their group, are recorded in unselectedRules so that
+// their findings are dropped.
+func (e *Engine) libraryPolicies() ([]policyModule, error) {
+	e.unselectedRules = make(map[string]bool)
+
+	modules, err := libraryModules()
+	if err != nil {
+		return nil, fmt.Errorf("reading policy library: %w", err)
+	}
+	if err := checkLibrarySelectors(modules, e.config.Library); err != nil {
+		return nil, err
+	}
+
+	var selected, helpers []policyModule
+	for _, m := range modules {
+		switch {
+		case m.group == "":
+			helpers = append(helpers, m.policyModule)
+		case e.libraryEnabled(m):
+			selected = append(selected, m.policyModule)
+			if librarySelected(m.group, e.config.Library) {
+				continue
+			}
+			for _, id := range m.ids {
+				if cfg, ok := e.ruleConfig(id); !ok || !cfg.Enabled {
+					e.unselectedRules["policy."+id] = true
+				}
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	return append(helpers, selected...), nil
+}
+
+// LibraryRules returns every rule of the built-in policy library, sorted by
+// group and ID, with whether the configuration enables it and its configured
+// severity.
+func (e *Engine) LibraryRules(ctx context.Context) ([]LibraryRule, error) {
+	modules, err := libraryModules()
+	if err != nil {
+		return nil, fmt.Errorf("reading policy library: %w", err)
+	}
+
+	policies := make([]policyModule, len(modules))
+	enabled := make(map[string]bool)
+	for i, m := range modules {
+		policies[i] = m.policyModule
+		enabled[m.group] = m.group != "" && e.libraryEnabled(m)
+	}
+
+	set, err := New(&Config{}).preparePolicies(ctx, policies)
+	if err != nil {
+		return nil, err
+	}
+	if len(set.errors) > 0 {
+		return nil, fmt.Errorf("compiling policy library: %s", set.errors[0].Message)
+	}
+
+	var rules []LibraryRule
+	for _, pq := range set.queries {
+		for _, meta := range pq.rules {
+			group := strings.ReplaceAll(strings.TrimPrefix(meta.Package, libraryPackage), ".", "/")
+			rule := LibraryRule{RuleMetadata: meta, Group: group, Enabled: enabled[group]}
+			if cfg, ok := e.ruleConfig(meta.ID); ok {
+				rule.Enabled = cfg.Enabled
+				if cfg.Severity != "" {
+					rule.Severity = parseSeverity(cfg.Severity)
+				}
+			}
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Group != rules[j].Group {
+			return rules[i].Group < rules[j].Group
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
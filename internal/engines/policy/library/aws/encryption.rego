@@ -0,0 +1,63 @@
+# METADATA
+# title: AWS encryption at rest
+# description: AWS storage services must encrypt data at rest.
+package terratidy.library.aws.encryption
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: S3 bucket encryption
+# description: >-
+#   S3 buckets must configure server-side encryption, inline or with an
+#   aws_s3_bucket_server_side_encryption_configuration resource.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/serv-side-encryption.html
+# custom:
+#   id: aws-s3-encryption
+deny contains msg if {
+	some bucket in lib.resources("aws_s3_bucket")
+	count(object.get(bucket, "server_side_encryption_configuration", [])) == 0
+	not lib.referenced(bucket, "aws_s3_bucket_server_side_encryption_configuration", "bucket")
+	msg := lib.violation(
+		"aws-s3-encryption",
+		sprintf("S3 bucket %s does not configure server-side encryption", [bucket.name]),
+		bucket,
+	)
+}
+
+# METADATA
+# title: EBS volume encryption
+# description: EBS volumes must set encrypted = true.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/ebs/latest/userguide/ebs-encryption.html
+# custom:
+#   id: aws-ebs-encryption
+deny contains msg if {
+	some volume in lib.resources("aws_ebs_volume")
+	lib.disabled(volume, "encrypted")
+	msg := lib.violation(
+		"aws-ebs-encryption",
+		sprintf("EBS volume %s is not encrypted", [volume.name]),
+		volume,
+	)
+}
+
+# METADATA
+# title: RDS storage encryption
+# description: RDS instances and Aurora clusters must set storage_encrypted = true.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/Overview.Encryption.html
+# custom:
+#   id: aws-rds-encryption
+deny contains msg if {
+	some type in ["aws_db_instance", "aws_rds_cluster"]
+	some db in lib.resources(type)
+	lib.disabled(db, "storage_encrypted")
+	msg := lib.violation(
+		"aws-rds-encryption",
+		sprintf("%s %s does not encrypt its storage", [type, db.name]),
+		db,
+	)
+}
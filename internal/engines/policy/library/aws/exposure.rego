@@ -0,0 +1,107 @@
+# METADATA
+# title: AWS public exposure
+# description: AWS resources must not be reachable from or readable by the internet.
+package terratidy.library.aws.exposure
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: No open security group ingress
+# description: >-
+#   Security groups must not allow ingress from 0.0.0.0/0 or ::/0 on ports
+#   other than HTTP and HTTPS.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/vpc/latest/userguide/security-group-rules.html
+# custom:
+#   id: aws-sg-open-ingress
+deny contains msg if {
+	some sg in lib.resources("aws_security_group")
+	some rule in sg.ingress
+	open_ingress(rule)
+	msg := lib.violation(
+		"aws-sg-open-ingress",
+		sprintf("Security group %s allows ingress from the internet on ports %v-%v", [sg.name, rule.from_port, rule.to_port]),
+		sg,
+	)
+}
+
+# METADATA
+# custom:
+#   id: aws-sg-open-ingress
+deny contains msg if {
+	some rule in lib.resources("aws_vpc_security_group_ingress_rule")
+	some cidr in [object.get(rule, "cidr_ipv4", ""), object.get(rule, "cidr_ipv6", "")]
+	lib.open_cidr(cidr)
+	not lib.web_ports(object.get(rule, "from_port", -1), object.get(rule, "to_port", -1))
+	msg := lib.violation(
+		"aws-sg-open-ingress",
+		sprintf("Security group ingress rule %s allows ingress from the internet", [rule.name]),
+		rule,
+	)
+}
+
+open_ingress(rule) if {
+	some cidr in array.concat(object.get(rule, "cidr_blocks", []), object.get(rule, "ipv6_cidr_blocks", []))
+	lib.open_cidr(cidr)
+	not lib.web_ports(rule.from_port, rule.to_port)
+}
+
+# METADATA
+# title: S3 public access block
+# description: >-
+#   aws_s3_bucket_public_access_block resources must enable all four
+#   settings.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html
+# custom:
+#   id: aws-s3-public-access-block
+deny contains msg if {
+	some block in lib.resources("aws_s3_bucket_public_access_block")
+	disabled := [setting |
+		some setting in ["block_public_acls", "block_public_policy", "ignore_public_acls", "restrict_public_buckets"]
+		lib.disabled(block, setting)
+	]
+	count(disabled) > 0
+	msg := lib.violation(
+		"aws-s3-public-access-block",
+		sprintf("S3 public access block %s does not enable %s", [block.name, concat(", ", disabled)]),
+		block,
+	)
+}
+
+# METADATA
+# title: No public S3 ACLs
+# description: S3 buckets must not use the public-read or public-read-write canned ACLs.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/acl-overview.html#canned-acl
+# custom:
+#   id: aws-s3-public-acl
+deny contains msg if {
+	some type in ["aws_s3_bucket", "aws_s3_bucket_acl"]
+	some r in lib.resources(type)
+	r.acl in {"public-read", "public-read-write"}
+	msg := lib.violation(
+		"aws-s3-public-acl",
+		sprintf("%s %s uses the %s ACL", [type, r.name, r.acl]),
+		r,
+	)
+}
+
+# METADATA
+# title: No public RDS instances
+# description: RDS instances must not be publicly accessible.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_VPC.WorkingWithRDSInstanceinaVPC.html
+# custom:
+#   id: aws-rds-public
+deny contains msg if {
+	some db in lib.resources("aws_db_instance")
+	db.publicly_accessible == true
+	msg := lib.violation(
+		"aws-rds-public",
+		sprintf("RDS instance %s is publicly accessible", [db.name]),
+		db,
+	)
+}
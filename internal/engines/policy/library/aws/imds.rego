@@ -0,0 +1,39 @@
+# METADATA
+# title: AWS instance metadata service
+# description: EC2 instances must require IMDSv2 session tokens.
+package terratidy.library.aws.imds
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: IMDSv2 required
+# description: >-
+#   EC2 instances and launch templates must set metadata_options with
+#   http_tokens = "required", so that credentials cannot be read through
+#   IMDSv1 request forgery.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AWSEC2/latest/UserGuide/configuring-IMDS-new-instances.html
+# custom:
+#   id: aws-ec2-imdsv2
+deny contains msg if {
+	some type in ["aws_instance", "aws_launch_template"]
+	some r in lib.resources(type)
+	not imdsv2_required(r)
+	msg := lib.violation(
+		"aws-ec2-imdsv2",
+		sprintf("%s %s does not require IMDSv2 (metadata_options.http_tokens)", [type, r.name]),
+		r,
+	)
+}
+
+imdsv2_required(r) if {
+	some options in r.metadata_options
+	options.http_tokens == "required"
+}
+
+imdsv2_required(r) if {
+	some options in r.metadata_options
+	lib.unresolved(options.http_tokens)
+}
@@ -0,0 +1,85 @@
+# METADATA
+# title: AWS logging
+# description: AWS resources should record access and API activity.
+package terratidy.library.aws.logging
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: S3 access logging
+# description: >-
+#   S3 buckets should log server access, inline or with an
+#   aws_s3_bucket_logging resource.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/ServerLogs.html
+# custom:
+#   id: aws-s3-access-logging
+warn contains msg if {
+	some bucket in lib.resources("aws_s3_bucket")
+	count(object.get(bucket, "logging", [])) == 0
+	not lib.referenced(bucket, "aws_s3_bucket_logging", "bucket")
+	msg := lib.violation(
+		"aws-s3-access-logging",
+		sprintf("S3 bucket %s does not log server access", [bucket.name]),
+		bucket,
+	)
+}
+
+# METADATA
+# title: Multi-region CloudTrail
+# description: CloudTrail trails should record events in all regions.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/receive-cloudtrail-log-files-from-multiple-regions.html
+# custom:
+#   id: aws-cloudtrail-multi-region
+warn contains msg if {
+	some trail in lib.resources("aws_cloudtrail")
+	lib.disabled(trail, "is_multi_region_trail")
+	msg := lib.violation(
+		"aws-cloudtrail-multi-region",
+		sprintf("CloudTrail %s is not multi-region", [trail.name]),
+		trail,
+	)
+}
+
+# METADATA
+# title: CloudTrail log file validation
+# description: CloudTrail trails should validate log file integrity.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/awscloudtrail/latest/userguide/cloudtrail-log-file-validation-intro.html
+# custom:
+#   id: aws-cloudtrail-log-validation
+warn contains msg if {
+	some trail in lib.resources("aws_cloudtrail")
+	lib.disabled(trail, "enable_log_file_validation")
+	msg := lib.violation(
+		"aws-cloudtrail-log-validation",
+		sprintf("CloudTrail %s does not validate log files", [trail.name]),
+		trail,
+	)
+}
+
+# METADATA
+# title: Load balancer access logs
+# description: Application and network load balancers should enable access logs.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/elasticloadbalancing/latest/application/enable-access-logging.html
+# custom:
+#   id: aws-lb-access-logs
+warn contains msg if {
+	some type in ["aws_lb", "aws_alb"]
+	some lb in lib.resources(type)
+	not access_logs_enabled(lb)
+	msg := lib.violation(
+		"aws-lb-access-logs",
+		sprintf("Load balancer %s does not enable access logs", [lb.name]),
+		lb,
+	)
+}
+
+access_logs_enabled(lb) if {
+	some logs in lb.access_logs
+	not logs.enabled == false
+}
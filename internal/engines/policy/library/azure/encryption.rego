@@ -0,0 +1,61 @@
+# METADATA
+# title: Azure encryption
+# description: Azure resources must encrypt data at rest and in transit.
+package terratidy.library.azure.encryption
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: VM encryption at host
+# description: Virtual machines must enable encryption at host.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/virtual-machines/disk-encryption#encryption-at-host---end-to-end-encryption-for-your-vm-data
+# custom:
+#   id: azure-vm-encryption-at-host
+deny contains msg if {
+	some type in ["azurerm_linux_virtual_machine", "azurerm_windows_virtual_machine"]
+	some vm in lib.resources(type)
+	lib.disabled(vm, "encryption_at_host_enabled")
+	msg := lib.violation(
+		"azure-vm-encryption-at-host",
+		sprintf("Virtual machine %s does not enable encryption at host", [vm.name]),
+		vm,
+	)
+}
+
+# METADATA
+# title: Storage account minimum TLS
+# description: Storage accounts must not accept TLS versions older than 1.2.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/storage/common/transport-layer-security-configure-minimum-version
+# custom:
+#   id: azure-storage-min-tls
+deny contains msg if {
+	some account in lib.resources("azurerm_storage_account")
+	account.min_tls_version in {"TLS1_0", "TLS1_1"}
+	msg := lib.violation(
+		"azure-storage-min-tls",
+		sprintf("Storage account %s accepts %s", [account.name, account.min_tls_version]),
+		account,
+	)
+}
+
+# METADATA
+# title: Storage account HTTPS only
+# description: Storage accounts must not accept plain HTTP traffic.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/storage/common/storage-require-secure-transfer
+# custom:
+#   id: azure-storage-https
+deny contains msg if {
+	some account in lib.resources("azurerm_storage_account")
+	some attr in ["https_traffic_only_enabled", "enable_https_traffic_only"]
+	account[attr] == false
+	msg := lib.violation(
+		"azure-storage-https",
+		sprintf("Storage account %s allows HTTP traffic", [account.name]),
+		account,
+	)
+}
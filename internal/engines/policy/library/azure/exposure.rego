@@ -0,0 +1,83 @@
+# METADATA
+# title: Azure public exposure
+# description: Azure resources must not be reachable from or readable by the internet.
+package terratidy.library.azure.exposure
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: No public storage
+# description: >-
+#   Storage accounts must not allow public blob access and containers must
+#   be private.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/storage/blobs/anonymous-read-access-prevent
+# custom:
+#   id: azure-storage-public
+deny contains msg if {
+	some account in lib.resources("azurerm_storage_account")
+	some attr in ["allow_nested_items_to_be_public", "allow_blob_public_access"]
+	account[attr] == true
+	msg := lib.violation(
+		"azure-storage-public",
+		sprintf("Storage account %s allows public blob access", [account.name]),
+		account,
+	)
+}
+
+# METADATA
+# custom:
+#   id: azure-storage-public
+deny contains msg if {
+	some container in lib.resources("azurerm_storage_container")
+	container.container_access_type in {"blob", "container"}
+	msg := lib.violation(
+		"azure-storage-public",
+		sprintf("Storage container %s has %s access", [container.name, container.container_access_type]),
+		container,
+	)
+}
+
+# METADATA
+# title: No open NSG ingress
+# description: >-
+#   Network security rules must not allow inbound traffic from the internet
+#   on ports other than HTTP and HTTPS.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/virtual-network/network-security-groups-overview
+# custom:
+#   id: azure-nsg-open-ingress
+deny contains msg if {
+	some nsg in lib.resources("azurerm_network_security_group")
+	some rule in nsg.security_rule
+	open_inbound(rule)
+	msg := lib.violation(
+		"azure-nsg-open-ingress",
+		sprintf("Network security group %s allows inbound traffic from the internet (%s)", [nsg.name, rule.name]),
+		nsg,
+	)
+}
+
+# METADATA
+# custom:
+#   id: azure-nsg-open-ingress
+deny contains msg if {
+	some rule in lib.resources("azurerm_network_security_rule")
+	open_inbound(rule)
+	msg := lib.violation(
+		"azure-nsg-open-ingress",
+		sprintf("Network security rule %s allows inbound traffic from the internet", [rule.name]),
+		rule,
+	)
+}
+
+internet_sources := {"*", "0.0.0.0/0", "::/0", "Internet", "Any"}
+
+open_inbound(rule) if {
+	rule.direction == "Inbound"
+	rule.access == "Allow"
+	rule.source_address_prefix in internet_sources
+	not rule.destination_port_range in {"80", "443"}
+}
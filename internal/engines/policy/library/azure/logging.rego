@@ -0,0 +1,46 @@
+# METADATA
+# title: Azure logging
+# description: Azure resources should record network and database activity.
+package terratidy.library.azure.logging
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: NSG flow logs
+# description: >-
+#   Network security groups should be referenced by an
+#   azurerm_network_watcher_flow_log resource.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/network-watcher/nsg-flow-logs-overview
+# custom:
+#   id: azure-nsg-flow-logs
+warn contains msg if {
+	some nsg in lib.resources("azurerm_network_security_group")
+	not lib.referenced(nsg, "azurerm_network_watcher_flow_log", "network_security_group_id")
+	msg := lib.violation(
+		"azure-nsg-flow-logs",
+		sprintf("Network security group %s does not have flow logs", [nsg.name]),
+		nsg,
+	)
+}
+
+# METADATA
+# title: SQL server auditing
+# description: >-
+#   SQL servers should be referenced by an
+#   azurerm_mssql_server_extended_auditing_policy resource.
+# related_resources:
+# - ref: https://learn.microsoft.com/azure/azure-sql/database/auditing-overview
+# custom:
+#   id: azure-sql-auditing
+warn contains msg if {
+	some server in lib.resources("azurerm_mssql_server")
+	not lib.referenced(server, "azurerm_mssql_server_extended_auditing_policy", "server_id")
+	msg := lib.violation(
+		"azure-sql-auditing",
+		sprintf("SQL server %s does not have an auditing policy", [server.name]),
+		server,
+	)
+}
@@ -0,0 +1,47 @@
+# METADATA
+# title: GCP encryption at rest
+# description: GCP storage should be encrypted with customer-managed keys.
+package terratidy.library.gcp.encryption
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: Cloud Storage CMEK
+# description: Cloud Storage buckets should set a default Cloud KMS key.
+# related_resources:
+# - ref: https://cloud.google.com/storage/docs/encryption/customer-managed-keys
+# custom:
+#   id: gcp-storage-cmek
+warn contains msg if {
+	some bucket in lib.resources("google_storage_bucket")
+	not kms_key(object.get(bucket, "encryption", []), "default_kms_key_name")
+	msg := lib.violation(
+		"gcp-storage-cmek",
+		sprintf("Storage bucket %s does not use a customer-managed encryption key", [bucket.name]),
+		bucket,
+	)
+}
+
+# METADATA
+# title: Persistent disk CMEK
+# description: Compute disks should be encrypted with a Cloud KMS key.
+# related_resources:
+# - ref: https://cloud.google.com/compute/docs/disks/customer-managed-encryption
+# custom:
+#   id: gcp-disk-cmek
+warn contains msg if {
+	some disk in lib.resources("google_compute_disk")
+	not kms_key(object.get(disk, "disk_encryption_key", []), "kms_key_self_link")
+	msg := lib.violation(
+		"gcp-disk-cmek",
+		sprintf("Compute disk %s does not use a customer-managed encryption key", [disk.name]),
+		disk,
+	)
+}
+
+kms_key(blocks, attr) if {
+	some block in blocks
+	block[attr]
+}
@@ -0,0 +1,110 @@
+# METADATA
+# title: GCP public exposure
+# description: GCP resources must not be reachable from or readable by the internet.
+package terratidy.library.gcp.exposure
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+public_members := {"allUsers", "allAuthenticatedUsers"}
+
+# METADATA
+# title: No public Cloud Storage buckets
+# description: Bucket IAM must not grant roles to allUsers or allAuthenticatedUsers.
+# related_resources:
+# - ref: https://cloud.google.com/storage/docs/access-control/making-data-public
+# custom:
+#   id: gcp-storage-public
+deny contains msg if {
+	some r in lib.resources("google_storage_bucket_iam_member")
+	r.member in public_members
+	msg := lib.violation(
+		"gcp-storage-public",
+		sprintf("Storage bucket IAM member %s grants access to %s", [r.name, r.member]),
+		r,
+	)
+}
+
+# METADATA
+# custom:
+#   id: gcp-storage-public
+deny contains msg if {
+	some r in lib.resources("google_storage_bucket_iam_binding")
+	some member in r.members
+	member in public_members
+	msg := lib.violation(
+		"gcp-storage-public",
+		sprintf("Storage bucket IAM binding %s grants access to %s", [r.name, member]),
+		r,
+	)
+}
+
+# METADATA
+# title: Uniform bucket-level access
+# description: >-
+#   Cloud Storage buckets must enable uniform bucket-level access so that
+#   object ACLs cannot make data public.
+# related_resources:
+# - ref: https://cloud.google.com/storage/docs/uniform-bucket-level-access
+# custom:
+#   id: gcp-storage-uniform-access
+deny contains msg if {
+	some bucket in lib.resources("google_storage_bucket")
+	lib.disabled(bucket, "uniform_bucket_level_access")
+	msg := lib.violation(
+		"gcp-storage-uniform-access",
+		sprintf("Storage bucket %s does not enable uniform bucket-level access", [bucket.name]),
+		bucket,
+	)
+}
+
+# METADATA
+# title: No open firewall ingress
+# description: >-
+#   Firewall rules must not allow ingress from 0.0.0.0/0 or ::/0 on ports
+#   other than HTTP and HTTPS.
+# related_resources:
+# - ref: https://cloud.google.com/firewall/docs/firewalls
+# custom:
+#   id: gcp-firewall-open-ingress
+deny contains msg if {
+	some fw in lib.resources("google_compute_firewall")
+	object.get(fw, "direction", "INGRESS") == "INGRESS"
+	some cidr in object.get(fw, "source_ranges", [])
+	lib.open_cidr(cidr)
+	some allow in fw.allow
+	not web_only(allow)
+	msg := lib.violation(
+		"gcp-firewall-open-ingress",
+		sprintf("Firewall %s allows ingress from %s", [fw.name, cidr]),
+		fw,
+	)
+}
+
+web_only(allow) if {
+	count(object.get(allow, "ports", [])) > 0
+	every port in allow.ports {
+		port in {"80", "443"}
+	}
+}
+
+# METADATA
+# title: No public Cloud SQL networks
+# description: Cloud SQL instances must not authorize 0.0.0.0/0.
+# related_resources:
+# - ref: https://cloud.google.com/sql/docs/mysql/authorize-networks
+# custom:
+#   id: gcp-sql-public-ip
+deny contains msg if {
+	some db in lib.resources("google_sql_database_instance")
+	some settings in db.settings
+	some ip in settings.ip_configuration
+	some network in ip.authorized_networks
+	lib.open_cidr(network.value)
+	msg := lib.violation(
+		"gcp-sql-public-ip",
+		sprintf("Cloud SQL instance %s authorizes %s", [db.name, network.value]),
+		db,
+	)
+}
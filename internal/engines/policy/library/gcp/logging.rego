@@ -0,0 +1,42 @@
+# METADATA
+# title: GCP logging
+# description: GCP resources should record network and access activity.
+package terratidy.library.gcp.logging
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+# METADATA
+# title: Subnet flow logs
+# description: VPC subnetworks should enable flow logs with a log_config block.
+# related_resources:
+# - ref: https://cloud.google.com/vpc/docs/using-flow-logs
+# custom:
+#   id: gcp-subnet-flow-logs
+warn contains msg if {
+	some subnet in lib.resources("google_compute_subnetwork")
+	count(object.get(subnet, "log_config", [])) == 0
+	msg := lib.violation(
+		"gcp-subnet-flow-logs",
+		sprintf("Subnetwork %s does not enable flow logs", [subnet.name]),
+		subnet,
+	)
+}
+
+# METADATA
+# title: Cloud Storage access logging
+# description: Cloud Storage buckets should write usage logs with a logging block.
+# related_resources:
+# - ref: https://cloud.google.com/storage/docs/access-logs
+# custom:
+#   id: gcp-storage-access-logging
+warn contains msg if {
+	some bucket in lib.resources("google_storage_bucket")
+	count(object.get(bucket, "logging", [])) == 0
+	msg := lib.violation(
+		"gcp-storage-access-logging",
+		sprintf("Storage bucket %s does not log access", [bucket.name]),
+		bucket,
+	)
+}
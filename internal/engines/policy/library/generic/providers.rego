@@ -0,0 +1,50 @@
+# METADATA
+# title: Provider requirements
+# description: Providers must be declared and pinned in required_providers.
+package terratidy.library.generic.providers
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+requirements := [{"name": name, "requirement": req, "block": block} |
+	some block in object.get(input.terraform, "required_providers", [])
+	some name, req in block
+	not startswith(name, "_")
+]
+
+# METADATA
+# title: Provider version constraint
+# description: Every entry of required_providers must set a version constraint.
+# related_resources:
+# - ref: https://developer.hashicorp.com/terraform/language/providers/requirements#version-constraints
+# custom:
+#   id: generic-provider-version
+warn contains msg if {
+	some p in requirements
+	is_object(p.requirement)
+	not lib.unresolved(p.requirement)
+	not p.requirement.version
+	msg := lib.violation(
+		"generic-provider-version",
+		sprintf("Provider %s has no version constraint in required_providers", [p.name]),
+		p.block,
+	)
+}
+
+# METADATA
+# title: Provider declared
+# description: Configured providers must be declared in required_providers.
+# related_resources:
+# - ref: https://developer.hashicorp.com/terraform/language/providers/requirements
+# custom:
+#   id: generic-provider-declared
+warn contains msg if {
+	some provider in input.providers
+	not provider.name in {p.name | some p in requirements}
+	msg := lib.violation(
+		"generic-provider-declared",
+		sprintf("Provider %s is not declared in required_providers", [provider.name]),
+		provider,
+	)
+}
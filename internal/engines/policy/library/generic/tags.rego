@@ -0,0 +1,51 @@
+# METADATA
+# title: Resource tags
+# description: Resources must carry the tags the organization relies on.
+package terratidy.library.generic.tags
+
+import rego.v1
+
+import data.terratidy.library.lib
+
+default_required := ["Owner", "Environment"]
+
+default options := {}
+
+options := data.rule_options["generic-required-tags"]
+
+required := object.get(options, "tags", default_required)
+
+# METADATA
+# title: Required tags
+# description: >-
+#   Resources that set tags must include every required tag (Owner and
+#   Environment unless the tags option lists others). Tags from the AWS
+#   provider default_tags count as present; tags that cannot be evaluated
+#   statically are not checked.
+# custom:
+#   id: generic-required-tags
+warn contains msg if {
+	some r in input.resources
+	tags := r.tags
+	is_object(tags)
+	not lib.unresolved(tags)
+	missing := [key |
+		some key in required
+		not key in object.keys(tags)
+		not key in default_tags(r)
+	]
+	count(missing) > 0
+	msg := lib.violation(
+		"generic-required-tags",
+		sprintf("%s.%s is missing required tags: %s", [r.type, r.name, concat(", ", missing)]),
+		r,
+	)
+}
+
+default_tags(r) := {key |
+	startswith(r.type, "aws_")
+	some provider in input.providers
+	provider.name == "aws"
+	some block in provider.default_tags
+	some key, _ in block.tags
+}
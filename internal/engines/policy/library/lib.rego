@@ -0,0 +1,46 @@
+# METADATA
+# description: Helpers shared by the policies of the built-in library.
+package terratidy.library.lib
+
+import rego.v1
+
+# violation is the finding of a library rule for a block.
+violation(rule, msg, block) := {
+	"msg": msg,
+	"rule": rule,
+	"file": block._file,
+	"line": block._range.start_line,
+}
+
+# resources returns the resources of a type.
+resources(type) := [r | some r in input.resources; r.type == type]
+
+# disabled holds when an attribute is absent or false. Expressions that cannot
+# be evaluated statically are given the benefit of the doubt.
+disabled(block, attr) if not block[attr]
+
+# unresolved holds for an expression that could not be evaluated statically.
+unresolved(value) if value._expression
+
+# referenced holds when a resource of type refers to block through attr, by
+# reference or by the same literal value (e.g. a bucket name).
+referenced(block, type, attr) if {
+	some r in resources(type)
+	some ref in r[attr]._references
+	startswith(ref, sprintf("%s.%s.", [block.type, block.name]))
+}
+
+referenced(block, type, attr) if {
+	some r in resources(type)
+	is_string(r[attr])
+	r[attr] == block[attr]
+}
+
+# open_cidr holds for CIDR ranges that match the whole internet.
+open_cidr(cidr) if cidr in {"0.0.0.0/0", "::/0"}
+
+# web_ports holds when a port range only covers HTTP or HTTPS.
+web_ports(from, to) if {
+	from == to
+	from in {80, 443}
+}
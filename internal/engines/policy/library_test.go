@@ -0,0 +1,365 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// insecureModule violates every rule of the library once.
+const insecureModule = `terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+provider "aws" {}
+provider "google" {}
+
+resource "aws_s3_bucket" "data" {
+  acl  = "public-read"
+  tags = { Owner = "team" }
+}
+
+resource "aws_s3_bucket_public_access_block" "data" {
+  bucket            = aws_s3_bucket.data.id
+  block_public_acls = true
+}
+
+resource "aws_ebs_volume" "data" {
+  size = 10
+}
+
+resource "aws_db_instance" "db" {
+  publicly_accessible = true
+}
+
+resource "aws_instance" "web" {
+  metadata_options {
+    http_tokens = "optional"
+  }
+}
+
+resource "aws_security_group" "ssh" {
+  ingress {
+    from_port   = 22
+    to_port     = 22
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_cloudtrail" "main" {}
+
+resource "aws_lb" "web" {}
+
+resource "google_storage_bucket" "data" {
+  uniform_bucket_level_access = false
+}
+
+resource "google_storage_bucket_iam_member" "public" {
+  member = "allUsers"
+}
+
+resource "google_compute_disk" "data" {}
+
+resource "google_compute_firewall" "ssh" {
+  source_ranges = ["0.0.0.0/0"]
+  allow {
+    protocol = "tcp"
+    ports    = ["22"]
+  }
+}
+
+resource "google_sql_database_instance" "db" {
+  settings {
+    ip_configuration {
+      authorized_networks {
+        value = "0.0.0.0/0"
+      }
+    }
+  }
+}
+
+resource "google_compute_subnetwork" "main" {}
+
+resource "azurerm_linux_virtual_machine" "vm" {}
+
+resource "azurerm_storage_account" "data" {
+  min_tls_version                 = "TLS1_0"
+  https_traffic_only_enabled      = false
+  allow_nested_items_to_be_public = true
+}
+
+resource "azurerm_network_security_group" "main" {
+  security_rule {
+    name                   = "ssh"
+    direction              = "Inbound"
+    access                 = "Allow"
+    source_address_prefix  = "*"
+    destination_port_range = "22"
+  }
+}
+
+resource "azurerm_mssql_server" "db" {}
+`
+
+// secureModule satisfies every rule of the library.
+const secureModule = `terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+
+provider "aws" {
+  default_tags {
+    tags = { Environment = "prod" }
+  }
+}
+
+resource "aws_s3_bucket" "data" {
+  tags = { Owner = "team" }
+}
+
+resource "aws_s3_bucket_server_side_encryption_configuration" "data" {
+  bucket = aws_s3_bucket.data.id
+}
+
+resource "aws_s3_bucket_logging" "data" {
+  bucket = aws_s3_bucket.data.id
+}
+
+resource "aws_s3_bucket_public_access_block" "data" {
+  bucket                  = aws_s3_bucket.data.id
+  block_public_acls       = true
+  block_public_policy     = true
+  ignore_public_acls      = true
+  restrict_public_buckets = true
+}
+
+resource "aws_instance" "web" {
+  metadata_options {
+    http_tokens = "required"
+  }
+}
+
+resource "aws_security_group" "web" {
+  ingress {
+    from_port   = 443
+    to_port     = 443
+    cidr_blocks = ["0.0.0.0/0"]
+  }
+}
+
+resource "aws_cloudtrail" "main" {
+  is_multi_region_trail      = true
+  enable_log_file_validation = var.validate
+}
+
+resource "azurerm_network_security_group" "main" {}
+
+resource "azurerm_network_watcher_flow_log" "main" {
+  network_security_group_id = azurerm_network_security_group.main.id
+}
+`
+
+// runLibrary runs the engine with cfg against a module and returns the rule
+// names of its findings, sorted.
+func runLibrary(t *testing.T, cfg *Config, module string) []string {
+	t.Helper()
+	findings := runLibraryFindings(t, cfg, module)
+	rules := make([]string, 0, len(findings))
+	for _, f := range findings {
+		rules = append(rules, f.Rule)
+	}
+	sort.Strings(rules)
+	return rules
+}
+
+func runLibraryFindings(t *testing.T, cfg *Config, module string) []sdk.Finding {
+	t.Helper()
+	tfFile := filepath.Join(t.TempDir(), "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(module), 0o644))
+
+	// Library rules only, see TestLibrary_AlongsideCustomPolicies for both
+	cfg.NoBuiltin = true
+	findings, err := New(cfg).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+	for _, f := range findings {
+		require.NotEqual(t, "policy.eval-error", f.Rule, f.Message)
+	}
+	return findings
+}
+
+func TestLibrary_AllRules(t *testing.T) {
+	rules := runLibrary(t, &Config{Library: []string{LibraryAll}}, insecureModule)
+
+	library, err := New(nil).LibraryRules(context.Background())
+	require.NoError(t, err)
+	for _, rule := range library {
+		assert.Contains(t, rules, rule.ID)
+	}
+
+	assert.Empty(t, runLibrary(t, &Config{Library: []string{LibraryAll}}, secureModule))
+}
+
+func TestLibrary_Selectors(t *testing.T) {
+	assert.Equal(t, []string{"policy.aws-ec2-imdsv2"},
+		runLibrary(t, &Config{Library: []string{"aws/imds"}}, insecureModule))
+
+	for _, rule := range runLibrary(t, &Config{Library: []string{"gcp"}}, insecureModule) {
+		assert.True(t, strings.HasPrefix(rule, "policy.gcp-"), rule)
+	}
+
+	_, err := New(&Config{Library: []string{"aws/compute"}}).Run(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown policy library selector "aws/compute"`)
+}
+
+func TestLibrary_NotLoadedByDefault(t *testing.T) {
+	for _, rule := range runLibrary(t, &Config{}, insecureModule) {
+		library, err := New(nil).LibraryRules(context.Background())
+		require.NoError(t, err)
+		for _, lr := range library {
+			assert.NotEqual(t, lr.ID, rule)
+		}
+	}
+}
+
+func TestLibrary_RuleConfig(t *testing.T) {
+	// Enabling a rule loads its policy without selecting the group
+	cfg := &Config{Rules: map[string]RuleConfig{"aws-rds-public": {Enabled: true}}}
+	assert.Equal(t, []string{"policy.aws-rds-public"}, runLibrary(t, cfg, insecureModule))
+
+	// Other rules of the enabled rule's category stay off
+	cfg = &Config{Rules: map[string]RuleConfig{"aws-s3-encryption": {Enabled: true}}}
+	assert.Equal(t, []string{"policy.aws-s3-encryption"}, runLibrary(t, cfg, insecureModule))
+
+	// Disabling a rule drops its findings from a selected group
+	cfg = &Config{
+		Library: []string{"aws/exposure"},
+		Rules: map[string]RuleConfig{
+			"policy.aws-s3-public-acl":   {Enabled: false},
+			"aws-s3-public-access-block": {Enabled: false},
+		},
+	}
+	assert.Equal(t, []string{"policy.aws-rds-public", "policy.aws-sg-open-ingress"}, runLibrary(t, cfg, insecureModule))
+
+	// A configured severity replaces the policy's
+	cfg = &Config{
+		Library: []string{"aws/imds"},
+		Rules:   map[string]RuleConfig{"aws-ec2-imdsv2": {Enabled: true, Severity: "info"}},
+	}
+	findings := runLibraryFindings(t, cfg, insecureModule)
+	require.Len(t, findings, 1)
+	assert.Equal(t, sdk.SeverityInfo, findings[0].Severity)
+	assert.Equal(t, 30, findings[0].Location.Start.Line)
+}
+
+func TestLibrary_RequiredTags(t *testing.T) {
+	module := `provider "aws" {
+  default_tags {
+    tags = { Owner = "platform" }
+  }
+}
+
+resource "aws_s3_bucket" "data" {
+  tags = { Environment = "prod" }
+}
+
+resource "google_storage_bucket" "data" {
+  tags = { Environment = "prod" }
+}
+
+resource "aws_s3_bucket" "merged" {
+  tags = merge(var.tags, { Name = "merged" })
+}
+`
+	cfg := &Config{Library: []string{"generic/tags"}}
+	findings := runLibraryFindings(t, cfg, module)
+	require.Len(t, findings, 1)
+	assert.Contains(t, findings[0].Message, "google_storage_bucket.data is missing required tags: Owner")
+	assert.Equal(t, sdk.SeverityWarning, findings[0].Severity)
+
+	cfg.Rules = map[string]RuleConfig{
+		"generic-required-tags": {Enabled: true, Options: map[string]any{"tags": []string{"CostCenter"}}},
+	}
+	findings = runLibraryFindings(t, cfg, module)
+	require.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.Contains(t, f.Message, "missing required tags: CostCenter")
+	}
+}
+
+func TestLibrary_AlongsideCustomPolicies(t *testing.T) {
+	tfFile, policyDir := writeNamespacedFixture(t)
+
+	cfg := &Config{PolicyDirs: []string{policyDir}, Library: []string{"aws/logging"}}
+	findings, err := New(cfg).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	rules := make(map[string]bool)
+	for _, f := range findings {
+		rules[f.Rule] = true
+	}
+	assert.True(t, rules["policy.bucket-encryption"], "custom policy")
+	assert.True(t, rules["policy.aws-s3-access-logging"], "library policy")
+	assert.True(t, rules["policy.required-terraform-block"], "built-in policy")
+
+	// The built-in policies can be turned off
+	cfg.NoBuiltin = true
+	findings, err = New(cfg).Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	rules = make(map[string]bool)
+	for _, f := range findings {
+		rules[f.Rule] = true
+	}
+	assert.True(t, rules["policy.bucket-encryption"], "custom policy")
+	assert.True(t, rules["policy.aws-s3-access-logging"], "library policy")
+	assert.False(t, rules["policy.required-terraform-block"], "built-in policy")
+}
+
+func TestEngine_LibraryRules(t *testing.T) {
+	cfg := &Config{
+		Library: []string{"azure"},
+		Rules: map[string]RuleConfig{
+			"azure-sql-auditing": {Enabled: false},
+			"aws-ec2-imdsv2":     {Enabled: true},
+		},
+	}
+	rules, err := New(cfg).LibraryRules(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rules)
+
+	groups := make(map[string]bool)
+	for _, rule := range rules {
+		groups[rule.Group] = true
+		assert.False(t, strings.HasPrefix(rule.ID, "policy.library."), "clause without an ID: %s", rule.ID)
+		assert.NotEmpty(t, rule.Title, rule.ID)
+		assert.NotEmpty(t, rule.Description, rule.ID)
+
+		provider, _, _ := strings.Cut(rule.Group, "/")
+		want := provider == "azure" && rule.ID != "policy.azure-sql-auditing" || rule.ID == "policy.aws-ec2-imdsv2"
+		assert.Equal(t, want, rule.Enabled, rule.ID)
+	}
+	for _, group := range []string{
+		"aws/encryption", "aws/imds", "aws/exposure", "aws/logging",
+		"gcp/encryption", "gcp/exposure", "gcp/logging",
+		"azure/encryption", "azure/exposure", "azure/logging",
+		"generic/tags", "generic/providers",
+	} {
+		assert.True(t, groups[group], group)
+	}
+}
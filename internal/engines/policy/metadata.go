@@ -0,0 +1,277 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// Package conventions. Policies in package terraform (the original layout) or
+// in any package below terratidy are discovered automatically; their deny, warn
+// and info rules become entrypoints.
+const (
+	legacyPackage    = "data.terraform"
+	namespacePrefix  = "data.terratidy."
+	defaultViolation = "policy.violation"
+)
+
+// entrypointSeverities maps rule names to the severity of their violations.
+// The _repo rules are evaluated once against the repository input.
+var entrypointSeverities = map[string]sdk.Severity{
+	"deny":      sdk.SeverityError,
+	"warn":      sdk.SeverityWarning,
+	"info":      sdk.SeverityInfo,
+	"deny_repo": sdk.SeverityError,
+	"warn_repo": sdk.SeverityWarning,
+	"info_repo": sdk.SeverityInfo,
+}
+
+// repoSuffix marks the entrypoints of repository-scope policies.
+const repoSuffix = "_repo"
+
+// RuleMetadata describes a policy rule, read from its METADATA annotations.
+type RuleMetadata struct {
+	ID               string // Finding rule name, e.g. policy.no-public-s3
+	Package          string // Rego package, e.g. terratidy.security
+	Entrypoint       string // Query the rule contributes to, e.g. data.terratidy.security.deny
+	Title            string
+	Description      string
+	Severity         sdk.Severity
+	RelatedResources []string
+	File             string
+	Line             int
+}
+
+// newEntrypoint returns the entrypoint for a query such as
+// data.terratidy.security.deny. The severity follows the rule name and
+// defaults to error.
+func newEntrypoint(query string) entrypoint {
+	pkg, rule := query, ""
+	if i := strings.LastIndex(query, "."); i >= 0 {
+		pkg, rule = query[:i], query[i+1:]
+	}
+
+	severity, ok := entrypointSeverities[rule]
+	if !ok {
+		severity = sdk.SeverityError
+	}
+
+	ep := entrypoint{query: query, severity: severity, repo: strings.HasSuffix(rule, repoSuffix)}
+	if strings.HasPrefix(pkg, namespacePrefix) {
+		ep.namespace = strings.TrimPrefix(pkg, namespacePrefix)
+	}
+	return ep
+}
+
+// namespaceRule is the rule name of violations that do not set one, when the
+// entrypoint's rules do not name a single rule either.
+func (ep entrypoint) namespaceRule() string {
+	if ep.namespace == "" {
+		return defaultViolation
+	}
+	return "policy." + ep.namespace
+}
+
+// discoverEntrypoints returns the configured entrypoints, or the deny, warn and
+// info rules of every conventional package when none are configured.
+func discoverEntrypoints(compiler *ast.Compiler, configured []string) []entrypoint {
+	var entrypoints []entrypoint
+	if len(configured) > 0 {
+		for _, query := range configured {
+			entrypoints = append(entrypoints, newEntrypoint(query))
+		}
+		return entrypoints
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range sortedModuleNames(compiler) {
+		module := compiler.Modules[name]
+		pkg := module.Package.Path.String()
+		if pkg != legacyPackage && !strings.HasPrefix(pkg, namespacePrefix) {
+			continue
+		}
+		for _, rule := range module.Rules {
+			ruleName := ruleName(rule)
+			if _, ok := entrypointSeverities[ruleName]; !ok {
+				continue
+			}
+			query := pkg + "." + ruleName
+			if !seen[query] {
+				seen[query] = true
+				entrypoints = append(entrypoints, newEntrypoint(query))
+			}
+		}
+	}
+
+	sort.Slice(entrypoints, func(i, j int) bool {
+		return entrypoints[i].query < entrypoints[j].query
+	})
+	return entrypoints
+}
+
+// collectMetadata reads the metadata of every rule that contributes to an
+// entrypoint, keyed by entrypoint query and then by finding rule name. Rule IDs
+// come from custom.id or from the "rule" values the rule body produces;
+// rule-scoped annotations take precedence over package-scoped ones.
+func collectMetadata(compiler *ast.Compiler, entrypoints []entrypoint) map[string]map[string]RuleMetadata {
+	byQuery := make(map[string]entrypoint, len(entrypoints))
+	for _, ep := range entrypoints {
+		byQuery[ep.query] = ep
+	}
+
+	annotations := compiler.GetAnnotationSet()
+	metadata := make(map[string]map[string]RuleMetadata)
+
+	for _, name := range sortedModuleNames(compiler) {
+		module := compiler.Modules[name]
+		pkg := module.Package.Path.String()
+
+		for _, rule := range module.Rules {
+			ep, ok := byQuery[pkg+"."+ruleName(rule)]
+			if !ok {
+				continue
+			}
+
+			meta := RuleMetadata{
+				Package:    strings.TrimPrefix(pkg, "data."),
+				Entrypoint: ep.query,
+				Severity:   ep.severity,
+				File:       rule.Location.File,
+				Line:       rule.Location.Row,
+			}
+			ids := ruleIDs(rule)
+
+			if annotations != nil {
+				// Chain lists the most specific annotations first
+				chain := annotations.Chain(rule)
+				for i := len(chain) - 1; i >= 0; i-- {
+					applyAnnotations(&meta, chain[i].Annotations, &ids)
+				}
+			}
+			if len(ids) == 0 {
+				ids = []string{ep.namespaceRule()}
+			}
+
+			if metadata[ep.query] == nil {
+				metadata[ep.query] = make(map[string]RuleMetadata)
+			}
+			for _, id := range ids {
+				if _, exists := metadata[ep.query][id]; exists {
+					continue
+				}
+				m := meta
+				m.ID = id
+				metadata[ep.query][id] = m
+			}
+		}
+	}
+
+	return metadata
+}
+
+// applyAnnotations overlays a METADATA block onto rule metadata.
+func applyAnnotations(meta *RuleMetadata, a *ast.Annotations, ids *[]string) {
+	if a == nil {
+		return
+	}
+	if a.Title != "" {
+		meta.Title = a.Title
+	}
+	if a.Description != "" {
+		meta.Description = a.Description
+	}
+	if len(a.RelatedResources) > 0 {
+		meta.RelatedResources = nil
+		for _, r := range a.RelatedResources {
+			meta.RelatedResources = append(meta.RelatedResources, r.Ref.String())
+		}
+	}
+	if severity, ok := a.Custom["severity"].(string); ok {
+		meta.Severity = parseSeverity(severity)
+	}
+	// Only a rule-scoped id names the rule; a package-wide id would collide
+	if id, ok := a.Custom["id"].(string); ok && a.Scope == "rule" {
+		*ids = []string{"policy." + id}
+	}
+}
+
+// ruleIDs returns the finding rule names a rule body produces through
+// constant "rule" values in its violation objects.
+func ruleIDs(rule *ast.Rule) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	ast.WalkTerms(rule, func(term *ast.Term) bool {
+		obj, ok := term.Value.(ast.Object)
+		if !ok {
+			return false
+		}
+		value := obj.Get(ast.StringTerm("rule"))
+		if value == nil {
+			return false
+		}
+		if s, ok := value.Value.(ast.String); ok {
+			id := "policy." + string(s)
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return false
+	})
+	return ids
+}
+
+// ruleName returns the name of a rule such as deny, or "" for ref heads.
+func ruleName(rule *ast.Rule) string {
+	ref := rule.Head.Ref()
+	if len(ref) != 1 {
+		return ""
+	}
+	name, ok := ref[0].Value.(ast.Var)
+	if !ok {
+		return ""
+	}
+	return string(name)
+}
+
+func sortedModuleNames(compiler *ast.Compiler) []string {
+	names := make([]string, 0, len(compiler.Modules))
+	for name := range compiler.Modules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rules returns the metadata of every rule in the configured policies (or the
+// built-in policies when none are configured), sorted by ID.
+func (e *Engine) Rules(ctx context.Context) ([]RuleMetadata, error) {
+	policies, err := e.loadPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
+	}
+
+	set, err := e.preparePolicies(ctx, policies)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []RuleMetadata
+	seen := make(map[string]bool)
+	for _, pq := range set.queries {
+		for id, meta := range pq.rules {
+			if !seen[id] {
+				seen[id] = true
+				rules = append(rules, meta)
+			}
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+	return rules, nil
+}
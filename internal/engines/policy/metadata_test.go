@@ -0,0 +1,177 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const securityPolicy = `# METADATA
+# custom:
+#   severity: warning
+package terratidy.security
+
+import rego.v1
+
+# METADATA
+# title: Encrypted buckets
+# description: S3 buckets must configure server-side encryption
+# related_resources:
+# - ref: https://example.com/encryption
+# custom:
+#   id: bucket-encryption
+#   severity: error
+deny contains msg if {
+    some r in input.resources
+    r.type == "aws_s3_bucket"
+    not r.server_side_encryption_configuration
+    msg := {"msg": sprintf("bucket %s is not encrypted", [r.name]), "file": r._file}
+}
+
+info contains sprintf("found bucket %s", [r.name]) if {
+    some r in input.resources
+    r.type == "aws_s3_bucket"
+}
+`
+
+// networkPolicy also defines deny; in its own package it does not collide
+const networkPolicy = `package terratidy.network
+
+import rego.v1
+
+deny contains msg if {
+    some r in input.resources
+    r.type == "aws_security_group"
+    msg := {"msg": "no security groups", "rule": "no-security-groups"}
+}
+`
+
+// writeNamespacedFixture writes a module and two team policy files.
+func writeNamespacedFixture(t *testing.T) (tfFile, policyDir string) {
+	t.Helper()
+	dir := t.TempDir()
+	tfFile = filepath.Join(dir, "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`resource "aws_s3_bucket" "logs" {}
+
+resource "aws_security_group" "sg" {}
+`), 0o644))
+
+	policyDir = filepath.Join(dir, "policies")
+	require.NoError(t, os.MkdirAll(policyDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "security.rego"), []byte(securityPolicy), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "network.rego"), []byte(networkPolicy), 0o644))
+	return tfFile, policyDir
+}
+
+func TestEngine_NamespacedPackages(t *testing.T) {
+	tfFile, policyDir := writeNamespacedFixture(t)
+
+	engine := New(&Config{PolicyDirs: []string{policyDir}, NoBuiltin: true})
+	findings, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	byRule := make(map[string]sdk.Finding)
+	for _, f := range findings {
+		byRule[f.Rule] = f
+	}
+	require.Len(t, byRule, 3, "findings: %+v", findings)
+
+	// The rule's custom id and severity apply to its violations
+	assert.Equal(t, sdk.SeverityError, byRule["policy.bucket-encryption"].Severity)
+	// info rules without an id are named after the namespace; the package severity applies
+	assert.Equal(t, "found bucket logs", byRule["policy.security"].Message)
+	assert.Equal(t, sdk.SeverityWarning, byRule["policy.security"].Severity)
+	assert.Equal(t, sdk.SeverityError, byRule["policy.no-security-groups"].Severity)
+}
+
+func TestEngine_ConfiguredEntrypoints(t *testing.T) {
+	tfFile, policyDir := writeNamespacedFixture(t)
+
+	engine := New(&Config{
+		PolicyDirs:  []string{policyDir},
+		Entrypoints: []string{"data.terratidy.network.deny"},
+	})
+	findings, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	require.Len(t, findings, 1)
+	assert.Equal(t, "policy.no-security-groups", findings[0].Rule)
+}
+
+func TestEngine_Rules(t *testing.T) {
+	_, policyDir := writeNamespacedFixture(t)
+
+	rules, err := New(&Config{PolicyDirs: []string{policyDir}, NoBuiltin: true}).Rules(context.Background())
+	require.NoError(t, err)
+
+	ids := make([]string, len(rules))
+	for i, r := range rules {
+		ids[i] = r.ID
+	}
+	assert.Equal(t, []string{"policy.bucket-encryption", "policy.no-security-groups", "policy.security"}, ids)
+
+	encryption := rules[0]
+	assert.Equal(t, "Encrypted buckets", encryption.Title)
+	assert.Equal(t, "S3 buckets must configure server-side encryption", encryption.Description)
+	assert.Equal(t, sdk.SeverityError, encryption.Severity)
+	assert.Equal(t, []string{"https://example.com/encryption"}, encryption.RelatedResources)
+	assert.Equal(t, "terratidy.security", encryption.Package)
+	assert.Equal(t, "data.terratidy.security.deny", encryption.Entrypoint)
+	assert.Equal(t, filepath.Join(policyDir, "security.rego"), encryption.File)
+	assert.Equal(t, 16, encryption.Line)
+
+	// Package-scoped annotations apply to every rule of the package
+	assert.Equal(t, sdk.SeverityWarning, rules[2].Severity)
+}
+
+func TestEngine_Rules_Builtin(t *testing.T) {
+	rules, err := New(nil).Rules(context.Background())
+	require.NoError(t, err)
+
+	byID := make(map[string]RuleMetadata)
+	for _, r := range rules {
+		byID[r.ID] = r
+	}
+	for _, id := range []string{
+		"policy.required-terraform-block", "policy.required-version", "policy.required-providers",
+		"policy.no-public-ssh", "policy.no-public-s3", "policy.no-public-rds",
+		"policy.required-tags", "policy.module-version",
+	} {
+		require.Contains(t, byID, id)
+		assert.NotEmpty(t, byID[id].Description, id)
+	}
+	assert.Equal(t, sdk.SeverityWarning, byID["policy.required-version"].Severity)
+	assert.Equal(t, sdk.SeverityError, byID["policy.no-public-s3"].Severity)
+}
+
+func TestNewEntrypoint(t *testing.T) {
+	tests := []struct {
+		query     string
+		severity  sdk.Severity
+		namespace string
+		rule      string
+		repo      bool
+	}{
+		{"data.terraform.deny", sdk.SeverityError, "", "policy.violation", false},
+		{"data.terraform.warn", sdk.SeverityWarning, "", "policy.violation", false},
+		{"data.terratidy.team.a.info", sdk.SeverityInfo, "team.a", "policy.team.a", false},
+		{"data.myorg.violations", sdk.SeverityError, "", "policy.violation", false},
+		{"data.terratidy.layout.warn_repo", sdk.SeverityWarning, "layout", "policy.layout", true},
+		{"data.myorg.deny_repo", sdk.SeverityError, "", "policy.violation", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			ep := newEntrypoint(tt.query)
+			assert.Equal(t, tt.severity, ep.severity)
+			assert.Equal(t, tt.namespace, ep.namespace)
+			assert.Equal(t, tt.rule, ep.namespaceRule())
+			assert.Equal(t, tt.repo, ep.repo)
+		})
+	}
+}
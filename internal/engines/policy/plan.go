@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// planKeys are the parts of `terraform show -json` output exposed as input.plan.
+var planKeys = []string{
+	"format_version",
+	"terraform_version",
+	"resource_changes",
+	"planned_values",
+	"configuration",
+	"prior_state",
+	"output_changes",
+}
+
+// loadPlan reads a plan JSON file produced by `terraform show -json`.
+func loadPlan(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if _, ok := raw["format_version"]; !ok {
+		return nil, fmt.Errorf("%s is not terraform plan JSON (missing format_version)", path)
+	}
+
+	plan := map[string]any{
+		"resource_changes": []any{},
+		"planned_values":   map[string]any{},
+		"configuration":    map[string]any{},
+	}
+	for _, key := range planKeys {
+		if v, ok := raw[key]; ok {
+			plan[key] = v
+		}
+	}
+	return plan, nil
+}
+
+// planInput builds the input document for plan evaluation: the static input of
+// the root module with the plan under input.plan. It also returns the index used
+// to map plan addresses back to source.
+func (e *Engine) planInput(files []string) (map[string]any, sourceIndex, string, error) {
+	plan, err := loadPlan(e.config.PlanFile)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	rootDir := commonDir(files)
+	var rootFiles []string
+	for _, file := range files {
+		if filepath.Dir(file) == rootDir {
+			rootFiles = append(rootFiles, file)
+		}
+	}
+
+	moduleData, err := e.parseModuleToJSON(rootFiles)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	moduleData["plan"] = plan
+
+	configuration, _ := plan["configuration"].(map[string]any)
+	rootModule, _ := configuration["root_module"].(map[string]any)
+	sources := make(sourceIndex)
+	e.indexModule(sources, "", rootDir, rootModule, e.groupFilesByDirectory(files))
+
+	return moduleData, sources, rootDir, nil
+}
+
+// commonDir returns the deepest directory containing all files, or "." when
+// there are none.
+func commonDir(files []string) string {
+	if len(files) == 0 {
+		return "."
+	}
+
+	dir := filepath.Dir(files[0])
+	for _, file := range files[1:] {
+		for !isWithin(filepath.Dir(file), dir) {
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return dir
+}
+
+// isWithin reports whether path is dir or one of its descendants.
+func isWithin(path, dir string) bool {
+	if dir == "." && !filepath.IsAbs(path) && !strings.HasPrefix(path, "..") {
+		return true
+	}
+	return path == dir || strings.HasPrefix(path, dir+string(filepath.Separator))
+}
+
+// sourceIndex maps configuration addresses such as module.vpc.aws_subnet.private
+// to the range of the block that declares them.
+type sourceIndex map[string]hcl.Range
+
+// indexModule records the blocks of the module in dir under the address prefix
+// and follows the local module calls of its configuration.
+func (e *Engine) indexModule(
+	sources sourceIndex,
+	prefix, dir string,
+	module map[string]any,
+	dirFiles map[string][]string,
+) {
+	files, ok := dirFiles[dir]
+	if !ok {
+		files, _ = filepath.Glob(filepath.Join(dir, "*.tf"))
+	}
+
+	for _, path := range files {
+		mf := e.parseFile(path)
+		if mf == nil {
+			continue
+		}
+		for _, block := range mf.body.Blocks {
+			switch {
+			case block.Type == "resource" && len(block.Labels) == 2:
+				sources[prefix+block.Labels[0]+"."+block.Labels[1]] = block.Range()
+			case block.Type == "data" && len(block.Labels) == 2:
+				sources[prefix+"data."+block.Labels[0]+"."+block.Labels[1]] = block.Range()
+			case block.Type == "module" && len(block.Labels) == 1:
+				sources[prefix+"module."+block.Labels[0]] = block.Range()
+			}
+		}
+	}
+
+	calls, _ := module["module_calls"].(map[string]any)
+	for name, c := range calls {
+		call, _ := c.(map[string]any)
+		source, _ := call["source"].(string)
+		if !isLocalSource(source) {
+			continue
+		}
+		child, _ := call["module"].(map[string]any)
+		e.indexModule(sources, prefix+"module."+name+".", filepath.Join(dir, source), child, dirFiles)
+	}
+}
+
+// locate sets the file and location of a finding whose violation names a
+// resource address but no file.
+func (s sourceIndex) locate(finding *sdk.Finding, violation any) {
+	v, ok := violation.(map[string]any)
+	if !ok || len(s) == 0 {
+		return
+	}
+	if _, hasFile := v["file"]; hasFile {
+		return
+	}
+	address, ok := v["address"].(string)
+	if !ok {
+		return
+	}
+
+	rng, ok := s[configAddress(address)]
+	if !ok {
+		return
+	}
+	finding.File = rng.Filename
+	finding.Location = rng
+}
+
+// configAddress strips instance keys from a resource instance address, so that
+// module.app["a"].aws_instance.web[0] becomes module.app.aws_instance.web.
+func configAddress(address string) string {
+	var b strings.Builder
+	depth := 0
+	inString := false
+	for i := 0; i < len(address); i++ {
+		c := address[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"' && depth > 0:
+			inString = true
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
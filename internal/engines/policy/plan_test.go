@@ -0,0 +1,204 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPlanJSON = `{
+  "format_version": "1.2",
+  "terraform_version": "1.9.0",
+  "planned_values": {
+    "root_module": {
+      "resources": [
+        {"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "name": "logs", "values": {"bucket": "logs-123"}}
+      ]
+    }
+  },
+  "resource_changes": [
+    {
+      "address": "aws_s3_bucket.logs",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "change": {"actions": ["create"], "after": {"bucket": "logs-123", "acl": "public-read"}}
+    },
+    {
+      "address": "module.network[\"eu\"].aws_subnet.private[0]",
+      "module_address": "module.network[\"eu\"]",
+      "type": "aws_subnet",
+      "name": "private",
+      "index": 0,
+      "change": {"actions": ["create"], "after": {"map_public_ip_on_launch": true}}
+    }
+  ],
+  "configuration": {
+    "root_module": {
+      "resources": [{"address": "aws_s3_bucket.logs", "type": "aws_s3_bucket", "name": "logs"}],
+      "module_calls": {
+        "network": {
+          "source": "./modules/network",
+          "module": {
+            "resources": [{"address": "aws_subnet.private", "type": "aws_subnet", "name": "private"}]
+          }
+        }
+      }
+    }
+  }
+}`
+
+const testPlanPolicy = `package terraform
+
+import rego.v1
+
+deny contains msg if {
+    some rc in input.plan.resource_changes
+    rc.type == "aws_s3_bucket"
+    rc.change.after.acl == "public-read"
+    msg := {"msg": "public bucket", "rule": "plan-public-s3", "address": rc.address}
+}
+
+deny contains msg if {
+    some rc in input.plan.resource_changes
+    rc.type == "aws_subnet"
+    rc.change.after.map_public_ip_on_launch == true
+    msg := {"msg": "public subnet", "rule": "plan-public-subnet", "address": rc.address}
+}
+`
+
+// writePlanFixture writes a root module, a local child module, a plan and a policy.
+func writePlanFixture(t *testing.T) (dir, planFile, policyFile string) {
+	t.Helper()
+	dir = t.TempDir()
+
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "modules", "network"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "aws_s3_bucket" "logs" {
+  bucket = "logs-${var.suffix}"
+}
+
+module "network" {
+  source   = "./modules/network"
+  for_each = toset(["eu"])
+}
+`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "modules", "network", "main.tf"), []byte(`variable "cidr" {}
+
+resource "aws_subnet" "private" {
+  count                   = 1
+  map_public_ip_on_launch = true
+}
+`), 0o644))
+
+	planFile = filepath.Join(dir, "plan.json")
+	require.NoError(t, os.WriteFile(planFile, []byte(testPlanJSON), 0o644))
+	policyFile = filepath.Join(dir, "plan.rego")
+	require.NoError(t, os.WriteFile(policyFile, []byte(testPlanPolicy), 0o644))
+	return dir, planFile, policyFile
+}
+
+func TestEngine_RunPlan(t *testing.T) {
+	dir, planFile, policyFile := writePlanFixture(t)
+
+	engine := New(&Config{PolicyFiles: []string{policyFile}, PlanFile: planFile, NoBuiltin: true})
+	findings, err := engine.Run(context.Background(), []string{
+		filepath.Join(dir, "main.tf"),
+		filepath.Join(dir, "modules", "network", "main.tf"),
+	})
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+
+	byRule := make(map[string]int)
+	for i, f := range findings {
+		byRule[f.Rule] = i
+	}
+
+	bucket := findings[byRule["policy.plan-public-s3"]]
+	assert.Equal(t, filepath.Join(dir, "main.tf"), bucket.File)
+	assert.Equal(t, 1, bucket.Location.Start.Line)
+
+	subnet := findings[byRule["policy.plan-public-subnet"]]
+	assert.Equal(t, filepath.Join(dir, "modules", "network", "main.tf"), subnet.File)
+	assert.Equal(t, 3, subnet.Location.Start.Line)
+}
+
+func TestEngine_RunPlan_ModuleFilesNotListed(t *testing.T) {
+	dir, planFile, policyFile := writePlanFixture(t)
+
+	// Child modules are read from disk when only the root files are given
+	engine := New(&Config{PolicyFiles: []string{policyFile}, PlanFile: planFile, NoBuiltin: true})
+	findings, err := engine.Run(context.Background(), []string{filepath.Join(dir, "main.tf")})
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.NotEqual(t, dir, f.File, "finding %s should be located", f.Rule)
+	}
+}
+
+func TestEngine_RunPlan_InvalidPlan(t *testing.T) {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan.json")
+	require.NoError(t, os.WriteFile(planFile, []byte(`{"resources": []}`), 0o644))
+
+	engine := New(&Config{PlanFile: planFile})
+	_, err := engine.Run(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "format_version")
+
+	engine = New(&Config{PlanFile: filepath.Join(dir, "missing.json")})
+	_, err = engine.Run(context.Background(), nil)
+	require.Error(t, err)
+}
+
+func TestGetInput_Plan(t *testing.T) {
+	dir, planFile, _ := writePlanFixture(t)
+
+	engine := New(&Config{PlanFile: planFile})
+	data, moduleSources, rootDir, err := engine.planInput([]string{filepath.Join(dir, "main.tf")})
+	require.NoError(t, err)
+	assert.Equal(t, dir, rootDir)
+	assert.Contains(t, moduleSources, "module.network.aws_subnet.private")
+	assert.Contains(t, moduleSources, "module.network")
+
+	plan := data["plan"].(map[string]any)
+	assert.Equal(t, "1.9.0", plan["terraform_version"])
+	assert.Len(t, plan["resource_changes"], 2)
+	assert.Contains(t, plan, "planned_values")
+	assert.Contains(t, plan, "configuration")
+
+	// The static input of the root module is still available
+	assert.Len(t, data["resources"], 1)
+
+	raw, err := engine.GetInput([]string{filepath.Join(dir, "main.tf")})
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), `"resource_changes"`)
+}
+
+func TestConfigAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+	}{
+		{"aws_instance.web", "aws_instance.web"},
+		{"aws_instance.web[0]", "aws_instance.web"},
+		{`aws_instance.web["a.b"]`, "aws_instance.web"},
+		{`module.app["x]y"].data.aws_ami.ubuntu`, "module.app.data.aws_ami.ubuntu"},
+		{"module.a[0].module.b[1].aws_subnet.s[2]", "module.a.module.b.aws_subnet.s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.want, configAddress(tt.address))
+		})
+	}
+}
+
+func TestCommonDir(t *testing.T) {
+	assert.Equal(t, ".", commonDir(nil))
+	assert.Equal(t, ".", commonDir([]string{"main.tf", "modules/vpc/main.tf"}))
+	assert.Equal(t, "infra", commonDir([]string{"infra/modules/vpc/main.tf", "infra/main.tf"}))
+	assert.Equal(t, "/a", commonDir([]string{"/a/b/main.tf", "/a/c/main.tf"}))
+}
@@ -9,27 +9,44 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclparse"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/open-policy-agent/opa/v1/profiler"
 	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage"
 	"github.com/santosr2/terratidy/pkg/sdk"
 )
 
 // Engine represents the policy engine with OPA/Rego support
 type Engine struct {
-	config *Config
-	parser *hclparse.Parser
+	config  *Config
+	parser  *hclparse.Parser
+	timings map[string]time.Duration
+	bundles []*loadedBundle
+
+	unselectedRules map[string]bool // Library rules loaded only alongside an enabled rule
 }
 
 // Config holds the policy engine configuration
 type Config struct {
-	PolicyDirs  []string              // Directories containing Rego policy files
-	PolicyFiles []string              // Individual policy files
-	DataFiles   []string              // Additional data files
-	Options     map[string]any        // Additional options
-	Rules       map[string]RuleConfig // Rule-specific configuration
+	PolicyDirs  []string // Directories containing Rego policy files
+	PolicyFiles []string // Individual policy files
+	DataFiles   []string // JSON/YAML documents merged into data
+	Bundles     []string // OPA bundles: .tar.gz files, bundle directories or cached name[@revision]
+	Library     []string // Policy library selectors: all, a provider (aws) or provider/category (aws/logging)
+	NoBuiltin   bool     // Skip the built-in policies that otherwise run alongside all others
+	BundleCache string   // Cache of pulled bundles; defaults to DefaultBundleCache
+	// BundleVerification verifies bundle signatures when a public key is set
+	BundleVerification BundleVerification
+	ExceptionsFile     string                // Exceptions register (exceptions.yaml), see LoadExceptions
+	PlanFile           string                // Plan JSON from `terraform show -json`, exposed as input.plan
+	Profile            bool                  // Record per-policy evaluation time, see Engine.Timings
+	Entrypoints        []string              // Queries to evaluate; defaults to deny/warn/info of conventional packages
+	Options            map[string]any        // Additional options
+	Rules              map[string]RuleConfig // Rule-specific configuration
 }
 
 // RuleConfig holds configuration for a single policy rule
@@ -53,8 +70,9 @@ func New(config *Config) *Engine {
 	}
 
 	return &Engine{
-		config: config,
-		parser: hclparse.NewParser(),
+		config:  config,
+		parser:  hclparse.NewParser(),
+		timings: make(map[string]time.Duration),
 	}
 }
 
@@ -78,6 +96,30 @@ func (e *Engine) Run(ctx context.Context, files []string) ([]sdk.Finding, error)
 		return allFindings, nil
 	}
 
+	// Compile once; every module is evaluated with the same prepared queries
+	set, err := e.preparePolicies(ctx, policies)
+	if err != nil {
+		return nil, err
+	}
+	allFindings = append(allFindings, set.errors...)
+
+	// Repository-scope policies see every module at once
+	if set.hasRepoQueries() {
+		findings, err := e.runRepository(ctx, set, files)
+		if err != nil {
+			return nil, err
+		}
+		allFindings = append(allFindings, findings...)
+	}
+
+	if e.config.PlanFile != "" {
+		findings, err := e.runPlan(ctx, set, files)
+		if err != nil {
+			return nil, err
+		}
+		return append(allFindings, findings...), nil
+	}
+
 	// Group files by directory for module-level analysis
 	dirFiles := e.groupFilesByDirectory(files)
 
@@ -102,7 +144,7 @@ func (e *Engine) Run(ctx context.Context, files []string) ([]sdk.Finding, error)
 		}
 
 		// Evaluate policies against the module data
-		findings, err := e.evaluatePolicies(ctx, policies, moduleData, dir)
+		findings, err := e.evaluatePolicies(ctx, set, moduleData, dir, nil, false)
 		if err != nil {
 			return nil, fmt.Errorf("evaluating policies for %s: %w", dir, err)
 		}
@@ -113,12 +155,39 @@ func (e *Engine) Run(ctx context.Context, files []string) ([]sdk.Finding, error)
 	return allFindings, nil
 }
 
+// runPlan evaluates the policies once against the plan and the root module.
+// Findings that name a resource address are mapped back to its source block.
+func (e *Engine) runPlan(ctx context.Context, set *policySet, files []string) ([]sdk.Finding, error) {
+	moduleData, sources, rootDir, err := e.planInput(files)
+	if err != nil {
+		return nil, fmt.Errorf("loading plan: %w", err)
+	}
+
+	findings, err := e.evaluatePolicies(ctx, set, moduleData, rootDir, sources, false)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating policies for plan %s: %w", e.config.PlanFile, err)
+	}
+	return findings, nil
+}
+
 // loadPolicies loads all Rego policy files
-func (e *Engine) loadPolicies() ([]string, error) {
-	var policies []string
+func (e *Engine) loadPolicies() ([]policyModule, error) {
+	var policies []policyModule
+
+	// Load from bundles, including policy directories that are bundles
+	bundles, err := e.loadBundles()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bundles {
+		policies = append(policies, bundleModules(b.path, b.bundle)...)
+	}
 
 	// Load from policy directories
 	for _, dir := range e.config.PolicyDirs {
+		if isBundleDir(dir) {
+			continue
+		}
 		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
 				return err
@@ -128,7 +197,7 @@ func (e *Engine) loadPolicies() ([]string, error) {
 				if err != nil {
 					return fmt.Errorf("reading %s: %w", path, err)
 				}
-				policies = append(policies, string(content))
+				policies = append(policies, policyModule{path: path, content: string(content)})
 			}
 			return nil
 		})
@@ -146,23 +215,42 @@ func (e *Engine) loadPolicies() ([]string, error) {
 			}
 			return nil, fmt.Errorf("reading %s: %w", file, err)
 		}
-		policies = append(policies, string(content))
+		policies = append(policies, policyModule{path: file, content: string(content)})
 	}
 
-	// Add built-in policies if no custom policies provided
-	if len(policies) == 0 {
+	// Load the selected policy library alongside the custom policies
+	libraryPolicies, err := e.libraryPolicies()
+	if err != nil {
+		return nil, err
+	}
+	policies = append(policies, libraryPolicies...)
+
+	// The built-in policies run alongside the others unless turned off
+	if !e.config.NoBuiltin {
 		policies = append(policies, builtinPolicies...)
 	}
 
 	return policies, nil
 }
 
-// parseModuleToJSON parses Terraform files and converts to JSON representation for OPA
+// parseModuleToJSON parses Terraform files and converts to JSON representation for OPA.
+// See input.go for the schema.
 func (e *Engine) parseModuleToJSON(files []string) (map[string]any, error) {
 	moduleData := newModuleData()
 
+	var parsed []*moduleFile
 	for _, file := range files {
-		e.parseFileIntoModule(file, moduleData)
+		if mf := e.parseFile(file); mf != nil {
+			parsed = append(parsed, mf)
+		}
+	}
+
+	builder := &inputBuilder{evalCtx: moduleEvalContext(parsed)}
+	for _, mf := range parsed {
+		moduleData["_files"] = append(moduleData["_files"].([]string), mf.path)
+		for _, block := range mf.body.Blocks {
+			e.addBlockToModule(block, builder.blockData(block, mf), moduleData)
+		}
 	}
 
 	return moduleData, nil
@@ -170,40 +258,16 @@ func (e *Engine) parseModuleToJSON(files []string) (map[string]any, error) {
 
 func newModuleData() map[string]any {
 	return map[string]any{
-		"resources": []any{},
-		"data":      []any{},
-		"modules":   []any{},
-		"variables": []any{},
-		"outputs":   []any{},
-		"locals":    []any{},
-		"providers": []any{},
-		"terraform": map[string]any{},
-		"_files":    []string{},
-	}
-}
-
-func (e *Engine) parseFileIntoModule(file string, moduleData map[string]any) {
-	content, err := os.ReadFile(file)
-	if err != nil {
-		return
-	}
-
-	hclFile, diags := e.parser.ParseHCL(content, file)
-	if diags.HasErrors() {
-		return
-	}
-
-	body, ok := hclFile.Body.(*hclsyntax.Body)
-	if !ok {
-		return
-	}
-
-	moduleData["_files"] = append(moduleData["_files"].([]string), file)
-
-	for _, block := range body.Blocks {
-		blockData := e.extractBlockData(block, content)
-		blockData["_file"] = file
-		e.addBlockToModule(block, blockData, moduleData)
+		"_schema_version": InputSchemaVersion,
+		"resources":       []any{},
+		"data":            []any{},
+		"modules":         []any{},
+		"variables":       []any{},
+		"outputs":         []any{},
+		"locals":          []any{},
+		"providers":       []any{},
+		"terraform":       map[string]any{},
+		"_files":          []string{},
 	}
 }
 
@@ -230,8 +294,15 @@ func (e *Engine) addBlockToModule(block *hclsyntax.Block, blockData, moduleData
 		addLabeledBlock(blockData, block.Labels, 1, "name")
 		appendToSlice(moduleData, "providers", blockData)
 	case "terraform":
+		// Multiple terraform blocks are merged; nested block lists are concatenated
+		tf := moduleData["terraform"].(map[string]any)
 		for k, v := range blockData {
-			moduleData["terraform"].(map[string]any)[k] = v
+			existing, ok := tf[k].([]any)
+			if list, isList := v.([]any); ok && isList {
+				tf[k] = append(existing, list...)
+				continue
+			}
+			tf[k] = v
 		}
 	}
 }
@@ -250,106 +321,126 @@ func appendToSlice(moduleData map[string]any, key string, blockData map[string]a
 	moduleData[key] = append(moduleData[key].([]any), blockData)
 }
 
-// extractBlockData extracts data from an HCL block into a map
-func (e *Engine) extractBlockData(block *hclsyntax.Block, content []byte) map[string]any {
-	data := map[string]any{
-		"_block_type": block.Type,
-		"_range": map[string]any{
-			"start_line":   block.Range().Start.Line,
-			"end_line":     block.Range().End.Line,
-			"start_column": block.Range().Start.Column,
-			"end_column":   block.Range().End.Column,
-		},
-	}
-
-	// Extract attributes
-	for name, attr := range block.Body.Attributes {
-		// Get the raw expression text
-		exprRange := attr.Expr.Range()
-		if exprRange.Start.Byte < len(content) && exprRange.End.Byte <= len(content) {
-			exprText := string(content[exprRange.Start.Byte:exprRange.End.Byte])
-			data[name] = exprText
-		}
-	}
-
-	// Extract nested blocks
-	for _, nested := range block.Body.Blocks {
-		nestedData := e.extractBlockData(nested, content)
-		key := nested.Type
-		if len(nested.Labels) > 0 {
-			key = nested.Type + "_" + nested.Labels[0]
-		}
-
-		if existing, ok := data[nested.Type]; ok {
-			// If already exists as slice, append
-			if slice, ok := existing.([]any); ok {
-				data[nested.Type] = append(slice, nestedData)
-			} else {
-				// Convert to slice
-				data[nested.Type] = []any{existing, nestedData}
-			}
-		} else {
-			data[key] = nestedData
-		}
-	}
-
-	return data
-}
-
 // policyEvalContext holds context for policy evaluation.
 type policyEvalContext struct {
 	ctx        context.Context
 	moduleData map[string]any
 	dir        string
+	sources    sourceIndex
+	txn        storage.Transaction // overlays the module's data, if any
 }
 
-// evaluatePolicies evaluates all policies against the module data.
+// evaluatePolicies evaluates the prepared entrypoints of one scope (module or
+// repository) against the input. Data overlays of the directory are applied in
+// a transaction that is discarded afterwards.
 func (e *Engine) evaluatePolicies(
 	ctx context.Context,
-	policies []string,
+	set *policySet,
 	moduleData map[string]any,
 	dir string,
+	sources sourceIndex,
+	repo bool,
 ) ([]sdk.Finding, error) {
 	var findings []sdk.Finding
 
-	evalCtx := &policyEvalContext{ctx: ctx, moduleData: moduleData, dir: dir}
+	evalCtx := &policyEvalContext{ctx: ctx, moduleData: moduleData, dir: dir, sources: sources}
 
-	for _, policy := range policies {
-		// Evaluate deny rules
-		denyFindings := e.evaluateQuery(evalCtx, policy, "data.terraform.deny", sdk.SeverityError)
-		findings = append(findings, denyFindings...)
+	overlay, err := overlayData(set.data, dir)
+	if err != nil {
+		return nil, fmt.Errorf("loading data overlay: %w", err)
+	}
+	if overlay != nil {
+		txn, err := set.store.NewTransaction(ctx, storage.WriteParams)
+		if err != nil {
+			return nil, err
+		}
+		defer set.store.Abort(ctx, txn)
+
+		if err := set.store.Write(ctx, txn, storage.ReplaceOp, storage.Path{}, overlay); err != nil {
+			return nil, fmt.Errorf("applying data overlay: %w", err)
+		}
+		evalCtx.txn = txn
+	}
+
+	for _, pq := range set.queries {
+		if pq.repo != repo {
+			continue
+		}
+		findings = append(findings, e.evaluateQuery(evalCtx, pq)...)
+	}
 
-		// Evaluate warn rules
-		warnFindings := e.evaluateQuery(evalCtx, policy, "data.terraform.warn", sdk.SeverityWarning)
-		findings = append(findings, warnFindings...)
+	// Repository findings span modules: runRepository applies the exceptions
+	if !repo && len(set.exceptions) > 0 {
+		if sources == nil {
+			sources = moduleSources(moduleData)
+		}
+		applyExceptions(findings, set.exceptions, sources, set.now)
 	}
 
 	return findings, nil
 }
 
-// evaluateQuery evaluates a single Rego query and returns findings.
-func (e *Engine) evaluateQuery(
-	evalCtx *policyEvalContext,
-	policy string,
-	query string,
-	severity sdk.Severity,
-) []sdk.Finding {
-	r := rego.New(
-		rego.Query(query),
-		rego.Module("policy.rego", policy),
-		rego.Input(evalCtx.moduleData),
-	)
+// evaluateQuery evaluates a prepared query and returns findings. Evaluation
+// errors are reported as policy.eval-error findings.
+func (e *Engine) evaluateQuery(evalCtx *policyEvalContext, pq preparedQuery) []sdk.Finding {
+	opts := []rego.EvalOption{rego.EvalInput(evalCtx.moduleData)}
+	if evalCtx.txn != nil {
+		opts = append(opts, rego.EvalTransaction(evalCtx.txn))
+	}
 
-	rs, err := r.Eval(evalCtx.ctx)
+	var prof *profiler.Profiler
+	if e.config.Profile {
+		prof = profiler.New()
+		opts = append(opts, rego.EvalQueryTracer(prof))
+	}
+
+	rs, err := pq.prepared.Eval(evalCtx.ctx, opts...)
+	if prof != nil {
+		e.recordTimings(prof)
+	}
 	if err != nil {
-		return nil
+		return errorFindings(err, evalCtx.dir)
 	}
 
-	return e.extractFindings(rs, evalCtx.dir, severity)
+	findings := e.extractFindings(rs, evalCtx.dir, pq.severity, evalCtx.sources)
+	for i := range findings {
+		if findings[i].Rule == defaultViolation {
+			findings[i].Rule = pq.defaultRule()
+		}
+		if meta, ok := pq.rules[findings[i].Rule]; ok {
+			findings[i].Severity = meta.Severity
+		}
+	}
+
+	// Apply the rule configuration: disabled rules and library rules that
+	// are neither selected nor enabled are dropped, and a configured severity
+	// replaces the policy's
+	configured := findings[:0]
+	for _, f := range findings {
+		if e.unselectedRules[f.Rule] {
+			continue
+		}
+		if cfg, ok := e.ruleConfig(f.Rule); ok {
+			if !cfg.Enabled {
+				continue
+			}
+			if cfg.Severity != "" {
+				f.Severity = parseSeverity(cfg.Severity)
+			}
+		}
+		configured = append(configured, f)
+	}
+	return configured
 }
 
-// extractFindings extracts findings from Rego result set.
-func (e *Engine) extractFindings(rs rego.ResultSet, dir string, severity sdk.Severity) []sdk.Finding {
+// extractFindings extracts findings from Rego result set. Violations that name an
+// address instead of a file are located through sources.
+func (e *Engine) extractFindings(
+	rs rego.ResultSet,
+	dir string,
+	severity sdk.Severity,
+	sources sourceIndex,
+) []sdk.Finding {
 	var findings []sdk.Finding
 
 	for _, result := range rs {
@@ -361,6 +452,7 @@ func (e *Engine) extractFindings(rs rego.ResultSet, dir string, severity sdk.Sev
 			for _, v := range violations {
 				finding := e.violationToFinding(v, dir)
 				finding.Severity = severity
+				sources.locate(&finding, v)
 				findings = append(findings, finding)
 			}
 		}
@@ -397,10 +489,10 @@ func (e *Engine) violationToFinding(violation any, dir string) sdk.Finding {
 		if severity, ok := v["severity"].(string); ok {
 			finding.Severity = parseSeverity(severity)
 		}
-		if line, ok := v["line"].(float64); ok {
+		if line, ok := intValue(v["line"]); ok {
 			finding.Location = hcl.Range{
 				Filename: finding.File,
-				Start:    hcl.Pos{Line: int(line), Column: 1},
+				Start:    hcl.Pos{Line: line, Column: 1},
 			}
 		}
 	}
@@ -408,6 +500,21 @@ func (e *Engine) violationToFinding(violation any, dir string) sdk.Finding {
 	return finding
 }
 
+// intValue converts a number from a Rego result, which is a json.Number when
+// it comes from evaluation.
+func intValue(v any) (int, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		i, err := n.Int64()
+		return int(i), err == nil
+	case float64:
+		return int(n), true
+	case int:
+		return n, true
+	}
+	return 0, false
+}
+
 // groupFilesByDirectory groups files by their parent directory
 func (e *Engine) groupFilesByDirectory(files []string) map[string][]string {
 	dirFiles := make(map[string][]string)
@@ -434,9 +541,16 @@ func parseSeverity(severity string) sdk.Severity {
 	}
 }
 
-// GetInput returns the module data as JSON for debugging
+// GetInput returns the module data as JSON for debugging. With a plan file
+// configured it returns the plan input instead.
 func (e *Engine) GetInput(files []string) ([]byte, error) {
-	data, err := e.parseModuleToJSON(files)
+	var data map[string]any
+	var err error
+	if e.config.PlanFile != "" {
+		data, _, _, err = e.planInput(files)
+	} else {
+		data, err = e.parseModuleToJSON(files)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -444,12 +558,17 @@ func (e *Engine) GetInput(files []string) ([]byte, error) {
 }
 
 // builtinPolicies contains default policies (OPA v1 Rego syntax)
-var builtinPolicies = []string{
+var builtinPolicies = []policyModule{
 	// Required version policy
-	`package terraform
+	{path: "builtin/terraform_block.rego", content: `package terraform
 
 import rego.v1
 
+# METADATA
+# title: Required terraform block
+# description: Modules must declare a terraform block with required_version.
+# custom:
+#   severity: warning
 deny contains msg if {
     count(input.terraform) == 0
     msg := {
@@ -459,6 +578,11 @@ deny contains msg if {
     }
 }
 
+# METADATA
+# title: Required Terraform version
+# description: The terraform block must set required_version.
+# custom:
+#   severity: warning
 deny contains msg if {
     tf := input.terraform
     not tf.required_version
@@ -468,12 +592,17 @@ deny contains msg if {
         "severity": "warning"
     }
 }
-`,
+`},
 	// Required providers policy
-	`package terraform
+	{path: "builtin/required_providers.rego", content: `package terraform
 
 import rego.v1
 
+# METADATA
+# title: Required providers
+# description: Modules that configure providers must declare required_providers.
+# custom:
+#   severity: warning
 deny contains msg if {
     count(input.providers) > 0
     count(input.terraform) == 0
@@ -483,54 +612,77 @@ deny contains msg if {
         "severity": "warning"
     }
 }
-`,
+`},
 	// Security policies
-	`package terraform
+	{path: "builtin/security.rego", content: `package terraform
 
 import rego.v1
 
+# METADATA
+# title: No public SSH
+# description: Security groups must not allow SSH (port 22) from 0.0.0.0/0.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/vpc/latest/userguide/vpc-security-groups.html
 deny contains msg if {
     some resource in input.resources
     resource.type == "aws_security_group"
-    contains(resource.ingress, "0.0.0.0/0")
-    contains(resource.ingress, "22")
+    some rule in resource.ingress
+    rule.from_port <= 22
+    rule.to_port >= 22
+    "0.0.0.0/0" in rule.cidr_blocks
     msg := {
         "msg": sprintf("Security group %s allows SSH from 0.0.0.0/0", [resource.name]),
         "rule": "no-public-ssh",
         "severity": "error",
-        "file": resource._file
+        "file": resource._file,
+        "line": resource._range.start_line
     }
 }
 
+# METADATA
+# title: No public S3 buckets
+# description: S3 buckets must not use the public-read canned ACL.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonS3/latest/userguide/access-control-block-public-access.html
 deny contains msg if {
     some resource in input.resources
     resource.type == "aws_s3_bucket"
-    resource.acl == "\"public-read\""
+    resource.acl == "public-read"
     msg := {
         "msg": sprintf("S3 bucket %s has public-read ACL", [resource.name]),
         "rule": "no-public-s3",
         "severity": "error",
-        "file": resource._file
+        "file": resource._file,
+        "line": resource._range.start_line
     }
 }
 
+# METADATA
+# title: No public RDS instances
+# description: RDS instances must not be publicly accessible.
+# related_resources:
+# - ref: https://docs.aws.amazon.com/AmazonRDS/latest/UserGuide/USER_VPC.WorkingWithRDSInstanceinaVPC.html
 deny contains msg if {
     some resource in input.resources
     resource.type == "aws_db_instance"
-    resource.publicly_accessible == "true"
+    resource.publicly_accessible == true
     msg := {
         "msg": sprintf("RDS instance %s is publicly accessible", [resource.name]),
         "rule": "no-public-rds",
         "severity": "error",
-        "file": resource._file
+        "file": resource._file,
+        "line": resource._range.start_line
     }
 }
-`,
+`},
 	// Tagging policies
-	`package terraform
+	{path: "builtin/tagging.rego", content: `package terraform
 
 import rego.v1
 
+# METADATA
+# title: Required tags
+# description: Taggable resources (EC2 instances, S3 buckets) should have tags.
 warn contains msg if {
     some resource in input.resources
     resource.type == "aws_instance"
@@ -539,10 +691,14 @@ warn contains msg if {
         "msg": sprintf("EC2 instance %s is missing tags", [resource.name]),
         "rule": "required-tags",
         "severity": "warning",
-        "file": resource._file
+        "file": resource._file,
+        "line": resource._range.start_line
     }
 }
 
+# METADATA
+# title: Required tags
+# description: Taggable resources (EC2 instances, S3 buckets) should have tags.
 warn contains msg if {
     some resource in input.resources
     resource.type == "aws_s3_bucket"
@@ -551,26 +707,31 @@ warn contains msg if {
         "msg": sprintf("S3 bucket %s is missing tags", [resource.name]),
         "rule": "required-tags",
         "severity": "warning",
-        "file": resource._file
+        "file": resource._file,
+        "line": resource._range.start_line
     }
 }
-`,
+`},
 	// Module source policy
-	`package terraform
+	{path: "builtin/module_source.rego", content: `package terraform
 
 import rego.v1
 
+# METADATA
+# title: Module version constraint
+# description: Modules from registries or remote sources should pin a version.
 warn contains msg if {
     some module in input.modules
     not module.version
-    not startswith(module.source, "\"./")
-    not startswith(module.source, "\"../")
+    not startswith(module.source, "./")
+    not startswith(module.source, "../")
     msg := {
         "msg": sprintf("Module %s should have a version constraint", [module.name]),
         "rule": "module-version",
         "severity": "warning",
-        "file": module._file
+        "file": module._file,
+        "line": module._range.start_line
     }
 }
-`,
+`},
 }
@@ -2,6 +2,7 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -303,6 +304,11 @@ func TestViolationToFinding_Map(t *testing.T) {
 	assert.Equal(t, "policy.no-public-ssh", finding.Rule)
 	assert.Equal(t, "/path/to/main.tf", finding.File)
 	assert.Equal(t, 10, finding.Location.Start.Line)
+
+	// Evaluation results carry numbers as json.Number
+	violation["line"] = json.Number("12")
+	finding = engine.violationToFinding(violation, "/path/to/dir")
+	assert.Equal(t, 12, finding.Location.Start.Line)
 }
 
 func TestEngine_MultipleFiles(t *testing.T) {
@@ -366,7 +372,7 @@ func TestBuiltinPolicies(t *testing.T) {
 
 	// Each policy should contain package declaration
 	for i, policy := range builtinPolicies {
-		assert.Contains(t, policy, "package terraform", "policy %d should have package declaration", i)
+		assert.Contains(t, policy.content, "package terraform", "policy %d should have package declaration", i)
 	}
 }
 
@@ -467,20 +473,68 @@ func TestAppendToSlice(t *testing.T) {
 	assert.Equal(t, "aws_s3_bucket", resources[1].(map[string]any)["type"])
 }
 
-func TestEvaluateQuery_InvalidPolicy(t *testing.T) {
+func TestPreparePolicies_InvalidPolicy(t *testing.T) {
+	// Invalid Rego is reported with its location instead of being ignored
+	set, err := New(nil).preparePolicies(context.Background(), []policyModule{
+		{path: "broken.rego", content: "package terraform\n\nthis is not valid rego {"},
+		builtinPolicies[0],
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, set.errors)
+	assert.Equal(t, "policy.eval-error", set.errors[0].Rule)
+	assert.Equal(t, "broken.rego", set.errors[0].File)
+	assert.Equal(t, 3, set.errors[0].Location.Start.Line)
+
+	// The remaining policy is still prepared; it only defines deny
+	require.Len(t, set.queries, 1)
+	assert.Equal(t, "data.terraform.deny", set.queries[0].query)
+}
+
+func TestPreparePolicies_CompileError(t *testing.T) {
+	set, err := New(nil).preparePolicies(context.Background(), []policyModule{
+		{path: "undefined.rego", content: `package terraform
+
+import rego.v1
+
+deny contains msg if {
+    msg := undefined_function(input)
+}
+`},
+		builtinPolicies[0],
+	})
+	require.NoError(t, err)
+
+	require.Len(t, set.errors, 1)
+	assert.Equal(t, "undefined.rego", set.errors[0].File)
+	assert.Equal(t, 6, set.errors[0].Location.Start.Line)
+	assert.Contains(t, set.errors[0].Message, "undefined_function")
+}
+
+func TestEvaluateQuery_RuntimeError(t *testing.T) {
 	engine := New(nil)
 
+	// A complete rule with conflicting values fails at evaluation time
+	set, err := New(nil).preparePolicies(context.Background(), []policyModule{{path: "conflict.rego", content: `package terraform
+
+import rego.v1
+
+deny := "a" if input.resources
+deny := "b" if input.resources
+`}})
+	require.NoError(t, err)
+
 	evalCtx := &policyEvalContext{
 		ctx:        context.Background(),
 		moduleData: newModuleData(),
 		dir:        "/test/dir",
 	}
+	findings := engine.evaluateQuery(evalCtx, set.queries[0])
 
-	// Invalid Rego policy should return nil (error handled gracefully)
-	invalidPolicy := "this is not valid rego {"
-	findings := engine.evaluateQuery(evalCtx, invalidPolicy, "data.terraform.deny", "error")
-
-	assert.Nil(t, findings)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "policy.eval-error", findings[0].Rule)
+	assert.Equal(t, "conflict.rego", findings[0].File)
+	assert.Positive(t, findings[0].Location.Start.Line)
 }
 
 func TestEvaluateQuery_ValidPolicy(t *testing.T) {
@@ -515,7 +569,11 @@ deny contains msg if {
     }
 }
 `
-	findings := engine.evaluateQuery(evalCtx, policy, "data.terraform.deny", "error")
+	set, err := New(nil).preparePolicies(context.Background(), []policyModule{{path: "test.rego", content: policy}})
+	require.NoError(t, err)
+	require.Empty(t, set.errors)
+
+	findings := engine.evaluateQuery(evalCtx, set.queries[0])
 
 	assert.Len(t, findings, 1)
 	assert.Equal(t, "Found EC2 instance", findings[0].Message)
@@ -526,23 +584,23 @@ func TestExtractFindings_EmptyResults(t *testing.T) {
 	engine := New(nil)
 
 	// Empty result set
-	findings := engine.extractFindings(nil, "/test/dir", "error")
+	findings := engine.extractFindings(nil, "/test/dir", "error", nil)
 	assert.Empty(t, findings)
 }
 
-func TestParseFileIntoModule_NonexistentFile(t *testing.T) {
+func TestParseModuleToJSON_NonexistentFile(t *testing.T) {
 	engine := New(nil)
-	moduleData := newModuleData()
 
 	// Should not panic on nonexistent file
-	engine.parseFileIntoModule("/nonexistent/file.tf", moduleData)
+	moduleData, err := engine.parseModuleToJSON([]string{"/nonexistent/file.tf"})
+	require.NoError(t, err)
 
 	// Module data should remain unchanged
 	assert.Empty(t, moduleData["resources"].([]any))
 	assert.Empty(t, moduleData["_files"].([]string))
 }
 
-func TestParseFileIntoModule_InvalidHCL(t *testing.T) {
+func TestParseModuleToJSON_InvalidHCL(t *testing.T) {
 	tmpDir := t.TempDir()
 	tmpFile := filepath.Join(tmpDir, "invalid.tf")
 
@@ -550,11 +608,68 @@ func TestParseFileIntoModule_InvalidHCL(t *testing.T) {
 	require.NoError(t, os.WriteFile(tmpFile, []byte("this is { not valid hcl"), 0o644))
 
 	engine := New(nil)
-	moduleData := newModuleData()
 
 	// Should not panic on invalid HCL
-	engine.parseFileIntoModule(tmpFile, moduleData)
+	moduleData, err := engine.parseModuleToJSON([]string{tmpFile})
+	require.NoError(t, err)
 
 	// File should not be added since parsing failed
 	assert.Empty(t, moduleData["_files"].([]string))
 }
+
+func TestEngine_Run_ReportsPolicyErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfFile := filepath.Join(tmpDir, "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`resource "aws_instance" "web" {}`), 0o644))
+
+	broken := filepath.Join(tmpDir, "broken.rego")
+	require.NoError(t, os.WriteFile(broken, []byte("package terraform\n\ndeny contains msg if {\n"), 0o644))
+	valid := filepath.Join(tmpDir, "valid.rego")
+	require.NoError(t, os.WriteFile(valid, []byte(`package terraform
+
+import rego.v1
+
+deny contains "instance found" if {
+    some r in input.resources
+    r.type == "aws_instance"
+}
+`), 0o644))
+
+	engine := New(&Config{PolicyFiles: []string{broken, valid}})
+	findings, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	rules := make(map[string]string)
+	for _, f := range findings {
+		rules[f.Rule] = f.File
+	}
+	assert.Equal(t, broken, rules["policy.eval-error"])
+	assert.Contains(t, rules, "policy.violation")
+}
+
+func TestEngine_Profile(t *testing.T) {
+	tmpDir := t.TempDir()
+	tfFile := filepath.Join(tmpDir, "main.tf")
+	require.NoError(t, os.WriteFile(tfFile, []byte(`resource "aws_s3_bucket" "b" {
+  acl = "public-read"
+}
+`), 0o644))
+
+	engine := New(&Config{Profile: true})
+	_, err := engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+
+	timings := engine.Timings()
+	require.NotEmpty(t, timings)
+	policies := make([]string, len(timings))
+	for i, timing := range timings {
+		policies[i] = timing.Policy
+	}
+	assert.Contains(t, policies, "builtin/security.rego")
+
+	// Without profiling nothing is recorded
+	engine = New(nil)
+	_, err = engine.Run(context.Background(), []string{tfFile})
+	require.NoError(t, err)
+	assert.Empty(t, engine.Timings())
+}
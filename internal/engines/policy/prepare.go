@@ -0,0 +1,216 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/profiler"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/topdown"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// policyModule is a Rego source file. Built-in policies use builtin/ paths.
+type policyModule struct {
+	path    string
+	content string
+}
+
+// entrypoint is a query whose results are violations of a fixed severity.
+type entrypoint struct {
+	query     string
+	severity  sdk.Severity
+	namespace string // <namespace> of package terratidy.<namespace>, if any
+	repo      bool   // Evaluated once against the repository input, see repositoryInput
+}
+
+// preparedQuery is an entrypoint compiled for repeated evaluation.
+type preparedQuery struct {
+	entrypoint
+	prepared rego.PreparedEvalQuery
+	// rules describes the rules contributing to the entrypoint, keyed by finding rule name
+	rules map[string]RuleMetadata
+}
+
+// defaultRule is the rule name of violations that do not set one: the single
+// rule of the entrypoint if there is only one, else the namespace rule.
+func (pq preparedQuery) defaultRule() string {
+	if len(pq.rules) == 1 {
+		for id := range pq.rules {
+			return id
+		}
+	}
+	return pq.namespaceRule()
+}
+
+// policySet holds the policies compiled once and prepared for evaluation.
+type policySet struct {
+	queries []preparedQuery
+	// store holds the base data documents; data is its content
+	store storage.Store
+	data  map[string]any
+	// exceptions waive rules for matching resources; now is when they are checked
+	exceptions []Exception
+	now        time.Time
+	// errors are findings for policy files that failed to parse or compile
+	errors []sdk.Finding
+}
+
+// PolicyTiming is the time spent evaluating one policy file.
+type PolicyTiming struct {
+	Policy   string
+	Duration time.Duration
+}
+
+// preparePolicies compiles all policy modules together and prepares one query
+// per entrypoint. Files that fail to parse or compile are reported as
+// policy.eval-error findings and left out, so one broken file does not disable
+// the others.
+func (e *Engine) preparePolicies(ctx context.Context, modules []policyModule) (*policySet, error) {
+	data, err := e.loadData()
+	if err != nil {
+		return nil, fmt.Errorf("loading data: %w", err)
+	}
+	exceptions, err := e.loadExceptions()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if exceptions != nil {
+		data["exceptions"] = exceptionsData(exceptions, now)
+	}
+	set := &policySet{store: inmem.NewFromObject(data), data: data, exceptions: exceptions, now: now}
+
+	parsed := make(map[string]*ast.Module, len(modules))
+	for _, m := range modules {
+		module, err := ast.ParseModuleWithOpts(m.path, m.content, ast.ParserOptions{ProcessAnnotation: true})
+		if err != nil {
+			set.errors = append(set.errors, errorFindings(err, m.path)...)
+			continue
+		}
+		parsed[m.path] = module
+	}
+
+	var compiler *ast.Compiler
+	for {
+		compiler = ast.NewCompiler()
+		compiler.Compile(parsed)
+		if !compiler.Failed() {
+			break
+		}
+
+		// Drop the files the errors point at and compile the rest again
+		removed := false
+		for _, compileErr := range compiler.Errors {
+			if compileErr.Location == nil {
+				continue
+			}
+			if _, ok := parsed[compileErr.Location.File]; ok {
+				delete(parsed, compileErr.Location.File)
+				removed = true
+			}
+		}
+		set.errors = append(set.errors, errorFindings(compiler.Errors, "")...)
+		if !removed {
+			return nil, fmt.Errorf("compiling policies: %w", compiler.Errors)
+		}
+	}
+
+	entrypoints := discoverEntrypoints(compiler, e.config.Entrypoints)
+	metadata := collectMetadata(compiler, entrypoints)
+
+	for _, ep := range entrypoints {
+		pq, err := rego.New(
+			rego.Query(ep.query),
+			rego.Compiler(compiler),
+			rego.Store(set.store),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("preparing %s: %w", ep.query, err)
+		}
+		set.queries = append(set.queries, preparedQuery{
+			entrypoint: ep,
+			prepared:   pq,
+			rules:      metadata[ep.query],
+		})
+	}
+
+	return set, nil
+}
+
+// errorFindings converts Rego parse, compile or evaluation errors to
+// policy.eval-error findings located in the policy source. Errors without a
+// location are reported against fallback.
+func errorFindings(err error, fallback string) []sdk.Finding {
+	var astErrs ast.Errors
+	if errors.As(err, &astErrs) {
+		findings := make([]sdk.Finding, 0, len(astErrs))
+		for _, e := range astErrs {
+			findings = append(findings, evalErrorFinding(e.Message, e.Location, fallback))
+		}
+		return findings
+	}
+
+	var topdownErr *topdown.Error
+	if errors.As(err, &topdownErr) {
+		return []sdk.Finding{evalErrorFinding(topdownErr.Message, topdownErr.Location, fallback)}
+	}
+
+	return []sdk.Finding{evalErrorFinding(err.Error(), nil, fallback)}
+}
+
+func evalErrorFinding(msg string, loc *ast.Location, fallback string) sdk.Finding {
+	finding := sdk.Finding{
+		Rule:     "policy.eval-error",
+		Message:  msg,
+		File:     fallback,
+		Severity: sdk.SeverityError,
+	}
+	if loc != nil && loc.File != "" {
+		finding.File = loc.File
+		finding.Location = hcl.Range{
+			Filename: loc.File,
+			Start:    hcl.Pos{Line: loc.Row, Column: loc.Col},
+			End:      hcl.Pos{Line: loc.Row, Column: loc.Col},
+		}
+	}
+	return finding
+}
+
+// recordTimings adds the evaluation time of each policy file in a profile.
+// The query itself has no file and is left out.
+func (e *Engine) recordTimings(prof *profiler.Profiler) {
+	for file, report := range prof.ReportByFile().Files {
+		if file == "" {
+			continue
+		}
+		var total int64
+		for _, stat := range report.Result {
+			total += stat.ExprTimeNs
+		}
+		e.timings[file] += time.Duration(total)
+	}
+}
+
+// Timings returns the time spent in each policy file during the last runs,
+// slowest first. It is only populated when Config.Profile is set.
+func (e *Engine) Timings() []PolicyTiming {
+	timings := make([]PolicyTiming, 0, len(e.timings))
+	for policy, d := range e.timings {
+		timings = append(timings, PolicyTiming{Policy: policy, Duration: d})
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].Duration != timings[j].Duration {
+			return timings[i].Duration > timings[j].Duration
+		}
+		return timings[i].Policy < timings[j].Policy
+	})
+	return timings
+}
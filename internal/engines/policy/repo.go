@@ -0,0 +1,212 @@
+package policy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// Repository input. Policies with deny_repo, warn_repo or info_repo rules are
+// evaluated once against a document describing every module of the run:
+//
+//	{
+//	  "_schema_version": 1,
+//	  "root": "<directory containing all modules>",
+//	  "modules": [{
+//	    "path": "live/prod",            // relative to root, "." for root itself
+//	    "dir": "<directory as given>",
+//	    "root": true,                   // not called by another module of the run
+//	    "called_by": ["..."],           // paths of the modules calling it
+//	    "module_calls": [{"name", "source", "version", "local", "path", "_file", "_range"}],
+//	    "backend": {"type": "s3", ...}, // null without a backend or cloud block
+//	    "input": {...}                  // the module's own policy input
+//	  }]
+//	}
+
+// hasRepoQueries reports whether any entrypoint is repository-scoped.
+func (s *policySet) hasRepoQueries() bool {
+	for _, pq := range s.queries {
+		if pq.repo {
+			return true
+		}
+	}
+	return false
+}
+
+// runRepository evaluates the repository-scope entrypoints once against the
+// repository input.
+func (e *Engine) runRepository(ctx context.Context, set *policySet, files []string) ([]sdk.Finding, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+	input, root, err := e.repositoryInput(files)
+	if err != nil {
+		return nil, fmt.Errorf("building repository input: %w", err)
+	}
+
+	findings, err := e.evaluatePolicies(ctx, set, input, root, nil, true)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating repository policies: %w", err)
+	}
+	applyRepositoryExceptions(findings, set, input)
+	return findings, nil
+}
+
+// applyRepositoryExceptions applies the exceptions to repository findings.
+// Addresses repeat across modules, so each finding is matched against the
+// blocks of the module holding its file.
+func applyRepositoryExceptions(findings []sdk.Finding, set *policySet, input map[string]any) {
+	if len(set.exceptions) == 0 {
+		return
+	}
+
+	byDir := make(map[string]sourceIndex)
+	modules, _ := input["modules"].([]any)
+	for _, m := range modules {
+		module, _ := m.(map[string]any)
+		dir, _ := module["dir"].(string)
+		moduleData, _ := module["input"].(map[string]any)
+		byDir[dir] = moduleSources(moduleData)
+	}
+
+	for i := range findings {
+		sources := byDir[filepath.Dir(findings[i].File)]
+		applyExceptions(findings[i:i+1], set.exceptions, sources, set.now)
+	}
+}
+
+// repositoryInput builds the repository input for files and returns it with
+// the root directory.
+func (e *Engine) repositoryInput(files []string) (map[string]any, string, error) {
+	root := commonDir(files)
+	dirFiles := e.groupFilesByDirectory(files)
+
+	dirs := make([]string, 0, len(dirFiles))
+	for dir := range dirFiles {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+
+	modules := make([]any, 0, len(dirs))
+	byPath := make(map[string]map[string]any, len(dirs))
+	for _, dir := range dirs {
+		moduleData, err := e.parseModuleToJSON(dirFiles[dir])
+		if err != nil {
+			return nil, "", fmt.Errorf("parsing %s: %w", dir, err)
+		}
+
+		path := repoPath(root, dir)
+		module := map[string]any{
+			"path":         path,
+			"dir":          dir,
+			"root":         true,
+			"called_by":    []any{},
+			"module_calls": moduleCalls(root, dir, moduleData),
+			"backend":      backendData(moduleData),
+			"input":        moduleData,
+		}
+		modules = append(modules, module)
+		byPath[path] = module
+	}
+
+	// Link local module calls to the modules they resolve to
+	for _, m := range modules {
+		caller := m.(map[string]any)
+		for _, c := range caller["module_calls"].([]any) {
+			call := c.(map[string]any)
+			path, ok := call["path"].(string)
+			if !ok {
+				continue
+			}
+			if callee, ok := byPath[path]; ok {
+				callee["root"] = false
+				callee["called_by"] = append(callee["called_by"].([]any), caller["path"])
+			}
+		}
+	}
+
+	return map[string]any{
+		"_schema_version": InputSchemaVersion,
+		"root":            root,
+		"modules":         modules,
+	}, root, nil
+}
+
+// GetRepositoryInput returns the repository input as JSON for debugging.
+func (e *Engine) GetRepositoryInput(files []string) ([]byte, error) {
+	input, _, err := e.repositoryInput(files)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(input, "", "  ")
+}
+
+// moduleCalls describes the module blocks of a module. Local sources (./ or
+// ../) are resolved to a path relative to root.
+func moduleCalls(root, dir string, moduleData map[string]any) []any {
+	calls := []any{}
+	blocks, _ := moduleData["modules"].([]any)
+	for _, b := range blocks {
+		block := b.(map[string]any)
+		call := map[string]any{
+			"name":   block["name"],
+			"source": block["source"],
+			"local":  false,
+			"path":   nil,
+			"_file":  block["_file"],
+			"_range": block["_range"],
+		}
+		if version, ok := block["version"]; ok {
+			call["version"] = version
+		}
+		if source, ok := block["source"].(string); ok && isLocalSource(source) {
+			call["local"] = true
+			call["path"] = repoPath(root, filepath.Join(dir, filepath.FromSlash(source)))
+		}
+		calls = append(calls, call)
+	}
+	return calls
+}
+
+// isLocalSource reports whether a module source is a local path, which
+// Terraform recognises by its ./ or ../ prefix.
+func isLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// backendData returns the backend of a module with its type, or nil. A
+// Terraform Cloud block is reported as backend type cloud.
+func backendData(moduleData map[string]any) any {
+	tf, _ := moduleData["terraform"].(map[string]any)
+	for _, kind := range []string{"backend", "cloud"} {
+		blocks, _ := tf[kind].([]any)
+		if len(blocks) == 0 {
+			continue
+		}
+		block := blocks[0].(map[string]any)
+		backend := make(map[string]any, len(block)+1)
+		for k, v := range block {
+			backend[k] = v
+		}
+		backend["type"] = kind
+		if labels, _ := block["_labels"].([]any); kind == "backend" && len(labels) > 0 {
+			backend["type"] = labels[0]
+		}
+		return backend
+	}
+	return nil
+}
+
+// repoPath returns dir relative to root with forward slashes.
+func repoPath(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return filepath.ToSlash(dir)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRepository writes two root modules under live/ calling a shared module.
+func writeRepository(t *testing.T) (root string, files []string) {
+	t.Helper()
+	root = t.TempDir()
+	modules := map[string]string{
+		"live/prod/main.tf": `terraform {
+  backend "s3" {
+    bucket  = "state"
+    encrypt = true
+  }
+}
+
+module "app" {
+  source = "../../modules/app"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.0.0"
+}
+`,
+		"live/dev/main.tf": `terraform {
+  backend "local" {}
+}
+
+module "app" {
+  source = "../../modules/app"
+}
+`,
+		"modules/app/main.tf": `resource "aws_s3_bucket" "app" {}
+`,
+	}
+	for _, name := range []string{"live/dev/main.tf", "live/prod/main.tf", "modules/app/main.tf"} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(modules[name]), 0o644))
+		files = append(files, path)
+	}
+	return root, files
+}
+
+func TestRepositoryInput(t *testing.T) {
+	root, files := writeRepository(t)
+
+	input, inputRoot, err := New(nil).repositoryInput(files)
+	require.NoError(t, err)
+	assert.Equal(t, root, inputRoot)
+	assert.Equal(t, root, input["root"])
+
+	modules := make(map[string]map[string]any)
+	for _, m := range input["modules"].([]any) {
+		module := m.(map[string]any)
+		modules[module["path"].(string)] = module
+	}
+	require.Len(t, modules, 3)
+
+	prod := modules["live/prod"]
+	assert.Equal(t, true, prod["root"])
+	assert.Equal(t, "s3", prod["backend"].(map[string]any)["type"])
+	assert.Equal(t, true, prod["backend"].(map[string]any)["encrypt"])
+
+	calls := prod["module_calls"].([]any)
+	require.Len(t, calls, 2)
+	app := calls[0].(map[string]any)
+	assert.Equal(t, "app", app["name"])
+	assert.Equal(t, true, app["local"])
+	assert.Equal(t, "modules/app", app["path"])
+	vpc := calls[1].(map[string]any)
+	assert.Equal(t, false, vpc["local"])
+	assert.Nil(t, vpc["path"])
+	assert.Equal(t, "5.0.0", vpc["version"])
+
+	shared := modules["modules/app"]
+	assert.Equal(t, false, shared["root"])
+	assert.Equal(t, []any{"live/dev", "live/prod"}, shared["called_by"])
+	assert.Nil(t, shared["backend"])
+	assert.Len(t, shared["input"].(map[string]any)["resources"], 1)
+}
+
+func TestEngine_RepositoryPolicies(t *testing.T) {
+	_, files := writeRepository(t)
+
+	policyDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(policyDir, "layout.rego"), []byte(`package terratidy.layout
+
+import rego.v1
+
+# METADATA
+# title: Encrypted S3 state
+# custom:
+#   id: live-s3-backend
+deny_repo contains msg if {
+	some m in input.modules
+	m.root
+	startswith(m.path, "live/")
+	not encrypted_s3(m.backend)
+	msg := {"msg": sprintf("%s must use an encrypted S3 backend", [m.path]), "file": m.input._files[0]}
+}
+
+encrypted_s3(backend) if {
+	backend.type == "s3"
+	backend.encrypt == true
+}
+
+info_repo contains sprintf("%d modules", [count(input.modules)])
+
+# Module-scope rules still run per module
+warn contains "bucket" if {
+	some r in input.resources
+	r.type == "aws_s3_bucket"
+}
+`), 0o644))
+
+	findings, err := New(&Config{PolicyDirs: []string{policyDir}, NoBuiltin: true}).Run(context.Background(), files)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, f := range findings {
+		messages = append(messages, f.Message)
+		switch f.Message {
+		case "live/dev must use an encrypted S3 backend":
+			assert.Equal(t, "policy.live-s3-backend", f.Rule)
+			assert.Equal(t, sdk.SeverityError, f.Severity)
+			assert.Equal(t, files[0], f.File)
+		case "3 modules":
+			assert.Equal(t, sdk.SeverityInfo, f.Severity)
+		}
+	}
+	assert.ElementsMatch(t, []string{"live/dev must use an encrypted S3 backend", "3 modules", "bucket"}, messages)
+}
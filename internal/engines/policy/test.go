@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/cover"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/tester"
+)
+
+// TestResult is the outcome of one Rego test rule (a rule named test_*).
+type TestResult struct {
+	Name     string // Full rule path, e.g. data.terratidy.security.test_public_bucket
+	File     string
+	Line     int
+	Duration time.Duration
+	Skipped  bool   // Rules named todo_test_* are skipped, as with opa test
+	Failure  string // Empty when the test passed
+	Output   string // print() output of the test
+}
+
+// TestReport holds the results of the Rego tests in the policies.
+type TestReport struct {
+	Results []TestResult
+	// Coverage is the coverage of the policy files, in `opa test --coverage`
+	// format. It is only set when requested.
+	Coverage *cover.Report
+}
+
+// Test runs the test_* rules of the configured policies with the OPA test
+// runner, against the same data documents and bundles as Run.
+func (e *Engine) Test(ctx context.Context, coverage bool) (*TestReport, error) {
+	policies, err := e.loadPolicies()
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
+	}
+	data, err := e.loadData()
+	if err != nil {
+		return nil, fmt.Errorf("loading data: %w", err)
+	}
+
+	modules := make(map[string]*ast.Module, len(policies))
+	for _, m := range policies {
+		module, err := ast.ParseModuleWithOpts(m.path, m.content, ast.ParserOptions{ProcessAnnotation: true})
+		if err != nil {
+			return nil, err
+		}
+		modules[m.path] = module
+	}
+
+	runner := tester.NewRunner().
+		SetCompiler(ast.NewCompiler().WithEnablePrintStatements(true)).
+		SetStore(inmem.NewFromObject(data)).
+		CapturePrintOutput(true).
+		SetModules(modules)
+
+	var cov *cover.Cover
+	if coverage {
+		cov = cover.New()
+		runner.SetCoverageQueryTracer(cov)
+	}
+
+	ch, err := runner.RunTests(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("compiling policies: %w", err)
+	}
+
+	report := &TestReport{}
+	for r := range ch {
+		report.Results = append(report.Results, testResult(r))
+	}
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].File < report.Results[j].File
+	})
+
+	if cov != nil {
+		r := cov.Report(modules)
+		report.Coverage = &r
+	}
+
+	return report, nil
+}
+
+// testResult converts an OPA test result.
+func testResult(r *tester.Result) TestResult {
+	result := TestResult{
+		Name:     r.Package + "." + r.Name,
+		Duration: r.Duration,
+		Skipped:  r.Skip,
+		Output:   string(r.Output),
+	}
+	if r.Location != nil {
+		result.File = r.Location.File
+		result.Line = r.Location.Row
+	}
+
+	switch {
+	case r.Error != nil:
+		result.Failure = r.Error.Error()
+	case r.Fail:
+		result.Failure = "test failed"
+	}
+	return result
+}
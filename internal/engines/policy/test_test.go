@@ -0,0 +1,93 @@
+package policy
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Test(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "tags.rego"), `package terratidy.tags
+
+import rego.v1
+
+deny contains msg if {
+    some r in input.resources
+    not r.tags.Owner
+    msg := {"msg": sprintf("%s has no Owner tag", [r.name]), "rule": "owner-tag"}
+}
+
+deny contains msg if {
+    some r in input.resources
+    r.tags.Owner == ""
+    msg := {"msg": sprintf("%s has an empty Owner tag", [r.name]), "rule": "owner-tag"}
+}
+`)
+	writeFile(t, filepath.Join(dir, "tags_test.rego"), `package terratidy.tags_test
+
+import rego.v1
+
+import data.terratidy.tags
+
+test_missing_owner if {
+    count(tags.deny) == 1 with input as {"resources": [{"name": "web", "tags": {}}]}
+}
+
+test_tagged if {
+    print("checking tagged resource")
+    count(tags.deny) == 0 with input as {"resources": [{"name": "web", "tags": {"Owner": "me"}}]}
+}
+
+test_wrong if {
+    count(tags.deny) == 2 with input as {"resources": [{"name": "web", "tags": {}}]}
+}
+
+todo_test_later if {
+    false
+}
+`)
+
+	engine := New(&Config{PolicyDirs: []string{dir}})
+	report, err := engine.Test(context.Background(), true)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 4)
+
+	results := make(map[string]TestResult)
+	for _, r := range report.Results {
+		results[r.Name] = r
+	}
+
+	assert.Empty(t, results["data.terratidy.tags_test.test_missing_owner"].Failure)
+	assert.Equal(t, filepath.Join(dir, "tags_test.rego"), results["data.terratidy.tags_test.test_missing_owner"].File)
+	assert.Equal(t, "checking tagged resource\n", results["data.terratidy.tags_test.test_tagged"].Output)
+	assert.Equal(t, "test failed", results["data.terratidy.tags_test.test_wrong"].Failure)
+	assert.Equal(t, 16, results["data.terratidy.tags_test.test_wrong"].Line)
+	assert.True(t, results["data.terratidy.tags_test.todo_test_later"].Skipped)
+
+	// The empty-tag rule is never exercised
+	require.NotNil(t, report.Coverage)
+	assert.Contains(t, report.Coverage.Files, filepath.Join(dir, "tags.rego"))
+	assert.Less(t, report.Coverage.Coverage, 100.0)
+	assert.True(t, report.Coverage.Files[filepath.Join(dir, "tags.rego")].IsNotCovered(14))
+}
+
+func TestEngine_Test_CompileError(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "broken_test.rego"), `package terratidy.broken_test
+
+import rego.v1
+
+test_undefined if {
+    data.terratidy.missing.deny == set()
+    undefined_function(1)
+}
+`)
+
+	_, err := New(&Config{PolicyDirs: []string{dir}}).Test(context.Background(), false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "undefined_function")
+}
@@ -0,0 +1,772 @@
+package style
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// defaultMaxLineLength is the line length above which long expressions are wrapped.
+const defaultMaxLineLength = 120
+
+// defaultPolicyAttributes lists the attributes that usually hold IAM-style JSON policy documents.
+var defaultPolicyAttributes = []string{
+	"policy",
+	"assume_role_policy",
+	"inline_policy",
+	"policy_document",
+	"access_policies",
+	"container_definitions",
+}
+
+// textEdit replaces the bytes in [start, end) with text.
+type textEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// applyTextEdits applies non-overlapping edits to content. Edits that overlap an
+// earlier edit are skipped; they will be picked up on the next run.
+func applyTextEdits(content []byte, edits []textEdit) []byte {
+	sort.SliceStable(edits, func(i, j int) bool {
+		return edits[i].start < edits[j].start
+	})
+
+	var out []byte
+	pos := 0
+	for _, edit := range edits {
+		if edit.start < pos {
+			continue
+		}
+		out = append(out, content[pos:edit.start]...)
+		out = append(out, edit.text...)
+		pos = edit.end
+	}
+	return append(out, content[pos:]...)
+}
+
+// parseSyntaxFile reads and parses a file as native HCL syntax for fixes.
+func parseSyntaxFile(filePath string) ([]byte, *hclsyntax.Body, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	file, diags := hclsyntax.ParseConfig(content, filePath, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, nil, diags
+	}
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return content, nil, nil
+	}
+	return content, body, nil
+}
+
+// fileContent returns the source bytes for a parsed file.
+func fileContent(ctx *sdk.Context, file *hcl.File) []byte {
+	if file != nil && len(file.Bytes) > 0 {
+		return file.Bytes
+	}
+	content, err := os.ReadFile(ctx.File)
+	if err != nil {
+		return nil
+	}
+	return content
+}
+
+// sourceOf returns the source text covered by rng.
+func sourceOf(content []byte, rng hcl.Range) string {
+	if rng.Start.Byte < 0 || rng.End.Byte > len(content) || rng.Start.Byte > rng.End.Byte {
+		return ""
+	}
+	return string(content[rng.Start.Byte:rng.End.Byte])
+}
+
+// lineIndent returns the leading whitespace of the line containing offset.
+func lineIndent(content []byte, offset int) string {
+	start := offset
+	for start > 0 && content[start-1] != '\n' {
+		start--
+	}
+	end := start
+	for end < len(content) && (content[end] == ' ' || content[end] == '\t') {
+		end++
+	}
+	return string(content[start:end])
+}
+
+// lineLength returns the length in bytes of the line containing offset.
+func lineLength(content []byte, offset int) int {
+	start := offset
+	for start > 0 && content[start-1] != '\n' {
+		start--
+	}
+	end := offset
+	for end < len(content) && content[end] != '\n' {
+		end++
+	}
+	return len(strings.TrimRight(string(content[start:end]), "\r"))
+}
+
+// intOption reads an integer rule option, accepting YAML and JSON number types.
+func intOption(options map[string]interface{}, key string, def int) int {
+	switch v := options[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	default:
+		return def
+	}
+}
+
+// stringOption reads a string rule option.
+func stringOption(options map[string]interface{}, key, def string) string {
+	if v, ok := options[key].(string); ok && v != "" {
+		return v
+	}
+	return def
+}
+
+// stringListOption reads a list of strings rule option.
+func stringListOption(options map[string]interface{}, key string, def []string) []string {
+	raw, ok := options[key].([]interface{})
+	if !ok {
+		if list, ok := options[key].([]string); ok {
+			return list
+		}
+		return def
+	}
+
+	var list []string
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// isHeredoc reports whether a template expression is written as a heredoc.
+func isHeredoc(content []byte, expr *hclsyntax.TemplateExpr) bool {
+	return strings.HasPrefix(sourceOf(content, expr.SrcRange), "<<")
+}
+
+// HeredocIndentedRule prefers indented heredocs (<<-EOT) over flush heredocs (<<EOT).
+type HeredocIndentedRule struct{}
+
+// Name returns the rule identifier.
+func (r *HeredocIndentedRule) Name() string {
+	return "style.heredoc-indented"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *HeredocIndentedRule) Description() string {
+	return "Prefers indented heredocs (<<-EOT) so heredoc bodies follow the surrounding indentation"
+}
+
+// Check examines heredoc templates for the flush (<<EOT) form.
+func (r *HeredocIndentedRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	hclFile, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	content := fileContent(ctx, file)
+	filePath := ctx.File
+
+	for _, expr := range flushHeredocs(content, hclFile) {
+		_, fixable := indentHeredoc(content, expr)
+		finding := sdk.Finding{
+			Rule:     r.Name(),
+			Message:  "Use an indented heredoc (<<-) instead of a flush heredoc (<<)",
+			File:     ctx.File,
+			Location: expr.SrcRange,
+			Severity: sdk.SeverityInfo,
+			Fixable:  fixable,
+		}
+		if fixable {
+			finding.FixFunc = func() ([]byte, error) {
+				return r.Fix(&sdk.Context{File: filePath}, nil)
+			}
+		}
+		findings = append(findings, finding)
+	}
+
+	return findings, nil
+}
+
+// Fix converts every safely convertible flush heredoc into an indented heredoc.
+func (r *HeredocIndentedRule) Fix(ctx *sdk.Context, _ *hcl.File) ([]byte, error) {
+	content, body, err := parseSyntaxFile(ctx.File)
+	if err != nil || body == nil {
+		return content, err
+	}
+
+	var edits []textEdit
+	for _, expr := range flushHeredocs(content, body) {
+		if text, ok := indentHeredoc(content, expr); ok {
+			edits = append(edits, textEdit{
+				start: expr.SrcRange.Start.Byte,
+				end:   expr.SrcRange.End.Byte,
+				text:  text,
+			})
+		}
+	}
+
+	return applyTextEdits(content, edits), nil
+}
+
+// flushHeredocs returns all heredoc templates that use the <<EOT form.
+func flushHeredocs(content []byte, body *hclsyntax.Body) []*hclsyntax.TemplateExpr {
+	var heredocs []*hclsyntax.TemplateExpr
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		expr, ok := node.(*hclsyntax.TemplateExpr)
+		if !ok || !isHeredoc(content, expr) {
+			return nil
+		}
+		if !strings.HasPrefix(sourceOf(content, expr.SrcRange), "<<-") {
+			heredocs = append(heredocs, expr)
+		}
+		return nil
+	})
+	return heredocs
+}
+
+// indentHeredoc rewrites a flush heredoc as an indented heredoc. The rewrite is
+// only safe when at least one body line starts at column 1; otherwise the
+// indented form would strip whitespace that is part of the string value.
+func indentHeredoc(content []byte, expr *hclsyntax.TemplateExpr) (string, bool) {
+	src := sourceOf(content, expr.SrcRange)
+	lines := strings.Split(src, "\n")
+	if len(lines) < 2 {
+		return "", false
+	}
+
+	opener := lines[0]
+	closer := strings.TrimSpace(lines[len(lines)-1])
+	bodyLines := lines[1 : len(lines)-1]
+
+	flushLine := false
+	for _, line := range bodyLines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			flushLine = true
+			break
+		}
+	}
+	if !flushLine {
+		return "", false
+	}
+
+	indent := lineIndent(content, expr.SrcRange.Start.Byte)
+	bodyIndent := indent + "  "
+
+	var b strings.Builder
+	b.WriteString("<<-" + strings.TrimPrefix(opener, "<<") + "\n")
+	for _, line := range bodyLines {
+		if strings.TrimSpace(line) != "" {
+			b.WriteString(bodyIndent)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString(indent + closer)
+
+	// Guard against edge cases (e.g. multi-line interpolations) by checking
+	// that the rewritten heredoc still produces the same literal parts.
+	newExpr, diags := hclsyntax.ParseExpression([]byte(b.String()+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return "", false
+	}
+	newTemplate, ok := newExpr.(*hclsyntax.TemplateExpr)
+	if !ok || !sameTemplateLiterals(expr, newTemplate) {
+		return "", false
+	}
+
+	return b.String(), true
+}
+
+// sameTemplateLiterals reports whether two templates have identical literal parts.
+func sameTemplateLiterals(a, b *hclsyntax.TemplateExpr) bool {
+	if len(a.Parts) != len(b.Parts) {
+		return false
+	}
+	for i := range a.Parts {
+		litA, okA := a.Parts[i].(*hclsyntax.LiteralValueExpr)
+		litB, okB := b.Parts[i].(*hclsyntax.LiteralValueExpr)
+		if okA != okB {
+			return false
+		}
+		if okA && !litA.Val.RawEquals(litB.Val) {
+			return false
+		}
+	}
+	return true
+}
+
+// PolicyJSONEncodeRule enforces a consistent way of writing JSON policy documents.
+type PolicyJSONEncodeRule struct{}
+
+// Name returns the rule identifier.
+func (r *PolicyJSONEncodeRule) Name() string {
+	return "style.policy-jsonencode"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *PolicyJSONEncodeRule) Description() string {
+	return "Ensures policy documents consistently use jsonencode() (or raw JSON heredocs, if configured)"
+}
+
+// Check examines policy attributes for the non-preferred JSON style.
+//
+// Options:
+//   - prefer: "jsonencode" (default) or "heredoc"
+//   - attributes: list of attribute names holding policy documents
+func (r *PolicyJSONEncodeRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	hclFile, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	prefer := stringOption(ctx.Config, "prefer", "jsonencode")
+	names := make(map[string]bool)
+	for _, name := range stringListOption(ctx.Config, "attributes", defaultPolicyAttributes) {
+		names[name] = true
+	}
+
+	_ = hclsyntax.VisitAll(hclFile, func(node hclsyntax.Node) hcl.Diagnostics {
+		attr, ok := node.(*hclsyntax.Attribute)
+		if !ok || !names[attr.Name] {
+			return nil
+		}
+
+		switch expr := attr.Expr.(type) {
+		case *hclsyntax.TemplateExpr:
+			if prefer == "jsonencode" && isJSONTemplate(expr) {
+				findings = append(findings, sdk.Finding{
+					Rule:     r.Name(),
+					Message:  "Use jsonencode() instead of a raw JSON string for " + attr.Name,
+					File:     ctx.File,
+					Location: attr.SrcRange,
+					Severity: sdk.SeverityWarning,
+					Fixable:  false,
+				})
+			}
+		case *hclsyntax.FunctionCallExpr:
+			if prefer == "heredoc" && expr.Name == "jsonencode" {
+				findings = append(findings, sdk.Finding{
+					Rule:     r.Name(),
+					Message:  "Use a JSON heredoc instead of jsonencode() for " + attr.Name,
+					File:     ctx.File,
+					Location: attr.SrcRange,
+					Severity: sdk.SeverityWarning,
+					Fixable:  false,
+				})
+			}
+		}
+		return nil
+	})
+
+	return findings, nil
+}
+
+// isJSONTemplate reports whether a template holds a JSON object or array. Templates
+// with interpolations are checked by shape only, since they cannot be decoded.
+func isJSONTemplate(expr *hclsyntax.TemplateExpr) bool {
+	var literal strings.Builder
+	interpolated := false
+	for _, part := range expr.Parts {
+		lit, ok := part.(*hclsyntax.LiteralValueExpr)
+		if !ok || !lit.Val.Type().Equals(cty.String) || lit.Val.IsNull() {
+			interpolated = true
+			literal.WriteString(`""`)
+			continue
+		}
+		literal.WriteString(lit.Val.AsString())
+	}
+
+	text := strings.TrimSpace(literal.String())
+	if !strings.HasPrefix(text, "{") && !strings.HasPrefix(text, "[") {
+		return false
+	}
+	if interpolated {
+		return strings.HasSuffix(text, "}") || strings.HasSuffix(text, "]")
+	}
+	return json.Valid([]byte(text))
+}
+
+// Fix is a no-op for this rule as converting between JSON and HCL requires manual review.
+func (r *PolicyJSONEncodeRule) Fix(_ *sdk.Context, _ *hcl.File) ([]byte, error) {
+	return nil, nil
+}
+
+// TrailingCommaRule requires a trailing comma after the last element of multi-line lists.
+type TrailingCommaRule struct{}
+
+// Name returns the rule identifier.
+func (r *TrailingCommaRule) Name() string {
+	return "style.trailing-comma"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *TrailingCommaRule) Description() string {
+	return "Requires a trailing comma after the last element of multi-line lists"
+}
+
+// Check examines multi-line lists for a missing trailing comma.
+func (r *TrailingCommaRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	hclFile, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	content := fileContent(ctx, file)
+	filePath := ctx.File
+
+	for _, edit := range missingTrailingCommas(content, hclFile) {
+		pos := edit.pos
+		findings = append(findings, sdk.Finding{
+			Rule:     r.Name(),
+			Message:  "Multi-line list should have a trailing comma after the last element",
+			File:     ctx.File,
+			Location: hcl.Range{Filename: ctx.File, Start: pos, End: pos},
+			Severity: sdk.SeverityInfo,
+			Fixable:  true,
+			FixFunc: func() ([]byte, error) {
+				return r.Fix(&sdk.Context{File: filePath}, nil)
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+// Fix inserts the missing trailing commas.
+func (r *TrailingCommaRule) Fix(ctx *sdk.Context, _ *hcl.File) ([]byte, error) {
+	content, body, err := parseSyntaxFile(ctx.File)
+	if err != nil || body == nil {
+		return content, err
+	}
+
+	var edits []textEdit
+	for _, missing := range missingTrailingCommas(content, body) {
+		edits = append(edits, textEdit{start: missing.pos.Byte, end: missing.pos.Byte, text: ","})
+	}
+
+	return applyTextEdits(content, edits), nil
+}
+
+// trailingComma records where a missing trailing comma belongs.
+type trailingComma struct {
+	pos hcl.Pos
+}
+
+// missingTrailingCommas finds multi-line lists whose closing bracket is on its own
+// line and whose last element is not followed by a comma.
+func missingTrailingCommas(content []byte, body *hclsyntax.Body) []trailingComma {
+	var missing []trailingComma
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		tuple, ok := node.(*hclsyntax.TupleConsExpr)
+		if !ok || len(tuple.Exprs) == 0 {
+			return nil
+		}
+
+		last := tuple.Exprs[len(tuple.Exprs)-1].Range()
+		if tuple.SrcRange.End.Line <= last.End.Line {
+			return nil
+		}
+
+		i := last.End.Byte
+		for i < len(content) && (content[i] == ' ' || content[i] == '\t') {
+			i++
+		}
+		if i < len(content) && content[i] == ',' {
+			return nil
+		}
+
+		missing = append(missing, trailingComma{pos: last.End})
+		return nil
+	})
+	return missing
+}
+
+// NoInterpolationWrapperRule forbids templates that only wrap a single interpolation.
+type NoInterpolationWrapperRule struct{}
+
+// Name returns the rule identifier.
+func (r *NoInterpolationWrapperRule) Name() string {
+	return "style.no-interpolation-wrapper"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *NoInterpolationWrapperRule) Description() string {
+	return "Forbids interpolation-only templates like \"${var.x}\"; use the expression directly"
+}
+
+// Check examines templates that consist of a single interpolation.
+func (r *NoInterpolationWrapperRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	hclFile, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	content := fileContent(ctx, file)
+	filePath := ctx.File
+
+	for _, wrap := range interpolationWrappers(hclFile) {
+		inner := sourceOf(content, wrap.Wrapped.Range())
+		findings = append(findings, sdk.Finding{
+			Rule:     r.Name(),
+			Message:  "Interpolation-only template: use " + wrap.replacement(content) + " instead of \"${" + inner + "}\"",
+			File:     ctx.File,
+			Location: wrap.SrcRange,
+			Severity: sdk.SeverityWarning,
+			Fixable:  true,
+			FixFunc: func() ([]byte, error) {
+				return r.Fix(&sdk.Context{File: filePath}, nil)
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+// Fix replaces interpolation-only templates with the wrapped expression.
+func (r *NoInterpolationWrapperRule) Fix(ctx *sdk.Context, _ *hcl.File) ([]byte, error) {
+	content, body, err := parseSyntaxFile(ctx.File)
+	if err != nil || body == nil {
+		return content, err
+	}
+
+	var edits []textEdit
+	for _, wrap := range interpolationWrappers(body) {
+		edits = append(edits, textEdit{
+			start: wrap.SrcRange.Start.Byte,
+			end:   wrap.SrcRange.End.Byte,
+			text:  wrap.replacement(content),
+		})
+	}
+
+	return applyTextEdits(content, edits), nil
+}
+
+// interpolationWrapper is a "${...}" interpolation-only template.
+type interpolationWrapper struct {
+	*hclsyntax.TemplateWrapExpr
+	objectKey bool // Key of an object constructor
+}
+
+// replacement returns the expression replacing the template. Object keys are
+// parenthesized: a bare reference would be taken as a literal key.
+func (w interpolationWrapper) replacement(content []byte) string {
+	inner := sourceOf(content, w.Wrapped.Range())
+	if w.objectKey {
+		return "(" + inner + ")"
+	}
+	return inner
+}
+
+// interpolationWrappers returns all "${...}" interpolation-only templates.
+func interpolationWrappers(body *hclsyntax.Body) []interpolationWrapper {
+	var wraps []interpolationWrapper
+	keys := make(map[*hclsyntax.TemplateWrapExpr]bool)
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		switch n := node.(type) {
+		case *hclsyntax.ObjectConsKeyExpr:
+			// Visited before the key expression itself
+			if wrap, ok := n.Wrapped.(*hclsyntax.TemplateWrapExpr); ok {
+				keys[wrap] = true
+			}
+		case *hclsyntax.TemplateWrapExpr:
+			wraps = append(wraps, interpolationWrapper{TemplateWrapExpr: n, objectKey: keys[n]})
+		}
+		return nil
+	})
+	return wraps
+}
+
+// LongExpressionWrapRule wraps long conditional and for expressions over multiple lines.
+type LongExpressionWrapRule struct{}
+
+// Name returns the rule identifier.
+func (r *LongExpressionWrapRule) Name() string {
+	return "style.long-expression-wrap"
+}
+
+// Description returns a human-readable description of the rule.
+func (r *LongExpressionWrapRule) Description() string {
+	return "Wraps conditional and for expressions that exceed the maximum line length"
+}
+
+// Check examines single-line conditional and for expressions on overlong lines.
+//
+// Options:
+//   - max_line_length: maximum line length (default 120)
+func (r *LongExpressionWrapRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	hclFile, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	content := fileContent(ctx, file)
+	maxLength := intOption(ctx.Config, "max_line_length", defaultMaxLineLength)
+	filePath := ctx.File
+
+	for _, expr := range longExpressions(content, hclFile, maxLength) {
+		kind := "for"
+		if _, ok := expr.(*hclsyntax.ConditionalExpr); ok {
+			kind = "conditional"
+		}
+		findings = append(findings, sdk.Finding{
+			Rule:     r.Name(),
+			Message:  "Long " + kind + " expression should be wrapped over multiple lines",
+			File:     ctx.File,
+			Location: expr.Range(),
+			Severity: sdk.SeverityInfo,
+			Fixable:  true,
+			FixFunc: func() ([]byte, error) {
+				return r.Fix(&sdk.Context{File: filePath, Config: map[string]interface{}{
+					"max_line_length": maxLength,
+				}}, nil)
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+// Fix wraps every long conditional and for expression.
+func (r *LongExpressionWrapRule) Fix(ctx *sdk.Context, _ *hcl.File) ([]byte, error) {
+	content, body, err := parseSyntaxFile(ctx.File)
+	if err != nil || body == nil {
+		return content, err
+	}
+
+	maxLength := intOption(ctx.Config, "max_line_length", defaultMaxLineLength)
+
+	var edits []textEdit
+	for _, expr := range longExpressions(content, body, maxLength) {
+		rng := expr.Range()
+		indent := lineIndent(content, rng.Start.Byte)
+
+		var text string
+		switch e := expr.(type) {
+		case *hclsyntax.ConditionalExpr:
+			text = wrapConditional(content, e, indent)
+		case *hclsyntax.ForExpr:
+			text = wrapFor(content, e, indent)
+		}
+		edits = append(edits, textEdit{start: rng.Start.Byte, end: rng.End.Byte, text: text})
+	}
+
+	return applyTextEdits(content, edits), nil
+}
+
+// longExpressions returns the outermost single-line conditional and for
+// expressions that sit on a line longer than maxLength.
+func longExpressions(content []byte, body *hclsyntax.Body, maxLength int) []hclsyntax.Expression {
+	var found []hclsyntax.Expression
+	_ = hclsyntax.VisitAll(body, func(node hclsyntax.Node) hcl.Diagnostics {
+		var expr hclsyntax.Expression
+		switch e := node.(type) {
+		case *hclsyntax.ConditionalExpr:
+			expr = e
+		case *hclsyntax.ForExpr:
+			expr = e
+		default:
+			return nil
+		}
+
+		rng := expr.Range()
+		if rng.Start.Line != rng.End.Line || lineLength(content, rng.Start.Byte) <= maxLength {
+			return nil
+		}
+
+		for _, outer := range found {
+			if outer.Range().ContainsOffset(rng.Start.Byte) {
+				return nil
+			}
+		}
+		found = append(found, expr)
+		return nil
+	})
+	return found
+}
+
+// wrapConditional renders a conditional expression over multiple lines. The
+// expression is wrapped in parentheses unless it is already enclosed in them.
+func wrapConditional(content []byte, expr *hclsyntax.ConditionalExpr, indent string) string {
+	inner := indent + "  "
+	lines := []string{
+		inner + sourceOf(content, expr.Condition.Range()),
+		inner + "? " + sourceOf(content, expr.TrueResult.Range()),
+		inner + ": " + sourceOf(content, expr.FalseResult.Range()),
+	}
+	body := strings.Join(lines, "\n")
+
+	if enclosedInParens(content, expr.SrcRange) {
+		return "\n" + body + "\n" + indent
+	}
+	return "(\n" + body + "\n" + indent + ")"
+}
+
+// enclosedInParens reports whether rng is directly surrounded by parentheses.
+func enclosedInParens(content []byte, rng hcl.Range) bool {
+	before := strings.TrimRight(string(content[:rng.Start.Byte]), " \t")
+	after := strings.TrimLeft(string(content[rng.End.Byte:]), " \t")
+	return strings.HasSuffix(before, "(") && strings.HasPrefix(after, ")")
+}
+
+// wrapFor renders a for expression with its body and condition on separate lines.
+func wrapFor(content []byte, expr *hclsyntax.ForExpr, indent string) string {
+	inner := indent + "  "
+	open, closing := "[", "]"
+	if expr.KeyExpr != nil {
+		open, closing = "{", "}"
+	}
+
+	vars := expr.ValVar
+	if expr.KeyVar != "" {
+		vars = expr.KeyVar + ", " + expr.ValVar
+	}
+
+	result := sourceOf(content, expr.ValExpr.Range())
+	if expr.KeyExpr != nil {
+		result = sourceOf(content, expr.KeyExpr.Range()) + " => " + result
+	}
+	if expr.Group {
+		result += "..."
+	}
+
+	var b strings.Builder
+	b.WriteString(open + "\n")
+	b.WriteString(inner + "for " + vars + " in " + sourceOf(content, expr.CollExpr.Range()) + " : " + result + "\n")
+	if expr.CondExpr != nil {
+		b.WriteString(inner + "if " + sourceOf(content, expr.CondExpr.Range()) + "\n")
+	}
+	b.WriteString(indent + closing)
+	return b.String()
+}
@@ -0,0 +1,237 @@
+package style
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkRule writes content to a temp file and runs a single rule against it.
+func checkRule(
+	t *testing.T, rule sdk.Rule, content string, options map[string]interface{},
+) (string, []sdk.Finding) {
+	t.Helper()
+
+	tmpFile := filepath.Join(t.TempDir(), "test.tf")
+	require.NoError(t, os.WriteFile(tmpFile, []byte(content), 0o644))
+
+	file, diags := hclparse.NewParser().ParseHCL([]byte(content), tmpFile)
+	require.False(t, diags.HasErrors(), diags.Error())
+
+	if options == nil {
+		options = make(map[string]interface{})
+	}
+	findings, err := rule.Check(&sdk.Context{File: tmpFile, Config: options}, file)
+	require.NoError(t, err)
+	return tmpFile, findings
+}
+
+func TestHeredocIndentedRule(t *testing.T) {
+	content := `resource "aws_instance" "web" {
+  user_data = <<EOT
+#!/bin/bash
+echo "hello ${var.name}"
+
+  indented
+EOT
+}
+`
+	tmpFile, findings := checkRule(t, &HeredocIndentedRule{}, content, nil)
+	require.Len(t, findings, 1)
+	assert.True(t, findings[0].Fixable)
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Equal(t, `resource "aws_instance" "web" {
+  user_data = <<-EOT
+    #!/bin/bash
+    echo "hello ${var.name}"
+
+      indented
+  EOT
+}
+`, string(fixed))
+
+	// The fixed file must not be flagged again
+	require.NoError(t, os.WriteFile(tmpFile, fixed, 0o644))
+	_, findings = checkRule(t, &HeredocIndentedRule{}, string(fixed), nil)
+	assert.Empty(t, findings)
+}
+
+func TestHeredocIndentedRule_NotFixableWhenAllLinesIndented(t *testing.T) {
+	content := `locals {
+  script = <<EOT
+  keep leading spaces
+  on every line
+EOT
+}
+`
+	_, findings := checkRule(t, &HeredocIndentedRule{}, content, nil)
+	require.Len(t, findings, 1)
+	assert.False(t, findings[0].Fixable)
+	assert.Nil(t, findings[0].FixFunc)
+}
+
+func TestPolicyJSONEncodeRule(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		options     map[string]interface{}
+		wantFinding bool
+	}{
+		{
+			name: "raw JSON heredoc",
+			content: `resource "aws_iam_policy" "p" {
+  policy = <<-EOT
+    {"Version": "2012-10-17", "Statement": []}
+  EOT
+}
+`,
+			wantFinding: true,
+		},
+		{
+			name: "interpolated JSON string",
+			content: `resource "aws_iam_policy" "p" {
+  policy = "{\"Resource\": \"${aws_s3_bucket.b.arn}\"}"
+}
+`,
+			wantFinding: true,
+		},
+		{
+			name: "jsonencode",
+			content: `resource "aws_iam_policy" "p" {
+  policy = jsonencode({ Version = "2012-10-17", Statement = [] })
+}
+`,
+			wantFinding: false,
+		},
+		{
+			name: "non-policy attribute",
+			content: `resource "aws_ssm_parameter" "p" {
+  value = "{\"a\": 1}"
+}
+`,
+			wantFinding: false,
+		},
+		{
+			name: "prefer heredoc flags jsonencode",
+			content: `resource "aws_iam_policy" "p" {
+  policy = jsonencode({ Version = "2012-10-17" })
+}
+`,
+			options:     map[string]interface{}{"prefer": "heredoc"},
+			wantFinding: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, findings := checkRule(t, &PolicyJSONEncodeRule{}, tt.content, tt.options)
+			assert.Equal(t, tt.wantFinding, len(findings) > 0, "findings: %+v", findings)
+		})
+	}
+}
+
+func TestTrailingCommaRule(t *testing.T) {
+	content := `locals {
+  multi = [
+    "a",
+    "b" # last
+  ]
+  done = [
+    "a",
+  ]
+  inline = ["a", "b"]
+  closed = ["a",
+  "b"]
+}
+`
+	_, findings := checkRule(t, &TrailingCommaRule{}, content, nil)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 4, findings[0].Location.Start.Line)
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Contains(t, string(fixed), "    \"b\", # last\n")
+}
+
+func TestNoInterpolationWrapperRule(t *testing.T) {
+	content := `resource "aws_instance" "web" {
+  ami  = "${var.ami}"
+  name = "web-${var.env}"
+  tags = "${merge(local.tags, { Name = "web" })}"
+}
+`
+	_, findings := checkRule(t, &NoInterpolationWrapperRule{}, content, nil)
+	require.Len(t, findings, 2)
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Equal(t, `resource "aws_instance" "web" {
+  ami  = var.ami
+  name = "web-${var.env}"
+  tags = merge(local.tags, { Name = "web" })
+}
+`, string(fixed))
+}
+
+func TestNoInterpolationWrapperRule_ObjectKey(t *testing.T) {
+	// A bare var.k key would be the literal "var.k": the key keeps parentheses
+	content := `locals {
+  tags = { "${var.k}" = 1, "static" = "${var.v}" }
+}
+`
+	_, findings := checkRule(t, &NoInterpolationWrapperRule{}, content, nil)
+	require.Len(t, findings, 2)
+	assert.Contains(t, findings[0].Message, "use (var.k) instead")
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Equal(t, `locals {
+  tags = { (var.k) = 1, "static" = var.v }
+}
+`, string(fixed))
+}
+
+func TestLongExpressionWrapRule(t *testing.T) {
+	content := `locals {
+  short = var.enabled ? 1 : 0
+  size  = var.environment == "production" ? var.production_instance_size : var.default_instance_size
+  names = [for name, cfg in var.instances : upper(name) if cfg.enabled && cfg.environment == var.env]
+  call  = coalesce(var.override != null ? var.override : var.default_value_for_everything_else)
+}
+`
+	options := map[string]interface{}{"max_line_length": 60}
+	_, findings := checkRule(t, &LongExpressionWrapRule{}, content, options)
+	require.Len(t, findings, 3)
+
+	fixed, err := findings[0].FixFunc()
+	require.NoError(t, err)
+	assert.Equal(t, `locals {
+  short = var.enabled ? 1 : 0
+  size  = (
+    var.environment == "production"
+    ? var.production_instance_size
+    : var.default_instance_size
+  )
+  names = [
+    for name, cfg in var.instances : upper(name)
+    if cfg.enabled && cfg.environment == var.env
+  ]
+  call  = coalesce(
+    var.override != null
+    ? var.override
+    : var.default_value_for_everything_else
+  )
+}
+`, string(fixed))
+
+	// The wrapped output must still be valid HCL
+	_, diags := hclparse.NewParser().ParseHCL(fixed, "fixed.tf")
+	assert.False(t, diags.HasErrors(), diags.Error())
+}
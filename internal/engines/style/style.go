@@ -232,6 +232,13 @@ func (e *Engine) registerRules() {
 	// Variable and output ordering
 	e.rules = append(e.rules, &VariableOrderRule{})
 	e.rules = append(e.rules, &OutputOrderRule{})
+
+	// Expression formatting
+	e.rules = append(e.rules, &HeredocIndentedRule{})
+	e.rules = append(e.rules, &PolicyJSONEncodeRule{})
+	e.rules = append(e.rules, &TrailingCommaRule{})
+	e.rules = append(e.rules, &LongExpressionWrapRule{})
+	e.rules = append(e.rules, &NoInterpolationWrapperRule{})
 }
 
 // GetAllRules returns all registered rules for listing/documentation
@@ -667,8 +667,8 @@ func TestEngine_GetAllRules(t *testing.T) {
 	engine := New(nil)
 	rules := engine.GetAllRules()
 
-	// Verify we have all 12 rules registered
-	assert.Len(t, rules, 12, "should have 12 rules registered")
+	// Verify we have all 17 rules registered
+	assert.Len(t, rules, 17, "should have 17 rules registered")
 
 	// Verify each rule has required methods
 	for _, rule := range rules {
@@ -14,6 +14,7 @@ import (
 	"sync"
 
 	"github.com/santosr2/terratidy/internal/config"
+	"github.com/santosr2/terratidy/internal/engines/format"
 	"github.com/santosr2/terratidy/internal/engines/lint"
 	"github.com/santosr2/terratidy/internal/engines/style"
 	"github.com/santosr2/terratidy/pkg/sdk"
@@ -151,6 +152,8 @@ func (s *Server) handleMessage(content json.RawMessage) error {
 		return s.handleDidSave(msg)
 	case "textDocument/formatting":
 		return s.handleFormatting(msg)
+	case "textDocument/rangeFormatting":
+		return s.handleRangeFormatting(msg)
 	case "textDocument/codeAction":
 		return s.handleCodeAction(msg)
 	default:
@@ -195,8 +198,9 @@ func (s *Server) handleInitialize(msg RequestMessage) error {
 				Change:    1, // Full sync
 				Save:      &SaveOptions{IncludeText: true},
 			},
-			DocumentFormattingProvider: true,
-			CodeActionProvider:         true,
+			DocumentFormattingProvider:      true,
+			DocumentRangeFormattingProvider: true,
+			CodeActionProvider:              true,
 			DiagnosticProvider: &DiagnosticOptions{
 				InterFileDependencies: false,
 				WorkspaceDiagnostics:  false,
@@ -327,10 +331,84 @@ func (s *Server) handleFormatting(msg RequestMessage) error {
 		return s.sendResult(msg.ID, nil)
 	}
 
-	// For now, return empty edits (formatting would require hclwrite)
-	// A full implementation would format the content and return TextEdit[]
-	_ = doc
-	return s.sendResult(msg.ID, []TextEdit{})
+	path := uriToPath(doc.URI)
+	content := []byte(doc.Content)
+	formatted, err := format.FormatFileWithOptions(path, content, s.formatOptions())
+	if err != nil {
+		// Unparseable documents are left alone; diagnostics report the errors
+		return s.sendResult(msg.ID, []TextEdit{})
+	}
+
+	return s.sendResult(msg.ID, formattingEdits(path, content, formatted))
+}
+
+// handleRangeFormatting handles textDocument/rangeFormatting request
+func (s *Server) handleRangeFormatting(msg RequestMessage) error {
+	var params DocumentRangeFormattingParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return s.sendError(msg.ID, -32602, "Invalid params")
+	}
+
+	s.docMu.RLock()
+	doc, ok := s.documents[params.TextDocument.URI]
+	s.docMu.RUnlock()
+
+	if !ok {
+		return s.sendResult(msg.ID, nil)
+	}
+
+	// LSP lines are 0-based and a range ending at character 0 excludes that line
+	lines := format.LineRange{Start: params.Range.Start.Line + 1, End: params.Range.End.Line + 1}
+	if params.Range.End.Character == 0 && params.Range.End.Line > params.Range.Start.Line {
+		lines.End--
+	}
+
+	path := uriToPath(doc.URI)
+	content := []byte(doc.Content)
+	opts := s.formatOptions()
+	engine := format.New(&format.Config{
+		Verify:  true,
+		Options: &opts,
+		Ranges:  map[string][]format.LineRange{path: {lines}},
+	})
+	formatted, err := engine.FormatContent(path, content)
+	if err != nil {
+		return s.sendResult(msg.ID, []TextEdit{})
+	}
+
+	return s.sendResult(msg.ID, formattingEdits(path, content, formatted))
+}
+
+// formatOptions returns the formatting policies from engines.fmt.config
+func (s *Server) formatOptions() format.Options {
+	if s.config == nil {
+		return format.DefaultOptions()
+	}
+	opts, err := format.ParseOptions(s.config.Engines.Fmt.Config)
+	if err != nil {
+		return format.DefaultOptions()
+	}
+	return opts
+}
+
+// formattingEdits converts the difference between content and formatted into LSP text edits
+func formattingEdits(path string, content, formatted []byte) []TextEdit {
+	edits := []TextEdit{}
+	fix := format.ComputeDiff(path, content, formatted).Fix()
+	if fix == nil {
+		return edits
+	}
+
+	for _, edit := range fix.Edits {
+		edits = append(edits, TextEdit{
+			Range: Range{
+				Start: Position{Line: edit.Range.Start.Line - 1},
+				End:   Position{Line: edit.Range.End.Line - 1},
+			},
+			NewText: edit.NewText,
+		})
+	}
+	return edits
 }
 
 // handleCodeAction handles textDocument/codeAction request
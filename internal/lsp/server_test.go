@@ -584,3 +584,49 @@ func TestServer_HandleMessage_BeforeInitialize(_ *testing.T) {
 	// The important thing is it doesn't panic
 	_ = err
 }
+
+func TestServer_HandleRangeFormatting(t *testing.T) {
+	out := &bytes.Buffer{}
+	server := NewServer(strings.NewReader(""), out)
+
+	server.docMu.Lock()
+	server.documents["file:///test.tf"] = &Document{
+		URI:     "file:///test.tf",
+		Content: "locals {\na=1\n}\n\nlocals {\nb=2\n}\n",
+		Version: 1,
+	}
+	server.docMu.Unlock()
+
+	params := DocumentRangeFormattingParams{
+		TextDocument: TextDocumentIdentifier{URI: "file:///test.tf"},
+		Range: Range{
+			Start: Position{Line: 5, Character: 0},
+			End:   Position{Line: 5, Character: 3},
+		},
+	}
+	paramsJSON, _ := json.Marshal(params)
+
+	msg := RequestMessage{
+		JSONRPC: "2.0",
+		ID:      json.RawMessage(`1`),
+		Method:  "textDocument/rangeFormatting",
+		Params:  paramsJSON,
+	}
+
+	content, err := json.Marshal(msg)
+	require.NoError(t, err)
+	require.NoError(t, server.handleMessage(content))
+
+	output := out.String()
+	assert.Contains(t, output, `"newText":"  b = 2\n"`)
+	assert.NotContains(t, output, `a = 1`)
+}
+
+func TestFormattingEdits(t *testing.T) {
+	edits := formattingEdits("test.tf", []byte("locals {\na=1\n}\n"), []byte("locals {\n  a = 1\n}\n"))
+	require.Len(t, edits, 1)
+	assert.Equal(t, Range{Start: Position{Line: 1}, End: Position{Line: 2}}, edits[0].Range)
+	assert.Equal(t, "  a = 1\n", edits[0].NewText)
+
+	assert.Empty(t, formattingEdits("test.tf", []byte("a = 1\n"), []byte("a = 1\n")))
+}
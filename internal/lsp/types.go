@@ -90,10 +90,11 @@ type InitializeResult struct {
 
 // ServerCapabilities represents server capabilities
 type ServerCapabilities struct {
-	TextDocumentSync           *TextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
-	DocumentFormattingProvider bool                     `json:"documentFormattingProvider,omitempty"`
-	CodeActionProvider         bool                     `json:"codeActionProvider,omitempty"`
-	DiagnosticProvider         *DiagnosticOptions       `json:"diagnosticProvider,omitempty"`
+	TextDocumentSync                *TextDocumentSyncOptions `json:"textDocumentSync,omitempty"`
+	DocumentFormattingProvider      bool                     `json:"documentFormattingProvider,omitempty"`
+	DocumentRangeFormattingProvider bool                     `json:"documentRangeFormattingProvider,omitempty"`
+	CodeActionProvider              bool                     `json:"codeActionProvider,omitempty"`
+	DiagnosticProvider              *DiagnosticOptions       `json:"diagnosticProvider,omitempty"`
 }
 
 // TextDocumentSyncOptions represents text document sync options
@@ -175,6 +176,13 @@ type DocumentFormattingParams struct {
 	Options      FormattingOptions      `json:"options"`
 }
 
+// DocumentRangeFormattingParams represents range formatting parameters
+type DocumentRangeFormattingParams struct {
+	TextDocument TextDocumentIdentifier `json:"textDocument"`
+	Range        Range                  `json:"range"`
+	Options      FormattingOptions      `json:"options"`
+}
+
 // FormattingOptions represents formatting options
 type FormattingOptions struct {
 	TabSize                int  `json:"tabSize"`
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// Baseline is a previous result, written with --format json, that findings
+// are compared against to tell new findings from existing ones. Findings are
+// matched by their fingerprint, so a finding that only moved is not new.
+type Baseline struct {
+	Findings []sdk.Finding
+}
+
+// LoadBaseline reads a baseline from a JSON report.
+func LoadBaseline(file string) (*Baseline, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading baseline: %w", err)
+	}
+
+	var report JSONOutput
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, fmt.Errorf("parsing baseline %s: %w", file, err)
+	}
+
+	baseline := &Baseline{Findings: make([]sdk.Finding, 0, len(report.Findings))}
+	for _, f := range report.Findings {
+		baseline.Findings = append(baseline.Findings, sdk.Finding{
+			Rule:     f.Rule,
+			Message:  f.Message,
+			File:     f.File,
+			Severity: sdk.Severity(f.Severity),
+			Location: hcl.Range{
+				Filename: f.File,
+				Start:    hcl.Pos{Line: f.Location.Start.Line, Column: f.Location.Start.Column},
+				End:      hcl.Pos{Line: f.Location.End.Line, Column: f.Location.End.Column},
+			},
+			Fixable: f.Fixable,
+		})
+	}
+	return baseline, nil
+}
+
+// IsNew reports for each finding whether it is absent from the baseline.
+func (b *Baseline) IsNew(findings []sdk.Finding) []bool {
+	known := make(map[string]bool, len(b.Findings))
+	for _, fp := range findingFingerprints(b.Findings) {
+		known[fp] = true
+	}
+
+	isNew := make([]bool, len(findings))
+	for i, fp := range findingFingerprints(findings) {
+		isNew[i] = !known[fp]
+	}
+	return isNew
+}
+
+// Fixed returns the baseline findings that are absent from findings.
+func (b *Baseline) Fixed(findings []sdk.Finding) []sdk.Finding {
+	current := make(map[string]bool, len(findings))
+	for _, fp := range findingFingerprints(findings) {
+		current[fp] = true
+	}
+
+	var fixed []sdk.Finding
+	for i, fp := range findingFingerprints(b.Findings) {
+		if !current[fp] {
+			fixed = append(fixed, b.Findings[i])
+		}
+	}
+	return fixed
+}
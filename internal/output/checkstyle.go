@@ -0,0 +1,72 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// CheckstyleFormatter outputs findings as Checkstyle XML, which review bots
+// such as reviewdog and many CI dashboards understand.
+type CheckstyleFormatter struct{}
+
+// Checkstyle is the root element of a Checkstyle report
+type Checkstyle struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []CheckstyleFile `xml:"file"`
+}
+
+// CheckstyleFile holds the findings of one file
+type CheckstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []CheckstyleError `xml:"error"`
+}
+
+// CheckstyleError is a single finding
+type CheckstyleError struct {
+	Line     int    `xml:"line,attr,omitempty"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// Format implements the Formatter interface for Checkstyle output
+func (f *CheckstyleFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	byFile := make(map[string][]CheckstyleError)
+	for _, finding := range findings {
+		byFile[finding.File] = append(byFile[finding.File], CheckstyleError{
+			Line:     finding.Location.Start.Line,
+			Column:   finding.Location.Start.Column,
+			Severity: string(finding.Severity),
+			Message:  finding.Message,
+			Source:   "terratidy." + finding.Rule,
+		})
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	report := Checkstyle{Version: "4.3"}
+	for _, file := range files {
+		report.Files = append(report.Files, CheckstyleFile{Name: file, Errors: byFile[file]})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("encoding Checkstyle XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
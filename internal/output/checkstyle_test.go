@@ -0,0 +1,66 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestCheckstyleFormatter(t *testing.T) {
+	findings := []sdk.Finding{
+		{
+			Rule:     "style.resource-naming",
+			Message:  `Resource name "Web" should use snake_case`,
+			File:     "main.tf",
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{Start: hcl.Pos{Line: 15, Column: 3}},
+		},
+		{
+			Rule:     "lint.terraform-required-version",
+			Message:  "Missing required_version",
+			File:     "versions.tf",
+			Severity: sdk.SeverityWarning,
+		},
+		{
+			Rule:     "style.blank-line-between-blocks",
+			Message:  "Missing blank line",
+			File:     "main.tf",
+			Severity: sdk.SeverityInfo,
+			Location: hcl.Range{Start: hcl.Pos{Line: 20, Column: 1}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&CheckstyleFormatter{}).Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var report Checkstyle
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if len(report.Files) != 2 || report.Files[0].Name != "main.tf" || report.Files[1].Name != "versions.tf" {
+		t.Fatalf("files = %+v", report.Files)
+	}
+
+	errs := report.Files[0].Errors
+	if len(errs) != 2 {
+		t.Fatalf("main.tf errors = %+v", errs)
+	}
+	want := CheckstyleError{
+		Line:     15,
+		Column:   3,
+		Severity: "error",
+		Message:  `Resource name "Web" should use snake_case`,
+		Source:   "terratidy.style.resource-naming",
+	}
+	if errs[0] != want {
+		t.Errorf("error = %+v, want %+v", errs[0], want)
+	}
+	if errs[1].Severity != "info" || report.Files[1].Errors[0].Line != 0 {
+		t.Errorf("unexpected errors: %+v %+v", errs[1], report.Files[1].Errors[0])
+	}
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// GitHubFormatter outputs findings as GitHub Actions workflow commands, which
+// the runner turns into annotations on the workflow run and the pull request
+// diff. Unlike SARIF upload, annotations need no extra token permissions and
+// also work for pull requests from forks.
+type GitHubFormatter struct{}
+
+// Format implements the Formatter interface for GitHub Actions output
+func (f *GitHubFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	for _, finding := range findings {
+		// Module-level findings name a directory, which annotations cannot point to
+		var props []string
+		file := githubFile(finding.File)
+		if file != "" {
+			props = append(props, "file="+githubProperty(file))
+		}
+		if start := finding.Location.Start; file != "" && start.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", start.Line))
+			if start.Column > 0 {
+				props = append(props, fmt.Sprintf("col=%d", start.Column))
+			}
+			if end := finding.Location.End; end.Line >= start.Line {
+				props = append(props, fmt.Sprintf("endLine=%d", end.Line))
+				if end.Line == start.Line && end.Column > start.Column {
+					props = append(props, fmt.Sprintf("endColumn=%d", end.Column))
+				}
+			}
+		}
+		props = append(props, "title="+githubProperty(finding.Rule))
+
+		if _, err := fmt.Fprintf(w, "::%s %s::%s\n",
+			githubLevel(finding.Severity),
+			strings.Join(props, ","),
+			githubData(finding.Message),
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubFile returns the annotation path of a finding's file, or "" when it
+// has none or names a directory
+func githubFile(file string) string {
+	if file == "" {
+		return ""
+	}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		return ""
+	}
+	if rel := relativePath(file); rel != "." {
+		return rel
+	}
+	return ""
+}
+
+// githubLevel converts SDK severity to a workflow command
+func githubLevel(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityError:
+		return "error"
+	case sdk.SeverityInfo:
+		return "notice"
+	default:
+		return "warning"
+	}
+}
+
+// githubData escapes the message of a workflow command
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property value of a workflow command
+func githubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// relativePath returns file relative to the working directory when it lies
+// within it, with forward slashes. CI systems resolve report paths against
+// the repository checkout, where TerraTidy runs.
+func relativePath(file string) string {
+	if filepath.IsAbs(file) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, file); err == nil && rel != ".." &&
+				!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+				file = rel
+			}
+		}
+	}
+	return filepath.ToSlash(file)
+}
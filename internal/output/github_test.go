@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestGitHubFormatter(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := []sdk.Finding{
+		{
+			Rule:     "style.blank-line-between-blocks",
+			Message:  "Missing blank line",
+			File:     filepath.Join(wd, "modules", "main.tf"),
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{
+				Start: hcl.Pos{Line: 12, Column: 3},
+				End:   hcl.Pos{Line: 12, Column: 9},
+			},
+		},
+		{
+			Rule:     "policy.aws-s3-encryption",
+			Message:  "Bucket data: not encrypted\n100% public",
+			File:     "main.tf",
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{
+				Start: hcl.Pos{Line: 3, Column: 1},
+				End:   hcl.Pos{Line: 8, Column: 2},
+			},
+		},
+		{
+			Rule:     "policy.required-modules",
+			Message:  "Missing network module",
+			Severity: sdk.SeverityInfo,
+		},
+		{
+			Rule:     "policy.required-version",
+			Message:  "Missing required_version",
+			File:     wd,
+			Severity: sdk.SeverityWarning,
+		},
+		{
+			Rule:     "policy.required-providers",
+			Message:  "Missing required_providers",
+			File:     ".",
+			Severity: sdk.SeverityWarning,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&GitHubFormatter{}).Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "::warning file=modules/main.tf,line=12,col=3,endLine=12,endColumn=9,title=style.blank-line-between-blocks::Missing blank line\n" +
+		"::error file=main.tf,line=3,col=1,endLine=8,title=policy.aws-s3-encryption::Bucket data: not encrypted%0A100%25 public\n" +
+		"::notice title=policy.required-modules::Missing network module\n" +
+		"::warning title=policy.required-version::Missing required_version\n" +
+		"::warning title=policy.required-providers::Missing required_providers\n"
+	if buf.String() != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestGitHubProperty(t *testing.T) {
+	if got := githubProperty("a:b,c%d"); got != "a%3Ab%2Cc%25d" {
+		t.Errorf("githubProperty() = %s", got)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// GitLabFormatter outputs findings as a GitLab Code Quality report, which
+// merge requests show as a widget and as markers in the diff.
+type GitLabFormatter struct{}
+
+// GitLabIssue is an issue of a Code Quality report
+type GitLabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    GitLabLocation `json:"location"`
+}
+
+// GitLabLocation is the location of a Code Quality issue
+type GitLabLocation struct {
+	Path  string      `json:"path"`
+	Lines GitLabLines `json:"lines"`
+}
+
+// GitLabLines is the line range of a Code Quality issue
+type GitLabLines struct {
+	Begin int `json:"begin"`
+	End   int `json:"end,omitempty"`
+}
+
+// Format implements the Formatter interface for GitLab Code Quality output
+func (f *GitLabFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	issues := make([]GitLabIssue, 0, len(findings))
+	fingerprints := findingFingerprints(findings)
+	for i, finding := range findings {
+		issue := GitLabIssue{
+			Description: finding.Message,
+			CheckName:   finding.Rule,
+			Fingerprint: fingerprints[i],
+			Severity:    gitlabSeverity(finding.Severity),
+			Location: GitLabLocation{
+				Path:  relativePath(finding.File),
+				Lines: GitLabLines{Begin: max(finding.Location.Start.Line, 1)},
+			},
+		}
+		if end := finding.Location.End.Line; end > issue.Location.Lines.Begin {
+			issue.Location.Lines.End = end
+		}
+		issues = append(issues, issue)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(issues)
+}
+
+// gitlabSeverity converts SDK severity to a Code Quality severity
+func gitlabSeverity(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityError:
+		return "major"
+	case sdk.SeverityInfo:
+		return "info"
+	default:
+		return "minor"
+	}
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestGitLabFormatter(t *testing.T) {
+	finding := sdk.Finding{
+		Rule:     "policy.aws-s3-encryption",
+		Message:  "Bucket data is not encrypted",
+		File:     "main.tf",
+		Severity: sdk.SeverityError,
+		Location: hcl.Range{
+			Start: hcl.Pos{Line: 3, Column: 1},
+			End:   hcl.Pos{Line: 8, Column: 2},
+		},
+	}
+	moved := finding
+	moved.Location.Start.Line, moved.Location.End.Line = 13, 18
+	warning := sdk.Finding{
+		Rule:     "lint.terraform-required-version",
+		Message:  "Missing required_version",
+		File:     "versions.tf",
+		Severity: sdk.SeverityWarning,
+	}
+
+	format := func(findings ...sdk.Finding) []GitLabIssue {
+		t.Helper()
+		var buf bytes.Buffer
+		if err := (&GitLabFormatter{}).Format(findings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var issues []GitLabIssue
+		if err := json.Unmarshal(buf.Bytes(), &issues); err != nil {
+			t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+		}
+		return issues
+	}
+
+	issues := format(finding, finding, warning)
+	if len(issues) != 3 {
+		t.Fatalf("got %d issues, want 3", len(issues))
+	}
+
+	first := issues[0]
+	if first.CheckName != "policy.aws-s3-encryption" || first.Severity != "major" ||
+		first.Location.Path != "main.tf" || first.Location.Lines.Begin != 3 || first.Location.Lines.End != 8 {
+		t.Errorf("issue = %+v", first)
+	}
+	if issues[2].Severity != "minor" || issues[2].Location.Lines.Begin != 1 {
+		t.Errorf("file-level issue = %+v", issues[2])
+	}
+
+	if first.Fingerprint == issues[1].Fingerprint {
+		t.Error("identical findings must have distinct fingerprints")
+	}
+	if got := format(moved)[0].Fingerprint; got != first.Fingerprint {
+		t.Error("fingerprint should not change when the finding moves")
+	}
+
+	if empty := format(); empty == nil || len(empty) != 0 {
+		t.Errorf("no findings should give an empty array, got %v", empty)
+	}
+}
@@ -0,0 +1,373 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// htmlContextLines is the number of source lines shown around a finding
+const htmlContextLines = 2
+
+// htmlMaxSnippetLines bounds the snippet of findings spanning many lines
+const htmlMaxSnippetLines = 12
+
+//go:embed html/report.html
+var htmlReportTemplate string
+
+// htmlReport is the parsed report template
+var htmlReport = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// HTMLFormatter outputs findings as a self-contained HTML report: one file
+// with inline styles and scripts, to attach as a CI artifact. Findings can be
+// filtered by engine, rule, severity and directory in the browser and show the
+// highlighted source around them. Given rule metadata, the catalogue of rules
+// with findings carries their descriptions; given a baseline, the report
+// compares the run with it.
+type HTMLFormatter struct {
+	Title    string
+	Version  string
+	Rules    map[string]RuleDescriptor // Rule metadata for the catalogue
+	Baseline *Baseline                 // Previous result; nil omits the trend
+}
+
+// htmlData is the data of the report template
+type htmlData struct {
+	Title     string
+	Version   string
+	Generated string
+	Summary   JSONSummary
+	Fixable   int
+	Engines   []htmlEngine
+	Trend     *htmlTrend
+	Files     []htmlFile
+	Rules     []htmlRule
+	Filters   htmlFilters
+}
+
+// htmlEngine counts the findings of an engine by severity
+type htmlEngine struct {
+	Name                   string
+	Errors, Warnings, Info int
+}
+
+// htmlTrend compares the findings with the baseline
+type htmlTrend struct {
+	Previous, New, Fixed, Existing int
+	FixedFindings                  []htmlFinding
+}
+
+// htmlFile holds the findings of a file
+type htmlFile struct {
+	Name     string
+	Findings []htmlFinding
+}
+
+// htmlFinding is a finding with its filter keys and source snippet
+type htmlFinding struct {
+	sdk.Finding
+	Engine   string
+	Path     string // File relative to the working directory
+	Dir      string // Directory of Path, the key of the directory filter
+	Position string
+	New      bool
+	Snippet  []htmlLine
+}
+
+// htmlLine is a highlighted source line of a snippet
+type htmlLine struct {
+	Number int
+	Code   template.HTML
+	Marked bool // Within the finding's range
+}
+
+// htmlRule is an entry of the rule catalogue
+type htmlRule struct {
+	ID     string
+	Engine string
+	Count  int
+	RuleDescriptor
+}
+
+// htmlFilters are the values offered by the filters
+type htmlFilters struct {
+	Engines, Rules, Severities, Dirs []string
+}
+
+// Format implements the Formatter interface for HTML output
+func (f *HTMLFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	title := f.Title
+	if title == "" {
+		title = "TerraTidy Report"
+	}
+	data := htmlData{
+		Title:     title,
+		Version:   f.Version,
+		Generated: time.Now().UTC().Format("2006-01-02 15:04 UTC"),
+		Summary:   JSONSummary{Total: len(findings)},
+	}
+
+	var isNew []bool
+	if f.Baseline != nil {
+		isNew = f.Baseline.IsNew(findings)
+		fixed := f.Baseline.Fixed(findings)
+		data.Trend = &htmlTrend{Previous: len(f.Baseline.Findings), Fixed: len(fixed)}
+		for _, n := range isNew {
+			if n {
+				data.Trend.New++
+			}
+		}
+		data.Trend.Existing = len(findings) - data.Trend.New
+		for _, finding := range fixed {
+			data.Trend.FixedFindings = append(data.Trend.FixedFindings, htmlFinding{
+				Finding:  finding,
+				Path:     relativePath(finding.File),
+				Position: htmlPosition(finding),
+			})
+		}
+	}
+
+	hl := &htmlHighlighter{sources: make(map[string][]string)}
+	engines := make(map[string]*htmlEngine)
+	rules := make(map[string]*htmlRule)
+	byFile := make(map[string][]htmlFinding)
+	dirs := make(map[string]bool)
+	severities := make(map[string]bool)
+	for i, finding := range findings {
+		engine := findingEngine(finding.Rule)
+		if engines[engine] == nil {
+			engines[engine] = &htmlEngine{Name: engine}
+		}
+		switch finding.Severity {
+		case sdk.SeverityError:
+			data.Summary.Errors++
+			engines[engine].Errors++
+		case sdk.SeverityWarning:
+			data.Summary.Warnings++
+			engines[engine].Warnings++
+		default:
+			data.Summary.Info++
+			engines[engine].Info++
+		}
+		if finding.Fixable {
+			data.Fixable++
+		}
+
+		if rules[finding.Rule] == nil {
+			rules[finding.Rule] = &htmlRule{ID: finding.Rule, Engine: engine, RuleDescriptor: f.Rules[finding.Rule]}
+		}
+		rules[finding.Rule].Count++
+
+		dir := "(no file)"
+		if finding.File != "" {
+			dir = path.Dir(relativePath(finding.File))
+		}
+		dirs[dir] = true
+		severities[string(finding.Severity)] = true
+
+		byFile[finding.File] = append(byFile[finding.File], htmlFinding{
+			Finding:  finding,
+			Engine:   engine,
+			Path:     relativePath(finding.File),
+			Dir:      dir,
+			Position: htmlPosition(finding),
+			New:      isNew != nil && isNew[i],
+			Snippet:  hl.snippet(finding),
+		})
+	}
+
+	for _, engine := range sortedKeys(engines) {
+		data.Engines = append(data.Engines, *engines[engine])
+	}
+	for _, rule := range sortedKeys(rules) {
+		data.Rules = append(data.Rules, *rules[rule])
+	}
+	data.Filters = htmlFilters{
+		Engines: sortedKeys(engines),
+		Rules:   sortedKeys(rules),
+		Dirs:    sortedKeys(dirs),
+	}
+	for _, severity := range []sdk.Severity{sdk.SeverityError, sdk.SeverityWarning, sdk.SeverityInfo} {
+		if severities[string(severity)] {
+			data.Filters.Severities = append(data.Filters.Severities, string(severity))
+		}
+	}
+
+	// Files in name order, file-less findings last
+	files := sortedKeys(byFile)
+	if len(files) > 0 && files[0] == "" {
+		files = append(files[1:], "")
+	}
+	for _, file := range files {
+		fileFindings := byFile[file]
+		sort.SliceStable(fileFindings, func(i, j int) bool {
+			a, b := fileFindings[i].Location.Start, fileFindings[j].Location.Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+		name := "(no file)"
+		if file != "" {
+			name = relativePath(file)
+		}
+		data.Files = append(data.Files, htmlFile{Name: name, Findings: fileFindings})
+	}
+
+	if err := htmlReport.Execute(w, data); err != nil {
+		return fmt.Errorf("rendering HTML report: %w", err)
+	}
+	return nil
+}
+
+// htmlPosition returns the line and column of a finding, or "" without one
+func htmlPosition(finding sdk.Finding) string {
+	start := finding.Location.Start
+	if start.Line <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", start.Line, start.Column)
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// htmlHighlighter reads source files and renders highlighted snippets of them
+type htmlHighlighter struct {
+	sources map[string][]string // Lines of each file, already highlighted
+}
+
+// snippet returns the highlighted lines around a finding, or nil when its
+// source cannot be read
+func (h *htmlHighlighter) snippet(finding sdk.Finding) []htmlLine {
+	start, end := finding.Location.Start.Line, finding.Location.End.Line
+	if finding.File == "" || start <= 0 {
+		return nil
+	}
+	lines, ok := h.sources[finding.File]
+	if !ok {
+		if content, err := os.ReadFile(finding.File); err == nil && utf8.Valid(content) {
+			lines = highlightHCL(finding.File, content)
+		}
+		h.sources[finding.File] = lines
+	}
+	if start > len(lines) {
+		return nil
+	}
+
+	end = max(end, start)
+	from := max(start-htmlContextLines, 1)
+	to := min(end+htmlContextLines, len(lines), from+htmlMaxSnippetLines-1)
+	snippet := make([]htmlLine, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		snippet = append(snippet, htmlLine{
+			Number: n,
+			Code:   template.HTML(lines[n-1]), // Escaped by highlightHCL
+			Marked: n >= start && n <= end,
+		})
+	}
+	return snippet
+}
+
+// highlightHCL returns the lines of an HCL source as HTML, with comments,
+// strings, numbers, keywords and attribute names in spans of the classes
+// hl-c, hl-s, hl-n, hl-k and hl-a. JSON files are escaped only.
+func highlightHCL(file string, src []byte) []string {
+	src = []byte(strings.ReplaceAll(string(src), "\r\n", "\n"))
+	classes := make([]string, len(src))
+	if !strings.HasSuffix(file, ".json") {
+		tokens, _ := hclsyntax.LexConfig(src, file, hcl.Pos{Line: 1, Column: 1})
+		for i, token := range tokens {
+			class := tokenClass(tokens, i)
+			for b := token.Range.Start.Byte; b < token.Range.End.Byte && b < len(src); b++ {
+				classes[b] = class
+			}
+		}
+	}
+
+	var lines []string
+	var line strings.Builder
+	current := ""
+	flush := func() {
+		if current != "" {
+			line.WriteString("</span>")
+			current = ""
+		}
+	}
+	for i := 0; i < len(src); {
+		r, size := utf8.DecodeRune(src[i:])
+		if r == '\n' {
+			flush()
+			lines = append(lines, line.String())
+			line.Reset()
+			i += size
+			continue
+		}
+		if classes[i] != current {
+			flush()
+			if classes[i] != "" {
+				fmt.Fprintf(&line, `<span class="%s">`, classes[i])
+				current = classes[i]
+			}
+		}
+		line.WriteString(template.HTMLEscapeString(string(src[i : i+size])))
+		i += size
+	}
+	flush()
+	if line.Len() > 0 {
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+// tokenClass returns the highlight class of tokens[i], or ""
+func tokenClass(tokens hclsyntax.Tokens, i int) string {
+	switch tokens[i].Type {
+	case hclsyntax.TokenComment:
+		return "hl-c"
+	case hclsyntax.TokenOQuote, hclsyntax.TokenCQuote, hclsyntax.TokenQuotedLit,
+		hclsyntax.TokenOHeredoc, hclsyntax.TokenCHeredoc, hclsyntax.TokenStringLit:
+		return "hl-s"
+	case hclsyntax.TokenNumberLit:
+		return "hl-n"
+	case hclsyntax.TokenIdent:
+	default:
+		return ""
+	}
+
+	switch string(tokens[i].Bytes) {
+	case "true", "false", "null", "for", "in", "if":
+		return "hl-k"
+	}
+	// Identifiers starting a line are block types or attribute names. Line
+	// comments end with their newline.
+	if i > 0 {
+		switch tokens[i-1].Type {
+		case hclsyntax.TokenNewline, hclsyntax.TokenOBrace, hclsyntax.TokenComment:
+		default:
+			return ""
+		}
+	}
+	if i+1 < len(tokens) && tokens[i+1].Type == hclsyntax.TokenEqual {
+		return "hl-a"
+	}
+	return "hl-k"
+}
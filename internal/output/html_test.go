@@ -0,0 +1,122 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLFormatter_Report(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	tfFile := filepath.Join(dir, "modules", "app", "main.tf")
+	require.NoError(t, os.MkdirAll(filepath.Dir(tfFile), 0o755))
+	require.NoError(t, os.WriteFile(tfFile, []byte(`# Application bucket
+resource "aws_s3_bucket" "data" {
+  bucket = "app-data"
+  count  = 2
+}
+`), 0o644))
+
+	existing := sdk.Finding{
+		Rule:     "policy.aws-s3-encryption",
+		Message:  "Bucket data is not encrypted",
+		File:     tfFile,
+		Severity: sdk.SeverityError,
+		Location: hcl.Range{Start: hcl.Pos{Line: 2, Column: 1}, End: hcl.Pos{Line: 2, Column: 33}},
+	}
+	added := sdk.Finding{
+		Rule:     "style.for-each-count-first",
+		Message:  "count should come first",
+		File:     tfFile,
+		Severity: sdk.SeverityWarning,
+		Location: hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 4, Column: 12}},
+	}
+	fixed := sdk.Finding{
+		Rule:     "lint.terraform-required-version",
+		Message:  "Missing required_version",
+		File:     "versions.tf",
+		Severity: sdk.SeverityWarning,
+	}
+
+	f := &HTMLFormatter{
+		Title:   "Nightly",
+		Version: "1.2.3",
+		Rules: map[string]RuleDescriptor{
+			"policy.aws-s3-encryption": {
+				Title:       "S3 encryption",
+				Description: "Buckets must configure server-side encryption",
+				HelpURI:     "https://example.com/s3",
+				Severity:    sdk.SeverityError,
+			},
+		},
+		Baseline: &Baseline{Findings: []sdk.Finding{existing, fixed}},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, f.Format([]sdk.Finding{existing, added}, &buf))
+	out := buf.String()
+
+	for _, want := range []string{
+		"<title>Nightly</title>",
+		// Filter keys and options
+		`data-engine="policy" data-rule="policy.aws-s3-encryption" data-severity="error" data-dir="modules/app" data-new="false"`,
+		`data-engine="style" data-rule="style.for-each-count-first" data-severity="warning" data-dir="modules/app" data-new="true"`,
+		`<select data-filter="dir"><option value="">All</option><option>modules/app</option></select>`,
+		`<input type="checkbox" data-filter="new" value="true"> New only`,
+		// Highlighted snippet with the finding's lines marked
+		`<span class="line"><span class="ln">1</span><span class="hl-c"># Application bucket</span></span>`,
+		`<span class="line marked"><span class="ln">2</span><span class="hl-k">resource</span> ` +
+			`<span class="hl-s">&#34;aws_s3_bucket&#34;</span> <span class="hl-s">&#34;data&#34;</span> {</span>`,
+		`<span class="ln">4</span>  <span class="hl-a">count</span>  = <span class="hl-n">2</span>`,
+		// Trend against the baseline
+		`<div class="number">2</div>
+                <div class="label">Previous Run</div>`,
+		`<div class="number">1</div>
+                <div class="label">Fixed</div>`,
+		"<code>versions.tf</code> Missing required_version",
+		`<span class="badge badge-new">New</span>`,
+		// Rule catalogue
+		`<strong>S3 encryption</strong><br>Buckets must configure server-side encryption <a href="https://example.com/s3">Docs</a>`,
+		`data-rule-link="style.for-each-count-first"`,
+		"Generated by TerraTidy 1.2.3",
+	} {
+		assert.Contains(t, out, want)
+	}
+	assert.NotContains(t, out, "<link", "the report must not load external resources")
+}
+
+func TestHTMLFormatter_NoBaseline(t *testing.T) {
+	var buf bytes.Buffer
+	findings := []sdk.Finding{{Rule: "lint.a", Message: "no file", Severity: sdk.SeverityInfo}}
+	require.NoError(t, (&HTMLFormatter{}).Format(findings, &buf))
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>TerraTidy Report</title>")
+	assert.Contains(t, out, `<div class="file-header">(no file)</div>`)
+	assert.NotContains(t, out, "Previous Run")
+	assert.NotContains(t, out, `data-filter="new"`)
+}
+
+func TestHighlightHCL(t *testing.T) {
+	lines := highlightHCL("main.tf", []byte("locals {\n  x = <<EOT\n<b>\nEOT\n}\n"))
+	require.Len(t, lines, 5)
+	assert.Equal(t, `<span class="hl-k">locals</span> {`, lines[0])
+	assert.Equal(t, `<span class="hl-s">&lt;b&gt;</span>`, lines[2], "heredoc content is escaped")
+
+	lines = highlightHCL("main.tf.json", []byte(`{"a": "<b>"}`))
+	assert.Equal(t, []string{`{&#34;a&#34;: &#34;&lt;b&gt;&#34;}`}, lines)
+}
+
+func TestBaseline_Fixed(t *testing.T) {
+	kept := sdk.Finding{Rule: "lint.a", Message: "kept", File: "main.tf"}
+	gone := sdk.Finding{Rule: "lint.b", Message: "gone", File: "main.tf"}
+	baseline := &Baseline{Findings: []sdk.Finding{kept, gone}}
+
+	assert.Equal(t, []sdk.Finding{gone}, baseline.Fixed([]sdk.Finding{kept}))
+}
@@ -0,0 +1,125 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// JUnitFormatter outputs findings as JUnit XML for CI test reports. Each file
+// is a testsuite and each finding a failed testcase whose failure type is the
+// severity. Files listed in Files without findings are reported as a passing
+// testcase so that test trends cover every checked file.
+type JUnitFormatter struct {
+	Files []string // Checked files, including those without findings
+}
+
+// JUnitTestSuites is the root element of a JUnit report
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+// JUnitTestSuite holds the testcases of one file
+type JUnitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []JUnitTestCase `xml:"testcase"`
+}
+
+// JUnitTestCase is a finding, or a passing check of a clean file
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *JUnitFailure `xml:"failure,omitempty"`
+}
+
+// JUnitFailure describes the finding of a failed testcase
+type JUnitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// junitNoFile is the testsuite of findings that are not tied to a file
+const junitNoFile = "(no file)"
+
+// Format implements the Formatter interface for JUnit output
+func (f *JUnitFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	byFile := make(map[string][]sdk.Finding)
+	for _, file := range f.Files {
+		byFile[file] = nil
+	}
+	for _, finding := range findings {
+		file := finding.File
+		if file == "" {
+			file = junitNoFile
+		}
+		byFile[file] = append(byFile[file], finding)
+	}
+
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	report := JUnitTestSuites{Name: "TerraTidy"}
+	for _, file := range files {
+		suite := junitSuite(file, byFile[file])
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Suites = append(report.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("encoding JUnit XML: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// junitSuite builds the testsuite of a file from its findings.
+func junitSuite(file string, findings []sdk.Finding) JUnitTestSuite {
+	suite := JUnitTestSuite{Name: file}
+	if len(findings) == 0 {
+		suite.Tests = 1
+		suite.Cases = []JUnitTestCase{{Name: "no findings", ClassName: file}}
+		return suite
+	}
+
+	for _, finding := range findings {
+		name := finding.Rule
+		location := file
+		if line := finding.Location.Start.Line; line > 0 {
+			name = fmt.Sprintf("%s (%d:%d)", finding.Rule, line, finding.Location.Start.Column)
+			location = fmt.Sprintf("%s:%d:%d", file, line, finding.Location.Start.Column)
+		}
+		suite.Cases = append(suite.Cases, JUnitTestCase{
+			Name:      name,
+			ClassName: file,
+			Failure: &JUnitFailure{
+				Message: finding.Message,
+				Type:    string(finding.Severity),
+				Text:    fmt.Sprintf("%s: %s (%s)", location, finding.Message, finding.Rule),
+			},
+		})
+	}
+	suite.Tests = len(findings)
+	suite.Failures = len(findings)
+	return suite
+}
@@ -0,0 +1,97 @@
+package output
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestJUnitFormatter(t *testing.T) {
+	findings := []sdk.Finding{
+		{
+			Rule:     "style.blank-line-between-blocks",
+			Message:  "Missing blank line",
+			File:     "main.tf",
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{Start: hcl.Pos{Line: 12, Column: 1}},
+		},
+		{
+			Rule:     "policy.aws-s3-encryption",
+			Message:  "Bucket <data> is not encrypted",
+			File:     "main.tf",
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{Start: hcl.Pos{Line: 3, Column: 1}},
+		},
+		{
+			Rule:     "policy.required-modules",
+			Message:  "Missing network module",
+			Severity: sdk.SeverityInfo,
+		},
+	}
+
+	formatter := &JUnitFormatter{Files: []string{"variables.tf", "main.tf"}}
+	var buf bytes.Buffer
+	if err := formatter.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>`) {
+		t.Errorf("missing XML header:\n%s", buf.String())
+	}
+
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+
+	if report.Tests != 4 || report.Failures != 3 {
+		t.Errorf("tests = %d, failures = %d, want 4 and 3", report.Tests, report.Failures)
+	}
+
+	var names []string
+	for _, suite := range report.Suites {
+		names = append(names, suite.Name)
+	}
+	if got := strings.Join(names, ","); got != "(no file),main.tf,variables.tf" {
+		t.Errorf("suites = %s", got)
+	}
+
+	main := report.Suites[1]
+	if main.Tests != 2 || main.Failures != 2 {
+		t.Errorf("main.tf tests = %d, failures = %d", main.Tests, main.Failures)
+	}
+	tc := main.Cases[1]
+	if tc.Name != "policy.aws-s3-encryption (3:1)" || tc.ClassName != "main.tf" {
+		t.Errorf("testcase = %s (%s)", tc.Name, tc.ClassName)
+	}
+	if tc.Failure == nil || tc.Failure.Type != "error" || tc.Failure.Message != "Bucket <data> is not encrypted" {
+		t.Errorf("failure = %+v", tc.Failure)
+	}
+	if !strings.Contains(tc.Failure.Text, "main.tf:3:1") {
+		t.Errorf("failure text = %q", tc.Failure.Text)
+	}
+
+	clean := report.Suites[2]
+	if clean.Tests != 1 || clean.Failures != 0 || clean.Cases[0].Failure != nil {
+		t.Errorf("clean file should pass: %+v", clean)
+	}
+}
+
+func TestJUnitFormatter_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&JUnitFormatter{}).Format(nil, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var report JUnitTestSuites
+	if err := xml.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if report.Tests != 0 || len(report.Suites) != 0 {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+}
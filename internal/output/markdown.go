@@ -0,0 +1,229 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// markdownContextLines is the number of source lines shown around a finding
+const markdownContextLines = 2
+
+// markdownMaxSnippetLines bounds the snippet of findings spanning many lines,
+// which then show their first lines only
+const markdownMaxSnippetLines = 12
+
+// MarkdownFormatter outputs findings as a compact Markdown report for pull
+// request comments and GitHub job summaries ($GITHUB_STEP_SUMMARY). The report
+// has a summary table by engine and severity and a collapsible section per
+// file with the source around each finding. Given a baseline, new findings are
+// listed apart from existing ones.
+type MarkdownFormatter struct {
+	Title    string
+	Baseline *Baseline // Previous result; nil lists all findings together
+}
+
+// Format implements the Formatter interface for Markdown output
+func (f *MarkdownFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	var b strings.Builder
+	title := f.Title
+	if title == "" {
+		title = "TerraTidy Report"
+	}
+	fmt.Fprintf(&b, "## %s\n\n", title)
+
+	if len(findings) == 0 {
+		b.WriteString(":white_check_mark: No issues found\n")
+		_, err := io.WriteString(w, b.String())
+		return err
+	}
+
+	writeMarkdownSummary(&b, findings)
+
+	sources := make(map[string][]string)
+	if f.Baseline == nil {
+		writeMarkdownFiles(&b, findings, sources)
+	} else {
+		var added, existing []sdk.Finding
+		for i, isNew := range f.Baseline.IsNew(findings) {
+			if isNew {
+				added = append(added, findings[i])
+			} else {
+				existing = append(existing, findings[i])
+			}
+		}
+		fmt.Fprintf(&b, "**%d new**, %d existing compared to the baseline.\n\n", len(added), len(existing))
+		fmt.Fprintf(&b, "### New findings (%d)\n\n", len(added))
+		if len(added) == 0 {
+			b.WriteString("No new findings.\n\n")
+		}
+		writeMarkdownFiles(&b, added, sources)
+		if len(existing) > 0 {
+			fmt.Fprintf(&b, "### Existing findings (%d)\n\n", len(existing))
+			writeMarkdownFiles(&b, existing, sources)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeMarkdownSummary writes the totals and a table of findings by engine and
+// severity.
+func writeMarkdownSummary(b *strings.Builder, findings []sdk.Finding) {
+	type counts struct{ errors, warnings, info int }
+	add := func(c *counts, severity sdk.Severity) {
+		switch severity {
+		case sdk.SeverityError:
+			c.errors++
+		case sdk.SeverityWarning:
+			c.warnings++
+		default:
+			c.info++
+		}
+	}
+
+	var total counts
+	byEngine := make(map[string]*counts)
+	for _, finding := range findings {
+		engine := findingEngine(finding.Rule)
+		if byEngine[engine] == nil {
+			byEngine[engine] = &counts{}
+		}
+		add(byEngine[engine], finding.Severity)
+		add(&total, finding.Severity)
+	}
+
+	fmt.Fprintf(b, "**%d issue(s)**: %d error(s), %d warning(s), %d info\n\n",
+		len(findings), total.errors, total.warnings, total.info)
+
+	engines := make([]string, 0, len(byEngine))
+	for engine := range byEngine {
+		engines = append(engines, engine)
+	}
+	sort.Strings(engines)
+
+	b.WriteString("| Engine | :x: Errors | :warning: Warnings | :information_source: Info |\n")
+	b.WriteString("|--------|-----------:|-------------------:|--------------------------:|\n")
+	for _, engine := range engines {
+		c := byEngine[engine]
+		fmt.Fprintf(b, "| %s | %d | %d | %d |\n", engine, c.errors, c.warnings, c.info)
+	}
+	fmt.Fprintf(b, "| **Total** | **%d** | **%d** | **%d** |\n\n", total.errors, total.warnings, total.info)
+}
+
+// writeMarkdownFiles writes a collapsible section per file. sources caches
+// the lines of files read for snippets.
+func writeMarkdownFiles(b *strings.Builder, findings []sdk.Finding, sources map[string][]string) {
+	byFile := make(map[string][]sdk.Finding)
+	for _, finding := range findings {
+		byFile[finding.File] = append(byFile[finding.File], finding)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		fileFindings := byFile[file]
+		sort.SliceStable(fileFindings, func(i, j int) bool {
+			return fileFindings[i].Location.Start.Line < fileFindings[j].Location.Start.Line
+		})
+
+		name := "(no file)"
+		if file != "" {
+			name = relativePath(file)
+		}
+		fmt.Fprintf(b, "<details>\n<summary><code>%s</code> (%d)</summary>\n\n", escapeHTML(name), len(fileFindings))
+
+		for _, finding := range fileFindings {
+			location := ""
+			if line := finding.Location.Start.Line; line > 0 {
+				location = fmt.Sprintf(" line %d", line)
+			}
+			fmt.Fprintf(b, "- %s **%s**%s: %s `%s`\n",
+				markdownIcon(finding.Severity), finding.Severity, location,
+				markdownEscape(finding.Message), finding.Rule)
+			writeMarkdownSnippet(b, finding, sources)
+		}
+		b.WriteString("\n</details>\n\n")
+	}
+}
+
+// writeMarkdownSnippet writes the source lines around a finding as an
+// indented code block of its list item.
+func writeMarkdownSnippet(b *strings.Builder, finding sdk.Finding, sources map[string][]string) {
+	start, end := finding.Location.Start.Line, finding.Location.End.Line
+	if finding.File == "" || start <= 0 {
+		return
+	}
+	lines, ok := sources[finding.File]
+	if !ok {
+		if content, err := os.ReadFile(finding.File); err == nil {
+			lines = strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+		}
+		sources[finding.File] = lines
+	}
+	if start > len(lines) {
+		return
+	}
+
+	end = max(end, start)
+	from := max(start-markdownContextLines, 1)
+	to := min(end+markdownContextLines, len(lines), from+markdownMaxSnippetLines-1)
+	width := len(fmt.Sprint(to))
+
+	fmt.Fprintf(b, "\n  ```%s\n", markdownLanguage(finding.File))
+	for n := from; n <= to; n++ {
+		marker := " "
+		if n >= start && n <= end {
+			marker = ">"
+		}
+		fmt.Fprintf(b, "  %s %*d | %s\n", marker, width, n, lines[n-1])
+	}
+	b.WriteString("  ```\n")
+}
+
+// markdownIcon returns the emoji shortcode of a severity
+func markdownIcon(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityError:
+		return ":x:"
+	case sdk.SeverityWarning:
+		return ":warning:"
+	default:
+		return ":information_source:"
+	}
+}
+
+// markdownLanguage returns the code block language of a file
+func markdownLanguage(file string) string {
+	if strings.HasSuffix(file, ".json") {
+		return "json"
+	}
+	if filepath.Ext(file) == ".tf" || filepath.Ext(file) == ".tfvars" {
+		return "terraform"
+	}
+	return "hcl"
+}
+
+// markdownEscape escapes characters with a meaning in Markdown or HTML
+func markdownEscape(s string) string {
+	return strings.NewReplacer(
+		"\\", "\\\\",
+		"`", "\\`",
+		"*", "\\*",
+		"_", "\\_",
+		"[", "\\[",
+		"]", "\\]",
+		"<", "&lt;",
+		">", "&gt;",
+		"\n", " ",
+	).Replace(s)
+}
@@ -0,0 +1,138 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestMarkdownFormatter(t *testing.T) {
+	dir := t.TempDir()
+	tfFile := filepath.Join(dir, "main.tf")
+	source := "terraform {}\n\nresource \"aws_s3_bucket\" \"data\" {\n  acl = \"public-read\"\n}\n\nvariable \"x\" {}\n"
+	if err := os.WriteFile(tfFile, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := []sdk.Finding{
+		{
+			Rule:     "policy.aws-s3-public-acl",
+			Message:  "S3 bucket data uses a public ACL <public-read>",
+			File:     tfFile,
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 4, Column: 22}},
+		},
+		{
+			Rule:     "lint.terraform-documented-variables",
+			Message:  "Variable 'x' is missing a description",
+			File:     tfFile,
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{Start: hcl.Pos{Line: 7, Column: 1}, End: hcl.Pos{Line: 7, Column: 15}},
+		},
+		{
+			Rule:     "policy.required-modules",
+			Message:  "Missing network module",
+			Severity: sdk.SeverityInfo,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{Title: "Report"}).Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"## Report\n",
+		"**3 issue(s)**: 1 error(s), 1 warning(s), 1 info",
+		"| lint | 0 | 1 | 0 |",
+		"| policy | 1 | 0 | 1 |",
+		"| **Total** | **1** | **1** | **1** |",
+		"<summary><code>(no file)</code> (1)</summary>",
+		"- :x: **error** line 4: S3 bucket data uses a public ACL &lt;public-read&gt; `policy.aws-s3-public-acl`",
+		"  ```terraform\n    2 | \n    3 | resource \"aws_s3_bucket\" \"data\" {\n  > 4 |   acl = \"public-read\"\n    5 | }\n    6 | \n  ```",
+		"  > 7 | variable \"x\" {}\n  ```",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "New findings") {
+		t.Error("no new/existing split without a baseline")
+	}
+}
+
+func TestMarkdownFormatter_Baseline(t *testing.T) {
+	existing := sdk.Finding{
+		Rule:     "lint.terraform-required-version",
+		Message:  "Missing required_version",
+		File:     "versions.tf",
+		Severity: sdk.SeverityWarning,
+		Location: hcl.Range{Start: hcl.Pos{Line: 1, Column: 1}},
+	}
+	added := sdk.Finding{
+		Rule:     "policy.aws-s3-encryption",
+		Message:  "Bucket data is not encrypted",
+		File:     "main.tf",
+		Severity: sdk.SeverityError,
+	}
+
+	// The baseline is a previous JSON report, in which the finding was on another line
+	previous := existing
+	previous.Location.Start.Line = 10
+	var report bytes.Buffer
+	if err := (&JSONFormatter{}).Format([]sdk.Finding{previous}, &report); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(file, report.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := LoadBaseline(file)
+	if err != nil {
+		t.Fatalf("LoadBaseline() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{Baseline: baseline}).Format([]sdk.Finding{existing, added}, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	out := buf.String()
+
+	newAt := strings.Index(out, "### New findings (1)")
+	existingAt := strings.Index(out, "### Existing findings (1)")
+	if newAt < 0 || existingAt < newAt {
+		t.Fatalf("missing new/existing sections:\n%s", out)
+	}
+	if i := strings.Index(out, "<code>main.tf</code>"); i < newAt || i > existingAt {
+		t.Errorf("new finding not in the new section:\n%s", out)
+	}
+	if i := strings.Index(out, "<code>versions.tf</code>"); i < existingAt {
+		t.Errorf("moved finding not in the existing section:\n%s", out)
+	}
+}
+
+func TestMarkdownFormatter_NoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	if err := (&MarkdownFormatter{}).Format(nil, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	if buf.String() != "## TerraTidy Report\n\n:white_check_mark: No issues found\n" {
+		t.Errorf("output = %q", buf.String())
+	}
+}
+
+func TestLoadBaseline_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(file, []byte("not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBaseline(file); err == nil || !strings.Contains(err.Error(), "parsing baseline") {
+		t.Errorf("LoadBaseline() error = %v", err)
+	}
+}
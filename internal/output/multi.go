@@ -0,0 +1,139 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// Target is a report of a run: a formatter and the file it writes to
+type Target struct {
+	Format    string // Format name, used in errors
+	Path      string // File to write; empty or "-" for the writer given to Start
+	Formatter Formatter
+}
+
+// MultiFormatter fans findings out to several targets so that one run writes
+// several reports, e.g. text on the terminal, SARIF for code scanning and JUnit
+// for test reports. Streaming formatters receive findings as they are written;
+// the others format every finding on Finish.
+type MultiFormatter struct {
+	Targets []Target
+
+	writers  []io.Writer
+	files    []*os.File
+	findings []sdk.Finding
+}
+
+// Start opens the target files, creating their directories, and starts the
+// streaming targets. Targets without a path write to w.
+func (m *MultiFormatter) Start(w io.Writer) error {
+	m.writers = make([]io.Writer, len(m.Targets))
+	m.files = nil
+	m.findings = nil
+
+	for i, target := range m.Targets {
+		m.writers[i] = w
+		if target.Path != "" && target.Path != "-" {
+			file, err := createReportFile(target.Path)
+			if err != nil {
+				m.closeFiles()
+				return fmt.Errorf("writing %s output: %w", target.Format, err)
+			}
+			m.files = append(m.files, file)
+			m.writers[i] = file
+		}
+
+		if stream, ok := target.Formatter.(StreamFormatter); ok {
+			if err := stream.Start(m.writers[i]); err != nil {
+				m.closeFiles()
+				return m.targetError(target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteFindings passes a batch of findings to the streaming targets and keeps
+// it for the others
+func (m *MultiFormatter) WriteFindings(findings []sdk.Finding) error {
+	m.findings = append(m.findings, findings...)
+	for _, target := range m.Targets {
+		if stream, ok := target.Formatter.(StreamFormatter); ok {
+			if err := stream.WriteFindings(findings); err != nil {
+				return m.targetError(target, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Finish writes the reports of the non-streaming targets, completes the
+// streaming ones and closes the files. Every target is attempted; the errors
+// are returned together.
+func (m *MultiFormatter) Finish() error {
+	var errs []error
+	for i, target := range m.Targets {
+		var err error
+		if stream, ok := target.Formatter.(StreamFormatter); ok {
+			err = stream.Finish()
+		} else {
+			err = target.Formatter.Format(m.findings, m.writers[i])
+		}
+		if err != nil {
+			errs = append(errs, m.targetError(target, err))
+		}
+	}
+	for _, file := range m.files {
+		if err := file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("closing %s: %w", file.Name(), err))
+		}
+	}
+	m.files = nil
+	return errors.Join(errs...)
+}
+
+// Format implements the Formatter interface, writing every target at once
+func (m *MultiFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	if err := m.Start(w); err != nil {
+		return err
+	}
+	if err := m.WriteFindings(findings); err != nil {
+		m.closeFiles()
+		return err
+	}
+	return m.Finish()
+}
+
+// targetError wraps an error with the format and file of a target
+func (m *MultiFormatter) targetError(target Target, err error) error {
+	if target.Path == "" || target.Path == "-" {
+		return fmt.Errorf("writing %s output: %w", target.Format, err)
+	}
+	return fmt.Errorf("writing %s output to %s: %w", target.Format, target.Path, err)
+}
+
+func (m *MultiFormatter) closeFiles() {
+	for _, file := range m.files {
+		_ = file.Close()
+	}
+	m.files = nil
+}
+
+// createReportFile creates a report file and its parent directories
+func createReportFile(path string) (*os.File, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("creating %s: %w", dir, err)
+		}
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s: %w", path, err)
+	}
+	return file, nil
+}
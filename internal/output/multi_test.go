@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiFormatter(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "reports", "results.json")
+	ndjsonPath := filepath.Join(dir, "findings.ndjson")
+
+	m := &MultiFormatter{Targets: []Target{
+		{Format: "text", Formatter: &TextFormatter{NoColor: true}},
+		{Format: "json", Path: jsonPath, Formatter: &JSONFormatter{}},
+		{Format: "ndjson", Path: ndjsonPath, Formatter: &NDJSONFormatter{}},
+	}}
+
+	var stdout bytes.Buffer
+	require.NoError(t, m.Start(&stdout))
+
+	first := []sdk.Finding{{Rule: "style.a", Message: "first", File: "main.tf", Severity: sdk.SeverityWarning}}
+	require.NoError(t, m.WriteFindings(first))
+
+	// Streaming targets are written as findings arrive, the others on Finish
+	streamed, err := os.ReadFile(ndjsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(streamed), "\n"))
+	assert.Empty(t, stdout.String())
+
+	second := []sdk.Finding{{Rule: "lint.b", Message: "second", File: "main.tf", Severity: sdk.SeverityError}}
+	require.NoError(t, m.WriteFindings(second))
+	require.NoError(t, m.Finish())
+
+	assert.Contains(t, stdout.String(), "✗ 2 issue(s): 1 error(s), 1 warning(s), 0 info")
+
+	content, err := os.ReadFile(jsonPath)
+	require.NoError(t, err)
+	var report JSONOutput
+	require.NoError(t, json.Unmarshal(content, &report))
+	assert.Equal(t, 2, report.Summary.Total)
+
+	streamed, err = os.ReadFile(ndjsonPath)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(streamed), "\n"))
+}
+
+func TestMultiFormatter_Errors(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o644))
+
+	m := &MultiFormatter{Targets: []Target{
+		{Format: "sarif", Path: filepath.Join(blocker, "results.sarif"), Formatter: &SARIFFormatter{}},
+	}}
+	err := m.Format(nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "writing sarif output")
+
+	// A failing target does not keep the others from being written
+	jsonPath := filepath.Join(dir, "results.json")
+	m = &MultiFormatter{Targets: []Target{
+		{Format: "template", Formatter: &TemplateFormatter{}},
+		{Format: "json", Path: jsonPath, Formatter: &JSONFormatter{}},
+	}}
+	err = m.Format(nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "writing template output: template format requires a template file")
+	assert.FileExists(t, jsonPath)
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// NDJSONFormatter outputs findings as newline-delimited JSON: one finding per
+// line, in the same form as the findings of the JSON output. It streams, so
+// consumers can process findings while the run is in progress.
+type NDJSONFormatter struct {
+	encoder *json.Encoder
+}
+
+// Format implements the Formatter interface for NDJSON output
+func (f *NDJSONFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	if err := f.Start(w); err != nil {
+		return err
+	}
+	if err := f.WriteFindings(findings); err != nil {
+		return err
+	}
+	return f.Finish()
+}
+
+// Start implements the StreamFormatter interface
+func (f *NDJSONFormatter) Start(w io.Writer) error {
+	f.encoder = json.NewEncoder(w)
+	return nil
+}
+
+// WriteFindings implements the StreamFormatter interface
+func (f *NDJSONFormatter) WriteFindings(findings []sdk.Finding) error {
+	if f.encoder == nil {
+		return errors.New("ndjson: WriteFindings called before Start")
+	}
+	for _, finding := range findings {
+		if err := f.encoder.Encode(jsonFinding(finding)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Finish implements the StreamFormatter interface
+func (f *NDJSONFormatter) Finish() error {
+	f.encoder = nil
+	return nil
+}
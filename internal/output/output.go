@@ -1,9 +1,13 @@
 // Package output provides formatters for TerraTidy findings.
-// It supports multiple output formats including text, JSON, SARIF, and HTML
-// for displaying analysis results to users.
+// It supports multiple output formats including text, JSON, NDJSON, SARIF, HTML,
+// Markdown, JUnit, Checkstyle, reviewdog's rdjson, the native annotation
+// formats of GitHub Actions and GitLab and user-defined Go templates for
+// displaying analysis results to users.
 package output
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -17,49 +21,24 @@ type Formatter interface {
 	Format(findings []sdk.Finding, w io.Writer) error
 }
 
-// TextFormatter outputs findings in human-readable text format
-type TextFormatter struct {
-	Verbose bool
+// StreamFormatter is a formatter that can write findings incrementally, as
+// engines produce them, instead of buffering the whole report
+type StreamFormatter interface {
+	Formatter
+	// Start begins a report on w
+	Start(w io.Writer) error
+	// WriteFindings writes a batch of findings
+	WriteFindings(findings []sdk.Finding) error
+	// Finish completes the report
+	Finish() error
 }
 
-// Format implements the Formatter interface for text output
-func (f *TextFormatter) Format(findings []sdk.Finding, w io.Writer) error {
-	if len(findings) == 0 {
-		_, _ = fmt.Fprintln(w, "✓ No issues found")
-		return nil
-	}
-
-	for _, finding := range findings {
-		icon := "ℹ"
-		switch finding.Severity {
-		case sdk.SeverityError:
-			icon = "✗"
-		case sdk.SeverityWarning:
-			icon = "⚠"
-		case sdk.SeverityInfo:
-			icon = "ℹ"
-		}
-
-		if f.Verbose {
-			_, _ = fmt.Fprintf(w, "%s %s:%d:%d: %s (%s)\n",
-				icon,
-				finding.File,
-				finding.Location.Start.Line,
-				finding.Location.Start.Column,
-				finding.Message,
-				finding.Rule,
-			)
-		} else {
-			_, _ = fmt.Fprintf(w, "%s %s: %s (%s)\n",
-				icon,
-				finding.File,
-				finding.Message,
-				finding.Rule,
-			)
-		}
-	}
-
-	return nil
+// RuleDescriptor describes a rule for formats that carry rule metadata
+type RuleDescriptor struct {
+	Title       string       // Short description
+	Description string       // Full description
+	HelpURI     string       // Documentation of the rule
+	Severity    sdk.Severity // Default severity
 }
 
 // JSONFormatter outputs findings in JSON format
@@ -67,10 +46,15 @@ type JSONFormatter struct {
 	Pretty bool
 }
 
+// JSONSchemaVersion is the version of the JSON and NDJSON output, described by
+// schema/output.schema.json. It changes only on incompatible changes.
+const JSONSchemaVersion = 1
+
 // JSONOutput represents the JSON output structure
 type JSONOutput struct {
-	Findings []JSONFinding `json:"findings"`
-	Summary  JSONSummary   `json:"summary"`
+	SchemaVersion int           `json:"schema_version"`
+	Findings      []JSONFinding `json:"findings"`
+	Summary       JSONSummary   `json:"summary"`
 }
 
 // JSONFinding represents a single finding in JSON format
@@ -81,6 +65,19 @@ type JSONFinding struct {
 	Location JSONLocation `json:"location"`
 	Severity string       `json:"severity"`
 	Fixable  bool         `json:"fixable"`
+	Fix      *JSONFix     `json:"fix,omitempty"`
+}
+
+// JSONFix represents the edits that resolve a finding in JSON format
+type JSONFix struct {
+	Description string     `json:"description,omitempty"`
+	Edits       []JSONEdit `json:"edits"`
+}
+
+// JSONEdit represents a single text replacement in JSON format
+type JSONEdit struct {
+	Location JSONLocation `json:"location"`
+	NewText  string       `json:"new_text"`
 }
 
 // JSONLocation represents a location in JSON format
@@ -106,30 +103,15 @@ type JSONSummary struct {
 // Format implements the Formatter interface for JSON output
 func (f *JSONFormatter) Format(findings []sdk.Finding, w io.Writer) error {
 	output := JSONOutput{
-		Findings: make([]JSONFinding, 0, len(findings)),
+		SchemaVersion: JSONSchemaVersion,
+		Findings:      make([]JSONFinding, 0, len(findings)),
 		Summary: JSONSummary{
 			Total: len(findings),
 		},
 	}
 
 	for _, finding := range findings {
-		output.Findings = append(output.Findings, JSONFinding{
-			Rule:    finding.Rule,
-			Message: finding.Message,
-			File:    finding.File,
-			Location: JSONLocation{
-				Start: JSONPosition{
-					Line:   finding.Location.Start.Line,
-					Column: finding.Location.Start.Column,
-				},
-				End: JSONPosition{
-					Line:   finding.Location.End.Line,
-					Column: finding.Location.End.Column,
-				},
-			},
-			Severity: string(finding.Severity),
-			Fixable:  finding.Fixable,
-		})
+		output.Findings = append(output.Findings, jsonFinding(finding))
 
 		// Count by severity
 		switch finding.Severity {
@@ -150,6 +132,50 @@ func (f *JSONFormatter) Format(findings []sdk.Finding, w io.Writer) error {
 	return encoder.Encode(output)
 }
 
+// jsonFinding converts a finding to its JSON representation
+func jsonFinding(finding sdk.Finding) JSONFinding {
+	return JSONFinding{
+		Rule:    finding.Rule,
+		Message: finding.Message,
+		File:    finding.File,
+		Location: JSONLocation{
+			Start: JSONPosition{
+				Line:   finding.Location.Start.Line,
+				Column: finding.Location.Start.Column,
+			},
+			End: JSONPosition{
+				Line:   finding.Location.End.Line,
+				Column: finding.Location.End.Column,
+			},
+		},
+		Severity: string(finding.Severity),
+		Fixable:  finding.Fixable,
+		Fix:      jsonFix(finding.Fix),
+	}
+}
+
+// jsonFix converts a finding's fix data to its JSON representation
+func jsonFix(fix *sdk.Fix) *JSONFix {
+	if fix == nil {
+		return nil
+	}
+
+	out := &JSONFix{
+		Description: fix.Description,
+		Edits:       make([]JSONEdit, 0, len(fix.Edits)),
+	}
+	for _, edit := range fix.Edits {
+		out.Edits = append(out.Edits, JSONEdit{
+			Location: JSONLocation{
+				Start: JSONPosition{Line: edit.Range.Start.Line, Column: edit.Range.Start.Column},
+				End:   JSONPosition{Line: edit.Range.End.Line, Column: edit.Range.End.Column},
+			},
+			NewText: edit.NewText,
+		})
+	}
+	return out
+}
+
 // GetFormatter returns the appropriate formatter based on the format string
 func GetFormatter(format string, verbose bool, version string) (Formatter, error) {
 	switch format {
@@ -159,277 +185,53 @@ func GetFormatter(format string, verbose bool, version string) (Formatter, error
 		return &JSONFormatter{Pretty: true}, nil
 	case "json-compact":
 		return &JSONFormatter{Pretty: false}, nil
+	case "ndjson":
+		return &NDJSONFormatter{}, nil
 	case "sarif":
 		return &SARIFFormatter{Version: version}, nil
 	case "html":
 		return &HTMLFormatter{Title: "TerraTidy Report", Version: version}, nil
+	case "junit":
+		return &JUnitFormatter{}, nil
+	case "github":
+		return &GitHubFormatter{}, nil
+	case "gitlab":
+		return &GitLabFormatter{}, nil
+	case "checkstyle":
+		return &CheckstyleFormatter{}, nil
+	case "rdjson":
+		return &RDJSONFormatter{}, nil
+	case "markdown", "md":
+		return &MarkdownFormatter{Title: "TerraTidy Report"}, nil
+	case "template":
+		return &TemplateFormatter{Version: version}, nil
 	default:
 		return nil, fmt.Errorf("unsupported output format: %s", format)
 	}
 }
 
-// HTMLFormatter outputs findings as an HTML report
-type HTMLFormatter struct {
-	Title   string
-	Version string
-}
-
-// Format implements the Formatter interface for HTML output
-func (f *HTMLFormatter) Format(findings []sdk.Finding, w io.Writer) error {
-	// Count by severity
-	var errors, warnings, info int
-	for _, finding := range findings {
-		switch finding.Severity {
-		case sdk.SeverityError:
-			errors++
-		case sdk.SeverityWarning:
-			warnings++
-		case sdk.SeverityInfo:
-			info++
-		}
-	}
-
-	// Group findings by file
-	byFile := make(map[string][]sdk.Finding)
-	for _, finding := range findings {
-		byFile[finding.File] = append(byFile[finding.File], finding)
-	}
-
-	// Generate HTML
-	html := f.generateHTML(findings, byFile, errors, warnings, info)
-	_, err := w.Write([]byte(html))
-	return err
-}
-
-func (f *HTMLFormatter) generateHTML(
-	findings []sdk.Finding,
-	byFile map[string][]sdk.Finding,
-	errors, warnings, info int,
-) string {
-	total := len(findings)
-	return fmt.Sprintf(`<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>%s</title>
-    <style>%s</style>
-</head>
-<body>
-    <div class="container">
-        <h1>%s</h1>
-        %s
-        %s
-        <footer>Generated by TerraTidy %s</footer>
-    </div>
-</body>
-</html>`,
-		f.Title,
-		htmlStyles(),
-		f.Title,
-		f.generateSummary(total, errors, warnings, info),
-		f.generateFindings(findings, byFile),
-		f.Version,
-	)
-}
-
-func htmlStyles() string {
-	return `:root {
-            --error-color: #dc3545;
-            --warning-color: #ffc107;
-            --info-color: #17a2b8;
-            --success-color: #28a745;
-            --bg-color: #f8f9fa;
-            --card-bg: #ffffff;
-            --text-color: #212529;
-            --border-color: #dee2e6;
-        }
-        * { box-sizing: border-box; margin: 0; padding: 0; }
-        body {
-            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif;
-            background: var(--bg-color);
-            color: var(--text-color);
-            line-height: 1.6;
-            padding: 2rem;
-        }
-        .container { max-width: 1200px; margin: 0 auto; }
-        h1 { margin-bottom: 1rem; color: #2c3e50; }
-        .summary {
-            display: grid;
-            grid-template-columns: repeat(auto-fit, minmax(150px, 1fr));
-            gap: 1rem;
-            margin-bottom: 2rem;
-        }
-        .summary-card {
-            background: var(--card-bg);
-            border-radius: 8px;
-            padding: 1rem;
-            text-align: center;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-        }
-        .summary-card.error { border-left: 4px solid var(--error-color); }
-        .summary-card.warning { border-left: 4px solid var(--warning-color); }
-        .summary-card.info { border-left: 4px solid var(--info-color); }
-        .summary-card.total { border-left: 4px solid #6c757d; }
-        .summary-card .number { font-size: 2rem; font-weight: bold; }
-        .summary-card .label { font-size: 0.875rem; color: #6c757d; }
-        .file-section {
-            background: var(--card-bg);
-            border-radius: 8px;
-            margin-bottom: 1rem;
-            box-shadow: 0 2px 4px rgba(0,0,0,0.1);
-            overflow: hidden;
-        }
-        .file-header {
-            background: #2c3e50;
-            color: white;
-            padding: 0.75rem 1rem;
-            font-family: monospace;
-            font-size: 0.9rem;
-        }
-        .finding {
-            padding: 1rem;
-            border-bottom: 1px solid var(--border-color);
-            display: flex;
-            align-items: flex-start;
-            gap: 1rem;
-        }
-        .finding:last-child { border-bottom: none; }
-        .finding-icon {
-            width: 24px;
-            height: 24px;
-            border-radius: 50%;
-            display: flex;
-            align-items: center;
-            justify-content: center;
-            font-weight: bold;
-            color: white;
-            flex-shrink: 0;
-        }
-        .finding-icon.error { background: var(--error-color); }
-        .finding-icon.warning { background: var(--warning-color); color: #212529; }
-        .finding-icon.info { background: var(--info-color); }
-        .finding-content { flex: 1; }
-        .finding-message { margin-bottom: 0.25rem; }
-        .finding-meta {
-            font-size: 0.8rem;
-            color: #6c757d;
-            font-family: monospace;
-        }
-        .finding-rule { color: #6c757d; }
-        .finding-location { margin-left: 1rem; }
-        .badge {
-            display: inline-block;
-            padding: 0.2rem 0.5rem;
-            border-radius: 4px;
-            font-size: 0.75rem;
-            font-weight: 500;
-        }
-        .badge-fixable { background: #d4edda; color: #155724; }
-        .no-issues {
-            text-align: center;
-            padding: 3rem;
-            color: var(--success-color);
-        }
-        .no-issues svg { width: 64px; height: 64px; margin-bottom: 1rem; }
-        footer {
-            text-align: center;
-            margin-top: 2rem;
-            color: #6c757d;
-            font-size: 0.875rem;
-        }`
-}
-
-func (f *HTMLFormatter) generateSummary(total, errors, warnings, info int) string {
-	return fmt.Sprintf(`
-        <div class="summary">
-            <div class="summary-card total">
-                <div class="number">%d</div>
-                <div class="label">Total Issues</div>
-            </div>
-            <div class="summary-card error">
-                <div class="number">%d</div>
-                <div class="label">Errors</div>
-            </div>
-            <div class="summary-card warning">
-                <div class="number">%d</div>
-                <div class="label">Warnings</div>
-            </div>
-            <div class="summary-card info">
-                <div class="number">%d</div>
-                <div class="label">Info</div>
-            </div>
-        </div>`, total, errors, warnings, info)
-}
-
-func (f *HTMLFormatter) generateFindings(findings []sdk.Finding, byFile map[string][]sdk.Finding) string {
-	if len(findings) == 0 {
-		return `
-        <div class="no-issues">
-            <svg viewBox="0 0 24 24" fill="currentColor">
-                <path d="M9 16.17L4.83 12l-1.42 1.41L9 19 21 7l-1.41-1.41z"/>
-            </svg>
-            <h2>All checks passed!</h2>
-            <p>No issues found in your Terraform code.</p>
-        </div>`
-	}
-
-	var sections string
-	for file, fileFindings := range byFile {
-		sections += f.generateFileSection(file, fileFindings)
-	}
-	return sections
-}
-
-func (f *HTMLFormatter) generateFileSection(file string, findings []sdk.Finding) string {
-	var findingsHTML string
-	for _, finding := range findings {
-		findingsHTML += f.generateFindingHTML(finding)
+// findingEngine returns the engine of a rule from its prefix, e.g. policy
+func findingEngine(rule string) string {
+	if engine, _, ok := strings.Cut(rule, "."); ok {
+		return engine
 	}
-
-	return fmt.Sprintf(`
-        <div class="file-section">
-            <div class="file-header">%s</div>
-            %s
-        </div>`, escapeHTML(file), findingsHTML)
-}
-
-func (f *HTMLFormatter) generateFindingHTML(finding sdk.Finding) string {
-	iconClass := "info"
-	iconSymbol := "i"
-	switch finding.Severity {
-	case sdk.SeverityError:
-		iconClass = "error"
-		iconSymbol = "!"
-	case sdk.SeverityWarning:
-		iconClass = "warning"
-		iconSymbol = "!"
+	return "other"
+}
+
+// findingFingerprints returns a stable fingerprint for each finding, derived
+// from its rule, path and message. The line is left out so that a finding is
+// still recognised when code above it moves; identical findings in a file are
+// told apart by their order.
+func findingFingerprints(findings []sdk.Finding) []string {
+	fingerprints := make([]string, len(findings))
+	seen := make(map[string]int)
+	for i, finding := range findings {
+		key := finding.Rule + "\x00" + relativePath(finding.File) + "\x00" + finding.Message
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d", key, seen[key])))
+		fingerprints[i] = hex.EncodeToString(sum[:16])
+		seen[key]++
 	}
-
-	fixableBadge := ""
-	if finding.Fixable {
-		fixableBadge = `<span class="badge badge-fixable">Fixable</span>`
-	}
-
-	return fmt.Sprintf(`
-            <div class="finding">
-                <div class="finding-icon %s">%s</div>
-                <div class="finding-content">
-                    <div class="finding-message">%s %s</div>
-                    <div class="finding-meta">
-                        <span class="finding-rule">%s</span>
-                        <span class="finding-location">Line %d, Column %d</span>
-                    </div>
-                </div>
-            </div>`,
-		iconClass,
-		iconSymbol,
-		escapeHTML(finding.Message),
-		fixableBadge,
-		escapeHTML(finding.Rule),
-		finding.Location.Start.Line,
-		finding.Location.Start.Column,
-	)
+	return fingerprints
 }
 
 // escapeHTML escapes special HTML characters
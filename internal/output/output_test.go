@@ -39,7 +39,16 @@ func TestTextFormatter(t *testing.T) {
 				},
 			},
 			verbose: false,
-			want:    "✗ test.tf: Test error message (test.error)\n",
+			want: "test.tf\n" +
+				"  error   1:1     Test error message (test.error)\n" +
+				"\n" +
+				"ENGINE  ERRORS  WARNINGS  INFO\n" +
+				"test         1         0     0\n" +
+				"\n" +
+				"RULE        COUNT\n" +
+				"test.error  1\n" +
+				"\n" +
+				"✗ 1 issue(s): 1 error(s), 0 warning(s), 0 info\n",
 		},
 		{
 			name: "multiple findings",
@@ -64,7 +73,97 @@ func TestTextFormatter(t *testing.T) {
 				},
 			},
 			verbose: false,
-			want:    "✗ test.tf: Error (test.error)\n⚠ test.tf: Warning (test.warning)\nℹ test.tf: Info (test.info)\n",
+			want: "test.tf\n" +
+				"  error           Error (test.error)\n" +
+				"  warning         Warning (test.warning)\n" +
+				"  info            Info (test.info)\n" +
+				"\n" +
+				"ENGINE  ERRORS  WARNINGS  INFO\n" +
+				"test         1         1     1\n" +
+				"\n" +
+				"RULE          COUNT\n" +
+				"test.error    1\n" +
+				"test.info     1\n" +
+				"test.warning  1\n" +
+				"\n" +
+				"✗ 3 issue(s): 1 error(s), 1 warning(s), 1 info\n",
+		},
+		{
+			name: "grouped by file",
+			findings: []sdk.Finding{
+				{
+					Rule:     "lint.b",
+					Message:  "Second file",
+					File:     "b.tf",
+					Severity: sdk.SeverityWarning,
+					Location: hcl.Range{Start: hcl.Pos{Line: 3, Column: 5}},
+				},
+				{
+					Rule:     "style.a",
+					Message:  "First file",
+					File:     "a.tf",
+					Severity: sdk.SeverityError,
+					Location: hcl.Range{Start: hcl.Pos{Line: 12, Column: 1}},
+				},
+			},
+			verbose: false,
+			want: "a.tf\n" +
+				"  error   12:1    First file (style.a)\n" +
+				"\n" +
+				"b.tf\n" +
+				"  warning 3:5     Second file (lint.b)\n" +
+				"\n" +
+				"ENGINE  ERRORS  WARNINGS  INFO\n" +
+				"lint         0         1     0\n" +
+				"style        1         0     0\n" +
+				"\n" +
+				"RULE     COUNT\n" +
+				"lint.b   1\n" +
+				"style.a  1\n" +
+				"\n" +
+				"✗ 2 issue(s): 1 error(s), 1 warning(s), 0 info\n",
+		},
+		{
+			name: "findings without file, verbose",
+			findings: []sdk.Finding{
+				{
+					Rule:     "test.error",
+					Message:  "Error",
+					File:     "test.tf",
+					Severity: sdk.SeverityError,
+				},
+				{
+					Rule:     "test.warning",
+					Message:  "Warning",
+					File:     "test.tf",
+					Severity: sdk.SeverityWarning,
+				},
+				{
+					Rule:     "other.info",
+					Message:  "Info",
+					Severity: sdk.SeverityInfo,
+					Fixable:  true,
+				},
+			},
+			verbose: true,
+			want: "test.tf\n" +
+				"  error           Error (test.error)\n" +
+				"  warning         Warning (test.warning)\n" +
+				"\n" +
+				"(no file)\n" +
+				"  info            Info (other.info)\n" +
+				"          automatically fixable\n" +
+				"\n" +
+				"ENGINE  ERRORS  WARNINGS  INFO\n" +
+				"other        0         0     1\n" +
+				"test         1         1     0\n" +
+				"\n" +
+				"RULE          COUNT\n" +
+				"other.info    1\n" +
+				"test.error    1\n" +
+				"test.warning  1\n" +
+				"\n" +
+				"✗ 3 issue(s): 1 error(s), 1 warning(s), 1 info\n",
 		},
 	}
 
@@ -223,6 +322,13 @@ func TestGetFormatter(t *testing.T) {
 			wantErr:  false,
 			wantType: "*output.JSONFormatter",
 		},
+		{
+			name:     "ndjson format",
+			format:   "ndjson",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.NDJSONFormatter",
+		},
 		{
 			name:     "sarif format",
 			format:   "sarif",
@@ -237,6 +343,55 @@ func TestGetFormatter(t *testing.T) {
 			wantErr:  false,
 			wantType: "*output.HTMLFormatter",
 		},
+		{
+			name:     "junit format",
+			format:   "junit",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.JUnitFormatter",
+		},
+		{
+			name:     "github format",
+			format:   "github",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.GitHubFormatter",
+		},
+		{
+			name:     "gitlab format",
+			format:   "gitlab",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.GitLabFormatter",
+		},
+		{
+			name:     "checkstyle format",
+			format:   "checkstyle",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.CheckstyleFormatter",
+		},
+		{
+			name:     "rdjson format",
+			format:   "rdjson",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.RDJSONFormatter",
+		},
+		{
+			name:     "markdown format",
+			format:   "markdown",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.MarkdownFormatter",
+		},
+		{
+			name:     "template format",
+			format:   "template",
+			verbose:  false,
+			wantErr:  false,
+			wantType: "*output.TemplateFormatter",
+		},
 		{
 			name:     "empty format (defaults to text)",
 			format:   "",
@@ -517,6 +672,9 @@ func TestHTMLFormatter(t *testing.T) {
 				if !strings.Contains(output, "&lt;script&gt;") {
 					t.Error("Output should contain escaped script tag")
 				}
+				if strings.Count(output, "<script") != 1 {
+					t.Error("Output should only contain the report's own script")
+				}
 			}
 
 			// Verify fixable badge appears for fixable findings
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// RDJSONFormatter outputs findings in reviewdog's Diagnostic Format (rdjson).
+// Fix data of findings becomes suggestions, which reviewdog posts as
+// suggested changes on the pull request.
+type RDJSONFormatter struct{}
+
+// RDJSON is the root of a reviewdog diagnostic result
+type RDJSON struct {
+	Source      RDJSONSource       `json:"source"`
+	Diagnostics []RDJSONDiagnostic `json:"diagnostics"`
+}
+
+// RDJSONSource identifies the tool that produced the diagnostics
+type RDJSONSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// RDJSONDiagnostic is a single finding
+type RDJSONDiagnostic struct {
+	Message     string             `json:"message"`
+	Location    RDJSONLocation     `json:"location"`
+	Severity    string             `json:"severity"`
+	Code        RDJSONCode         `json:"code"`
+	Suggestions []RDJSONSuggestion `json:"suggestions,omitempty"`
+}
+
+// RDJSONLocation is the file and range of a diagnostic
+type RDJSONLocation struct {
+	Path  string       `json:"path"`
+	Range *RDJSONRange `json:"range,omitempty"`
+}
+
+// RDJSONRange is a range of lines and columns, both 1-based
+type RDJSONRange struct {
+	Start RDJSONPosition  `json:"start"`
+	End   *RDJSONPosition `json:"end,omitempty"`
+}
+
+// RDJSONPosition is a position in a file
+type RDJSONPosition struct {
+	Line   int `json:"line"`
+	Column int `json:"column,omitempty"`
+}
+
+// RDJSONCode is the rule that produced a diagnostic
+type RDJSONCode struct {
+	Value string `json:"value"`
+}
+
+// RDJSONSuggestion replaces a range of the file with Text
+type RDJSONSuggestion struct {
+	Range RDJSONRange `json:"range"`
+	Text  string      `json:"text"`
+}
+
+// Format implements the Formatter interface for rdjson output
+func (f *RDJSONFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	result := RDJSON{
+		Source:      RDJSONSource{Name: "terratidy", URL: "https://github.com/santosr2/terratidy"},
+		Diagnostics: make([]RDJSONDiagnostic, 0, len(findings)),
+	}
+	for _, finding := range findings {
+		diagnostic := RDJSONDiagnostic{
+			Message:  finding.Message,
+			Location: RDJSONLocation{Path: relativePath(finding.File), Range: rdjsonRange(finding.Location)},
+			Severity: rdjsonSeverity(finding.Severity),
+			Code:     RDJSONCode{Value: finding.Rule},
+		}
+		if finding.Fix != nil {
+			for _, edit := range finding.Fix.Edits {
+				// Suggestions apply to the diagnostic's file only
+				if edit.Range.Filename != "" && edit.Range.Filename != finding.File {
+					continue
+				}
+				if r := rdjsonRange(edit.Range); r != nil {
+					diagnostic.Suggestions = append(diagnostic.Suggestions, RDJSONSuggestion{Range: *r, Text: edit.NewText})
+				}
+			}
+		}
+		result.Diagnostics = append(result.Diagnostics, diagnostic)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// rdjsonRange converts a source range, or returns nil for findings that are
+// not tied to a line
+func rdjsonRange(r hcl.Range) *RDJSONRange {
+	if r.Start.Line <= 0 {
+		return nil
+	}
+	out := &RDJSONRange{Start: RDJSONPosition{Line: r.Start.Line, Column: r.Start.Column}}
+	if r.End.Line > 0 {
+		out.End = &RDJSONPosition{Line: r.End.Line, Column: r.End.Column}
+	}
+	return out
+}
+
+// rdjsonSeverity converts SDK severity to an rdjson severity
+func rdjsonSeverity(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityError:
+		return "ERROR"
+	case sdk.SeverityInfo:
+		return "INFO"
+	default:
+		return "WARNING"
+	}
+}
@@ -0,0 +1,78 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestRDJSONFormatter(t *testing.T) {
+	findings := []sdk.Finding{
+		{
+			Rule:     "style.attribute-alignment",
+			Message:  "Attributes are not aligned",
+			File:     "main.tf",
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{
+				Filename: "main.tf",
+				Start:    hcl.Pos{Line: 4, Column: 3},
+				End:      hcl.Pos{Line: 5, Column: 20},
+			},
+			Fixable: true,
+			Fix: &sdk.Fix{
+				Description: "Align attributes",
+				Edits: []sdk.TextEdit{
+					{
+						Range: hcl.Range{
+							Filename: "main.tf",
+							Start:    hcl.Pos{Line: 4, Column: 3},
+							End:      hcl.Pos{Line: 4, Column: 10},
+						},
+						NewText: "name  =",
+					},
+					{
+						Range:   hcl.Range{Filename: "other.tf", Start: hcl.Pos{Line: 1, Column: 1}},
+						NewText: "ignored",
+					},
+				},
+			},
+		},
+		{
+			Rule:     "policy.required-modules",
+			Message:  "Missing network module",
+			Severity: sdk.SeverityError,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := (&RDJSONFormatter{}).Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	var result RDJSON
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if result.Source.Name != "terratidy" || len(result.Diagnostics) != 2 {
+		t.Fatalf("result = %+v", result)
+	}
+
+	d := result.Diagnostics[0]
+	if d.Severity != "WARNING" || d.Code.Value != "style.attribute-alignment" || d.Location.Path != "main.tf" {
+		t.Errorf("diagnostic = %+v", d)
+	}
+	if r := d.Location.Range; r == nil || r.Start != (RDJSONPosition{Line: 4, Column: 3}) ||
+		r.End == nil || *r.End != (RDJSONPosition{Line: 5, Column: 20}) {
+		t.Errorf("range = %+v", r)
+	}
+	if len(d.Suggestions) != 1 || d.Suggestions[0].Text != "name  =" || d.Suggestions[0].Range.End.Column != 10 {
+		t.Errorf("suggestions = %+v", d.Suggestions)
+	}
+
+	if d := result.Diagnostics[1]; d.Severity != "ERROR" || d.Location.Range != nil {
+		t.Errorf("diagnostic without location = %+v", d)
+	}
+}
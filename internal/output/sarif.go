@@ -4,14 +4,27 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/url"
+	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/santosr2/terratidy/pkg/sdk"
 )
 
+// sarifSrcRoot is the URI base ID that result paths are relative to
+const sarifSrcRoot = "%SRCROOT%"
+
+// sarifFingerprint is the partialFingerprints key of TerraTidy's
+// line-independent finding fingerprint
+const sarifFingerprint = "terratidyFindingHash/v1"
+
 // SARIFFormatter outputs findings in SARIF format for GitHub Code Scanning
 type SARIFFormatter struct {
-	Version string // TerraTidy version
+	Version  string                    // TerraTidy version
+	Rules    map[string]RuleDescriptor // Rule metadata by rule ID, for the rule descriptors
+	ExitCode int                       // Exit status of the run, reported in invocations
 }
 
 // SARIF represents the root SARIF document
@@ -23,8 +36,10 @@ type SARIF struct {
 
 // SARIFRun represents a single run of the tool
 type SARIFRun struct {
-	Tool    SARIFTool     `json:"tool"`
-	Results []SARIFResult `json:"results"`
+	Tool               SARIFTool                        `json:"tool"`
+	Invocations        []SARIFInvocation                `json:"invocations,omitempty"`
+	OriginalURIBaseIDs map[string]SARIFArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []SARIFResult                    `json:"results"`
 }
 
 // SARIFTool represents the tool information
@@ -40,13 +55,25 @@ type SARIFDriver struct {
 	Rules          []SARIFRule `json:"rules,omitempty"`
 }
 
+// SARIFInvocation describes how the run ended
+type SARIFInvocation struct {
+	ExecutionSuccessful bool `json:"executionSuccessful"`
+	ExitCode            int  `json:"exitCode"`
+}
+
 // SARIFRule represents a rule definition
 type SARIFRule struct {
-	ID               string              `json:"id"`
-	ShortDescription SARIFMessage        `json:"shortDescription"`
-	FullDescription  SARIFMessage        `json:"fullDescription,omitempty"`
-	HelpURI          string              `json:"helpUri,omitempty"`
-	Properties       SARIFRuleProperties `json:"properties,omitempty"`
+	ID                   string                    `json:"id"`
+	ShortDescription     SARIFMessage              `json:"shortDescription"`
+	FullDescription      *SARIFMessage             `json:"fullDescription,omitempty"`
+	HelpURI              string                    `json:"helpUri,omitempty"`
+	DefaultConfiguration *SARIFReportingDescriptor `json:"defaultConfiguration,omitempty"`
+	Properties           SARIFRuleProperties       `json:"properties,omitempty"`
+}
+
+// SARIFReportingDescriptor is the default configuration of a rule
+type SARIFReportingDescriptor struct {
+	Level string `json:"level"`
 }
 
 // SARIFRuleProperties represents rule properties
@@ -61,11 +88,12 @@ type SARIFMessage struct {
 
 // SARIFResult represents a single result/finding
 type SARIFResult struct {
-	RuleID    string          `json:"ruleId"`
-	Level     string          `json:"level"`
-	Message   SARIFMessage    `json:"message"`
-	Locations []SARIFLocation `json:"locations"`
-	Fixes     []SARIFFix      `json:"fixes,omitempty"`
+	RuleID              string            `json:"ruleId"`
+	Level               string            `json:"level"`
+	Message             SARIFMessage      `json:"message"`
+	Locations           []SARIFLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints,omitempty"`
+	Fixes               []SARIFFix        `json:"fixes,omitempty"`
 }
 
 // SARIFLocation represents a location in the source
@@ -76,7 +104,7 @@ type SARIFLocation struct {
 // SARIFPhysicalLocation represents a physical location
 type SARIFPhysicalLocation struct {
 	ArtifactLocation SARIFArtifactLocation `json:"artifactLocation"`
-	Region           SARIFRegion           `json:"region"`
+	Region           *SARIFRegion          `json:"region,omitempty"`
 }
 
 // SARIFArtifactLocation represents an artifact location
@@ -107,13 +135,18 @@ type SARIFArtifactChange struct {
 
 // SARIFReplacement represents a replacement
 type SARIFReplacement struct {
-	DeletedRegion   SARIFRegion  `json:"deletedRegion"`
-	InsertedContent SARIFMessage `json:"insertedContent,omitempty"`
+	DeletedRegion   SARIFRegion          `json:"deletedRegion"`
+	InsertedContent SARIFArtifactContent `json:"insertedContent"`
+}
+
+// SARIFArtifactContent is the text inserted by a replacement
+type SARIFArtifactContent struct {
+	Text string `json:"text"`
 }
 
 // Format implements the Formatter interface for SARIF output
 func (f *SARIFFormatter) Format(findings []sdk.Finding, w io.Writer) error {
-	rules := buildSARIFRules(findings)
+	rules := f.buildSARIFRules(findings)
 	results := buildSARIFResults(findings)
 	sarif := f.buildSARIFDocument(rules, results)
 
@@ -122,7 +155,9 @@ func (f *SARIFFormatter) Format(findings []sdk.Finding, w io.Writer) error {
 	return encoder.Encode(sarif)
 }
 
-func buildSARIFRules(findings []sdk.Finding) []SARIFRule {
+// buildSARIFRules returns a descriptor for each rule with findings, sorted by
+// ID, completed from the rule metadata when known.
+func (f *SARIFFormatter) buildSARIFRules(findings []sdk.Finding) []SARIFRule {
 	rulesMap := make(map[string]bool)
 	for _, finding := range findings {
 		rulesMap[finding.Rule] = true
@@ -130,7 +165,7 @@ func buildSARIFRules(findings []sdk.Finding) []SARIFRule {
 
 	var rules []SARIFRule
 	for ruleID := range rulesMap {
-		rules = append(rules, SARIFRule{
+		rule := SARIFRule{
 			ID: ruleID,
 			ShortDescription: SARIFMessage{
 				Text: ruleID,
@@ -138,15 +173,35 @@ func buildSARIFRules(findings []sdk.Finding) []SARIFRule {
 			Properties: SARIFRuleProperties{
 				Tags: []string{"terraform", "terragrunt", "quality"},
 			},
-		})
+		}
+		if engine, _, ok := strings.Cut(ruleID, "."); ok {
+			rule.Properties.Tags = append(rule.Properties.Tags, engine)
+		}
+
+		if meta, ok := f.Rules[ruleID]; ok {
+			if meta.Title != "" {
+				rule.ShortDescription.Text = meta.Title
+			}
+			if meta.Description != "" {
+				rule.FullDescription = &SARIFMessage{Text: meta.Description}
+			}
+			rule.HelpURI = meta.HelpURI
+			if meta.Severity != "" {
+				rule.DefaultConfiguration = &SARIFReportingDescriptor{Level: sarifLevel(meta.Severity)}
+			}
+		}
+		rules = append(rules, rule)
 	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
 	return rules
 }
 
 func buildSARIFResults(findings []sdk.Finding) []SARIFResult {
-	var results []SARIFResult
-	for _, finding := range findings {
+	results := make([]SARIFResult, 0, len(findings))
+	fingerprints := findingFingerprints(findings)
+	for i, finding := range findings {
 		result := buildSARIFResult(finding)
+		result.PartialFingerprints = map[string]string{sarifFingerprint: fingerprints[i]}
 		results = append(results, result)
 	}
 	return results
@@ -159,76 +214,125 @@ func buildSARIFResult(finding sdk.Finding) SARIFResult {
 		Message: SARIFMessage{
 			Text: finding.Message,
 		},
-		Locations: []SARIFLocation{
-			{
-				PhysicalLocation: SARIFPhysicalLocation{
-					ArtifactLocation: SARIFArtifactLocation{
-						URI:       filepath.ToSlash(finding.File),
-						URIBaseID: "%SRCROOT%",
-					},
-					Region: SARIFRegion{
-						StartLine:   finding.Location.Start.Line,
-						StartColumn: finding.Location.Start.Column,
-						EndLine:     finding.Location.End.Line,
-						EndColumn:   finding.Location.End.Column,
-					},
-				},
+		Locations: []SARIFLocation{},
+	}
+
+	if finding.File != "" {
+		result.Locations = append(result.Locations, SARIFLocation{
+			PhysicalLocation: SARIFPhysicalLocation{
+				ArtifactLocation: sarifArtifact(finding.File),
+				Region: sarifRegion(finding.Location.Start.Line, finding.Location.Start.Column,
+					finding.Location.End.Line, finding.Location.End.Column),
 			},
-		},
+		})
 	}
 
-	if finding.Fixable && finding.FixFunc != nil {
+	if finding.Fix != nil {
 		result.Fixes = buildSARIFFixes(finding)
 	}
 	return result
 }
 
+// buildSARIFFixes converts the fix data of a finding, with one artifact change
+// per edited file. Fixes available only as a function cannot be described.
 func buildSARIFFixes(finding sdk.Finding) []SARIFFix {
+	var changes []SARIFArtifactChange
+	byFile := make(map[string]int)
+	for _, edit := range finding.Fix.Edits {
+		file := edit.Range.Filename
+		if file == "" {
+			file = finding.File
+		}
+		region := sarifRegion(edit.Range.Start.Line, edit.Range.Start.Column, edit.Range.End.Line, edit.Range.End.Column)
+		if file == "" || region == nil {
+			continue
+		}
+
+		i, ok := byFile[file]
+		if !ok {
+			i = len(changes)
+			byFile[file] = i
+			changes = append(changes, SARIFArtifactChange{ArtifactLocation: sarifArtifact(file)})
+		}
+		changes[i].Replacements = append(changes[i].Replacements, SARIFReplacement{
+			DeletedRegion:   *region,
+			InsertedContent: SARIFArtifactContent{Text: edit.NewText},
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	description := finding.Fix.Description
+	if description == "" {
+		description = fmt.Sprintf("Auto-fix available for %s", finding.Rule)
+	}
 	return []SARIFFix{
 		{
-			Description: SARIFMessage{
-				Text: fmt.Sprintf("Auto-fix available for %s", finding.Rule),
-			},
-			ArtifactChanges: []SARIFArtifactChange{
-				{
-					ArtifactLocation: SARIFArtifactLocation{
-						URI:       filepath.ToSlash(finding.File),
-						URIBaseID: "%SRCROOT%",
-					},
-					Replacements: []SARIFReplacement{
-						{
-							DeletedRegion: SARIFRegion{
-								StartLine:   finding.Location.Start.Line,
-								StartColumn: finding.Location.Start.Column,
-								EndLine:     finding.Location.End.Line,
-								EndColumn:   finding.Location.End.Column,
-							},
-						},
-					},
-				},
-			},
+			Description:     SARIFMessage{Text: description},
+			ArtifactChanges: changes,
 		},
 	}
 }
 
 func (f *SARIFFormatter) buildSARIFDocument(rules []SARIFRule, results []SARIFResult) SARIF {
+	run := SARIFRun{
+		Tool: SARIFTool{
+			Driver: SARIFDriver{
+				Name:           "TerraTidy",
+				Version:        f.Version,
+				InformationURI: "https://github.com/santosr2/terratidy",
+				Rules:          rules,
+			},
+		},
+		Invocations: []SARIFInvocation{
+			{ExecutionSuccessful: true, ExitCode: f.ExitCode},
+		},
+		Results: results,
+	}
+	if wd, err := os.Getwd(); err == nil {
+		run.OriginalURIBaseIDs = map[string]SARIFArtifactLocation{
+			sarifSrcRoot: {URI: fileURI(wd) + "/"},
+		}
+	}
+
 	return SARIF{
 		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
 		Version: "2.1.0",
-		Runs: []SARIFRun{
-			{
-				Tool: SARIFTool{
-					Driver: SARIFDriver{
-						Name:           "TerraTidy",
-						Version:        f.Version,
-						InformationURI: "https://github.com/santosr2/terratidy",
-						Rules:          rules,
-					},
-				},
-				Results: results,
-			},
-		},
+		Runs:    []SARIFRun{run},
+	}
+}
+
+// sarifArtifact returns the location of a file: relative to %SRCROOT%, the
+// working directory, or an absolute file URI for files outside it.
+func sarifArtifact(file string) SARIFArtifactLocation {
+	rel := relativePath(file)
+	if filepath.IsAbs(filepath.FromSlash(rel)) {
+		return SARIFArtifactLocation{URI: fileURI(file)}
+	}
+	return SARIFArtifactLocation{URI: (&url.URL{Path: rel}).EscapedPath(), URIBaseID: sarifSrcRoot}
+}
+
+// sarifRegion returns a region, or nil when the range has no line.
+func sarifRegion(startLine, startColumn, endLine, endColumn int) *SARIFRegion {
+	if startLine <= 0 {
+		return nil
+	}
+	region := &SARIFRegion{StartLine: startLine, StartColumn: startColumn}
+	if endLine >= startLine {
+		region.EndLine = endLine
+		region.EndColumn = endColumn
+	}
+	return region
+}
+
+// fileURI returns the file URI of an absolute path
+func fileURI(path string) string {
+	p := filepath.ToSlash(path)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p // Windows drive paths
 	}
+	return (&url.URL{Scheme: "file", Path: p}).String()
 }
 
 // sarifLevel converts SDK severity to SARIF level
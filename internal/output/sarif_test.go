@@ -0,0 +1,163 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// formatSARIF formats findings and returns the single run of the document
+func formatSARIF(t *testing.T, f *SARIFFormatter, findings []sdk.Finding) SARIFRun {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := f.Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	var sarif SARIF
+	if err := json.Unmarshal(buf.Bytes(), &sarif); err != nil {
+		t.Fatalf("invalid SARIF JSON: %v", err)
+	}
+	if len(sarif.Runs) != 1 {
+		t.Fatalf("len(Runs) = %d, want 1", len(sarif.Runs))
+	}
+	return sarif.Runs[0]
+}
+
+func TestSARIFFormatter_Fixes(t *testing.T) {
+	finding := sdk.Finding{
+		Rule:     "style.attribute-alignment",
+		Message:  "Attributes are not aligned",
+		File:     "main.tf",
+		Severity: sdk.SeverityWarning,
+		Location: hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 5, Column: 20}},
+		Fixable:  true,
+		Fix: &sdk.Fix{
+			Description: "Align attributes",
+			Edits: []sdk.TextEdit{
+				{
+					Range:   hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 4, Column: 10}},
+					NewText: "name  =",
+				},
+				{
+					Range:   hcl.Range{Filename: "versions.tf", Start: hcl.Pos{Line: 1, Column: 1}, End: hcl.Pos{Line: 1, Column: 1}},
+					NewText: "terraform {}\n",
+				},
+			},
+		},
+	}
+	functionOnly := finding
+	functionOnly.Fix = nil
+	functionOnly.FixFunc = func() ([]byte, error) { return nil, nil }
+
+	run := formatSARIF(t, &SARIFFormatter{}, []sdk.Finding{finding, functionOnly})
+
+	fixes := run.Results[0].Fixes
+	if len(fixes) != 1 || fixes[0].Description.Text != "Align attributes" {
+		t.Fatalf("fixes = %+v", fixes)
+	}
+	changes := fixes[0].ArtifactChanges
+	if len(changes) != 2 || changes[0].ArtifactLocation.URI != "main.tf" || changes[1].ArtifactLocation.URI != "versions.tf" {
+		t.Fatalf("artifact changes = %+v", changes)
+	}
+	replacement := changes[0].Replacements[0]
+	if replacement.InsertedContent.Text != "name  =" ||
+		replacement.DeletedRegion != (SARIFRegion{StartLine: 4, StartColumn: 3, EndLine: 4, EndColumn: 10}) {
+		t.Errorf("replacement = %+v", replacement)
+	}
+
+	if run.Results[1].Fixes != nil {
+		t.Errorf("a fix without edit data must not be emitted: %+v", run.Results[1].Fixes)
+	}
+}
+
+func TestSARIFFormatter_Rules(t *testing.T) {
+	findings := []sdk.Finding{
+		{Rule: "policy.aws-s3-encryption", Message: "Bucket a is not encrypted", File: "main.tf", Severity: sdk.SeverityInfo},
+		{Rule: "lint.unknown", Message: "Unknown", File: "main.tf", Severity: sdk.SeverityWarning},
+	}
+	formatter := &SARIFFormatter{
+		Version: "1.0.0",
+		Rules: map[string]RuleDescriptor{
+			"policy.aws-s3-encryption": {
+				Title:       "S3 buckets are encrypted",
+				Description: "Every S3 bucket configures server-side encryption.",
+				HelpURI:     "https://example.com/s3",
+				Severity:    sdk.SeverityError,
+			},
+		},
+		ExitCode: 1,
+	}
+
+	run := formatSARIF(t, formatter, findings)
+
+	rules := run.Tool.Driver.Rules
+	if len(rules) != 2 || rules[0].ID != "lint.unknown" || rules[1].ID != "policy.aws-s3-encryption" {
+		t.Fatalf("rules = %+v", rules)
+	}
+	if rules[0].FullDescription != nil || rules[0].DefaultConfiguration != nil {
+		t.Errorf("rule without metadata = %+v", rules[0])
+	}
+	rule := rules[1]
+	if rule.ShortDescription.Text != "S3 buckets are encrypted" || rule.FullDescription == nil ||
+		rule.FullDescription.Text != "Every S3 bucket configures server-side encryption." ||
+		rule.HelpURI != "https://example.com/s3" || rule.DefaultConfiguration == nil ||
+		rule.DefaultConfiguration.Level != "error" {
+		t.Errorf("rule = %+v", rule)
+	}
+	// The result keeps its own (possibly overridden) level
+	if run.Results[0].Level != "note" {
+		t.Errorf("level = %s, want note", run.Results[0].Level)
+	}
+
+	if len(run.Invocations) != 1 || run.Invocations[0].ExitCode != 1 || !run.Invocations[0].ExecutionSuccessful {
+		t.Errorf("invocations = %+v", run.Invocations)
+	}
+}
+
+func TestSARIFFormatter_Locations(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	finding := sdk.Finding{
+		Rule:     "style.block-label-case",
+		Message:  "Use snake_case",
+		File:     filepath.Join(wd, "modules", "main.tf"),
+		Severity: sdk.SeverityWarning,
+		Location: hcl.Range{Start: hcl.Pos{Line: 3, Column: 1}, End: hcl.Pos{Line: 3, Column: 12}},
+	}
+	moved := finding
+	moved.Location.Start.Line, moved.Location.End.Line = 30, 30
+	repo := sdk.Finding{Rule: "policy.required-modules", Message: "Missing network module"}
+
+	run := formatSARIF(t, &SARIFFormatter{}, []sdk.Finding{finding, finding, repo})
+
+	base, ok := run.OriginalURIBaseIDs["%SRCROOT%"]
+	if !ok || base.URI != fileURI(wd)+"/" {
+		t.Errorf("originalUriBaseIds = %+v", run.OriginalURIBaseIDs)
+	}
+
+	location := run.Results[0].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "modules/main.tf" || location.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("artifact location = %+v", location.ArtifactLocation)
+	}
+	if location.Region == nil || *location.Region != (SARIFRegion{StartLine: 3, StartColumn: 1, EndLine: 3, EndColumn: 12}) {
+		t.Errorf("region = %+v", location.Region)
+	}
+	if len(run.Results[2].Locations) != 0 {
+		t.Errorf("finding without a file has locations: %+v", run.Results[2].Locations)
+	}
+
+	first := run.Results[0].PartialFingerprints["terratidyFindingHash/v1"]
+	if first == "" || first == run.Results[1].PartialFingerprints["terratidyFindingHash/v1"] {
+		t.Errorf("fingerprints = %v, %v", run.Results[0].PartialFingerprints, run.Results[1].PartialFingerprints)
+	}
+	if got := formatSARIF(t, &SARIFFormatter{}, []sdk.Finding{moved}).Results[0].PartialFingerprints; got["terratidyFindingHash/v1"] != first {
+		t.Error("fingerprint should not change when the finding moves")
+	}
+}
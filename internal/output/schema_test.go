@@ -0,0 +1,249 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// schemaFindings exercises every field of the JSON output.
+var schemaFindings = []sdk.Finding{
+	{
+		Rule:     "style.attribute-alignment",
+		Message:  "Attributes are not aligned",
+		File:     "main.tf",
+		Severity: sdk.SeverityWarning,
+		Location: hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 5, Column: 20}},
+		Fixable:  true,
+		Fix: &sdk.Fix{
+			Description: "Align attributes",
+			Edits: []sdk.TextEdit{{
+				Range:   hcl.Range{Start: hcl.Pos{Line: 4, Column: 3}, End: hcl.Pos{Line: 4, Column: 10}},
+				NewText: "name  =",
+			}},
+		},
+	},
+	{Rule: "policy.required-modules", Message: "Missing network module", Severity: sdk.SeverityError},
+	{Rule: "lint.terraform-required-version", Message: "Missing required_version", File: "versions.tf", Severity: sdk.SeverityInfo},
+}
+
+func loadOutputSchema(t *testing.T) map[string]any {
+	t.Helper()
+	content, err := os.ReadFile("schema/output.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(content, &schema); err != nil {
+		t.Fatalf("invalid schema: %v", err)
+	}
+	return schema
+}
+
+func TestJSONFormatter_Schema(t *testing.T) {
+	schema := loadOutputSchema(t)
+	for _, pretty := range []bool{true, false} {
+		var buf bytes.Buffer
+		if err := (&JSONFormatter{Pretty: pretty}).Format(schemaFindings, &buf); err != nil {
+			t.Fatalf("Format() error = %v", err)
+		}
+		var doc any
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatal(err)
+		}
+		if err := validateSchema(schema, schema, doc, "$"); err != nil {
+			t.Errorf("pretty=%v: %v", pretty, err)
+		}
+	}
+}
+
+func TestNDJSONFormatter_Schema(t *testing.T) {
+	schema := loadOutputSchema(t)
+	finding := schema["$defs"].(map[string]any)["finding"]
+
+	var buf bytes.Buffer
+	if err := (&NDJSONFormatter{}).Format(schemaFindings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	lines := 0
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var doc any
+		if err := json.Unmarshal(scanner.Bytes(), &doc); err != nil {
+			t.Fatalf("line %d is not JSON: %v", lines+1, err)
+		}
+		if err := validateSchema(schema, finding, doc, fmt.Sprintf("line %d", lines+1)); err != nil {
+			t.Error(err)
+		}
+		lines++
+	}
+	if lines != len(schemaFindings) {
+		t.Errorf("got %d lines, want %d", lines, len(schemaFindings))
+	}
+}
+
+func TestOutputSchema_RejectsInvalid(t *testing.T) {
+	schema := loadOutputSchema(t)
+	for name, doc := range map[string]string{
+		"missing field":   `{"schema_version":1,"findings":[],"summary":{"total":0,"errors":0,"warnings":0}}`,
+		"unknown field":   `{"schema_version":1,"findings":[],"summary":{"total":0,"errors":0,"warnings":0,"info":0},"extra":1}`,
+		"bad severity":    `{"schema_version":1,"findings":[{"rule":"a","message":"b","file":"","location":{"start":{"line":0,"column":0},"end":{"line":0,"column":0}},"severity":"fatal","fixable":false}],"summary":{"total":1,"errors":0,"warnings":0,"info":0}}`,
+		"wrong version":   `{"schema_version":2,"findings":[],"summary":{"total":0,"errors":0,"warnings":0,"info":0}}`,
+		"negative number": `{"schema_version":1,"findings":[],"summary":{"total":-1,"errors":0,"warnings":0,"info":0}}`,
+	} {
+		var v any
+		if err := json.Unmarshal([]byte(doc), &v); err != nil {
+			t.Fatal(err)
+		}
+		if validateSchema(schema, schema, v, "$") == nil {
+			t.Errorf("%s: document accepted", name)
+		}
+	}
+}
+
+func TestNDJSONFormatter_Stream(t *testing.T) {
+	var buf bytes.Buffer
+	f := &NDJSONFormatter{}
+	if err := f.WriteFindings(schemaFindings); err == nil {
+		t.Error("WriteFindings before Start should fail")
+	}
+
+	var _ StreamFormatter = f
+	if err := f.Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.WriteFindings(schemaFindings[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Errorf("first batch not written immediately: %d lines", got)
+	}
+	if err := f.WriteFindings(schemaFindings[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Finish(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != len(schemaFindings) {
+		t.Errorf("got %d lines, want %d", got, len(schemaFindings))
+	}
+}
+
+// validateSchema validates v against the subset of JSON Schema used by
+// schema/output.schema.json. Unknown keywords fail, so that the schema
+// cannot use a keyword this check would silently ignore.
+func validateSchema(root, schema any, v any, path string) error {
+	s, ok := schema.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: invalid schema %v", path, schema)
+	}
+
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		arg := s[key]
+		switch key {
+		case "$schema", "$id", "$defs", "title", "description":
+		case "$ref":
+			name, ok := strings.CutPrefix(arg.(string), "#/$defs/")
+			def, found := root.(map[string]any)["$defs"].(map[string]any)[name]
+			if !ok || !found {
+				return fmt.Errorf("%s: unresolved $ref %v", path, arg)
+			}
+			if err := validateSchema(root, def, v, path); err != nil {
+				return err
+			}
+		case "type":
+			if !schemaType(arg.(string), v) {
+				return fmt.Errorf("%s: %v is not of type %s", path, v, arg)
+			}
+		case "const":
+			if !reflect.DeepEqual(arg, v) {
+				return fmt.Errorf("%s: %v is not %v", path, v, arg)
+			}
+		case "enum":
+			found := false
+			for _, e := range arg.([]any) {
+				found = found || reflect.DeepEqual(e, v)
+			}
+			if !found {
+				return fmt.Errorf("%s: %v is not one of %v", path, v, arg)
+			}
+		case "minimum":
+			if n, ok := v.(float64); ok && n < arg.(float64) {
+				return fmt.Errorf("%s: %v is less than %v", path, v, arg)
+			}
+		case "required":
+			obj, _ := v.(map[string]any)
+			for _, name := range arg.([]any) {
+				if _, ok := obj[name.(string)]; !ok {
+					return fmt.Errorf("%s: missing %s", path, name)
+				}
+			}
+		case "properties":
+			obj, _ := v.(map[string]any)
+			for name, prop := range arg.(map[string]any) {
+				if value, ok := obj[name]; ok {
+					if err := validateSchema(root, prop, value, path+"."+name); err != nil {
+						return err
+					}
+				}
+			}
+		case "additionalProperties":
+			props, _ := s["properties"].(map[string]any)
+			obj, _ := v.(map[string]any)
+			for name := range obj {
+				if _, ok := props[name]; !ok && arg == false {
+					return fmt.Errorf("%s: unexpected property %s", path, name)
+				}
+			}
+		case "items":
+			arr, _ := v.([]any)
+			for i, item := range arr {
+				if err := validateSchema(root, arg, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fmt.Errorf("%s: unsupported schema keyword %s", path, key)
+		}
+	}
+	return nil
+}
+
+// schemaType reports whether v, decoded by encoding/json, is of a JSON Schema type
+func schemaType(typ string, v any) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == float64(int64(n))
+	default:
+		return false
+	}
+}
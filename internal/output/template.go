@@ -0,0 +1,244 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// TemplateFormatter renders findings with a Go text/template, for formats
+// that are not worth a formatter of their own such as chat messages, wiki
+// tables or CSV. The template is executed with a TemplateData.
+type TemplateFormatter struct {
+	Template *template.Template        // Parsed with ParseTemplateFile
+	Rules    map[string]RuleDescriptor // Rule metadata, exposed as .Rules
+	Version  string
+}
+
+// TemplateData is the data a template is executed with
+type TemplateData struct {
+	Findings []TemplateFinding
+	Summary  JSONSummary
+	Rules    map[string]RuleDescriptor // Rule metadata by rule ID
+	Run      TemplateRun
+}
+
+// TemplateFinding is a finding with the fields templates commonly need
+// precomputed. The sdk.Finding fields (Rule, Message, File, Severity, ...) are
+// available directly.
+type TemplateFinding struct {
+	sdk.Finding
+	Engine    string // Rule prefix, e.g. "style"
+	Path      string // File relative to the working directory
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+}
+
+// TemplateRun describes the run that produced the findings
+type TemplateRun struct {
+	Tool       string
+	Version    string
+	Time       time.Time
+	WorkingDir string
+}
+
+// TemplateGroup is a group of findings sharing a key, as returned by groupBy
+type TemplateGroup struct {
+	Key      string
+	Findings []TemplateFinding
+}
+
+// templateFuncs are the helper functions available to templates:
+//
+//	groupBy "file|path|rule|engine|severity" findings  groups in key order
+//	severity "error" findings                          findings of a severity
+//	minSeverity "warning" findings                     findings of a severity or worse
+//	relPath file                                       path relative to the working directory
+//	json value, csv fields..., mdEscape s              escaping for the target format
+//	upper, lower, replace, join, repeat                string helpers
+var templateFuncs = template.FuncMap{
+	"groupBy":     templateGroupBy,
+	"severity":    templateSeverity,
+	"minSeverity": templateMinSeverity,
+	"relPath":     relativePath,
+	"json":        templateJSON,
+	"csv":         templateCSV,
+	"mdEscape":    markdownEscape,
+	"upper":       strings.ToUpper,
+	"lower":       strings.ToLower,
+	"replace":     strings.ReplaceAll,
+	"join":        strings.Join,
+	"repeat":      strings.Repeat,
+}
+
+// ParseTemplateFile reads and parses an output template with the template
+// helper functions.
+func ParseTemplateFile(file string) (*template.Template, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading template: %w", err)
+	}
+	tmpl, err := template.New(filepath.Base(file)).Funcs(templateFuncs).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("parsing template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Format implements the Formatter interface for template output
+func (f *TemplateFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	if f.Template == nil {
+		return fmt.Errorf("template format requires a template file (--template-file)")
+	}
+
+	wd, _ := os.Getwd()
+	data := TemplateData{
+		Findings: make([]TemplateFinding, 0, len(findings)),
+		Summary:  JSONSummary{Total: len(findings)},
+		Rules:    f.Rules,
+		Run: TemplateRun{
+			Tool:       "TerraTidy",
+			Version:    f.Version,
+			Time:       time.Now(),
+			WorkingDir: wd,
+		},
+	}
+	if data.Rules == nil {
+		data.Rules = make(map[string]RuleDescriptor)
+	}
+
+	for _, finding := range findings {
+		data.Findings = append(data.Findings, TemplateFinding{
+			Finding:   finding,
+			Engine:    findingEngine(finding.Rule),
+			Path:      relativePath(finding.File),
+			Line:      finding.Location.Start.Line,
+			Column:    finding.Location.Start.Column,
+			EndLine:   finding.Location.End.Line,
+			EndColumn: finding.Location.End.Column,
+		})
+
+		switch finding.Severity {
+		case sdk.SeverityError:
+			data.Summary.Errors++
+		case sdk.SeverityWarning:
+			data.Summary.Warnings++
+		case sdk.SeverityInfo:
+			data.Summary.Info++
+		}
+	}
+
+	if err := f.Template.Execute(w, data); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+	return nil
+}
+
+// templateGroupBy groups findings by a field, in key order
+func templateGroupBy(field string, findings []TemplateFinding) ([]TemplateGroup, error) {
+	var key func(TemplateFinding) string
+	switch field {
+	case "file":
+		key = func(f TemplateFinding) string { return f.File }
+	case "path":
+		key = func(f TemplateFinding) string { return f.Path }
+	case "rule":
+		key = func(f TemplateFinding) string { return f.Rule }
+	case "engine":
+		key = func(f TemplateFinding) string { return f.Engine }
+	case "severity":
+		key = func(f TemplateFinding) string { return string(f.Severity) }
+	default:
+		return nil, fmt.Errorf("groupBy: unknown field %q (file|path|rule|engine|severity)", field)
+	}
+
+	index := make(map[string]int)
+	var groups []TemplateGroup
+	for _, finding := range findings {
+		k := key(finding)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, TemplateGroup{Key: k})
+		}
+		groups[i].Findings = append(groups[i].Findings, finding)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].Key < groups[j].Key })
+	return groups, nil
+}
+
+// templateSeverity returns the findings of a severity
+func templateSeverity(severity string, findings []TemplateFinding) []TemplateFinding {
+	var out []TemplateFinding
+	for _, finding := range findings {
+		if string(finding.Severity) == severity {
+			out = append(out, finding)
+		}
+	}
+	return out
+}
+
+// templateMinSeverity returns the findings of a severity or a more severe one
+func templateMinSeverity(severity string, findings []TemplateFinding) ([]TemplateFinding, error) {
+	threshold, ok := severityRank(sdk.Severity(severity))
+	if !ok {
+		return nil, fmt.Errorf("minSeverity: unknown severity %q (error|warning|info)", severity)
+	}
+	var out []TemplateFinding
+	for _, finding := range findings {
+		if rank, _ := severityRank(finding.Severity); rank >= threshold {
+			out = append(out, finding)
+		}
+	}
+	return out, nil
+}
+
+// severityRank orders severities from info (0) to error (2)
+func severityRank(severity sdk.Severity) (int, bool) {
+	switch severity {
+	case sdk.SeverityError:
+		return 2, true
+	case sdk.SeverityWarning:
+		return 1, true
+	case sdk.SeverityInfo:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// templateJSON encodes a value as JSON, e.g. a string inside a JSON payload
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// templateCSV formats fields as a CSV record, without the line break
+func templateCSV(fields ...any) (string, error) {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		record[i] = fmt.Sprint(field)
+	}
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(record); err != nil {
+		return "", err
+	}
+	w.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), w.Error()
+}
@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func templateFindings() []sdk.Finding {
+	return []sdk.Finding{
+		{
+			Rule:     "style.block-label-case",
+			Message:  `Label "WebServer" should use snake_case`,
+			File:     "main.tf",
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{Start: hcl.Pos{Line: 3, Column: 25}, End: hcl.Pos{Line: 3, Column: 36}},
+		},
+		{
+			Rule:     "lint.terraform-required-version",
+			Message:  "Missing required_version",
+			File:     "versions.tf",
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{Start: hcl.Pos{Line: 1, Column: 1}},
+		},
+		{
+			Rule:     "style.block-label-case",
+			Message:  "Another label",
+			File:     "main.tf",
+			Severity: sdk.SeverityInfo,
+			Location: hcl.Range{Start: hcl.Pos{Line: 9, Column: 1}},
+		},
+	}
+}
+
+func renderTemplate(t *testing.T, f *TemplateFormatter, content string) string {
+	t.Helper()
+	tmpl, err := ParseTemplateFile(writeTemplate(t, content))
+	require.NoError(t, err)
+	f.Template = tmpl
+
+	var buf bytes.Buffer
+	require.NoError(t, f.Format(templateFindings(), &buf))
+	return buf.String()
+}
+
+func TestTemplateFormatter_CSV(t *testing.T) {
+	out := renderTemplate(t, &TemplateFormatter{}, `{{csv "file" "line" "severity" "rule" "message"}}
+{{range .Findings}}{{csv .Path .Line .Severity .Rule .Message}}
+{{end}}`)
+
+	assert.Equal(t, `file,line,severity,rule,message
+main.tf,3,warning,style.block-label-case,"Label ""WebServer"" should use snake_case"
+versions.tf,1,error,lint.terraform-required-version,Missing required_version
+main.tf,9,info,style.block-label-case,Another label
+`, out)
+}
+
+func TestTemplateFormatter_Helpers(t *testing.T) {
+	f := &TemplateFormatter{
+		Version: "1.2.3",
+		Rules: map[string]RuleDescriptor{
+			"style.block-label-case": {Title: "Block label case"},
+		},
+	}
+	out := renderTemplate(t, f, `{{.Run.Tool}} {{.Run.Version}}: {{.Summary.Total}} ({{.Summary.Errors}}/{{.Summary.Warnings}}/{{.Summary.Info}})
+{{range groupBy "path" .Findings}}{{.Key}}: {{len .Findings}}
+{{end}}{{range groupBy "engine" .Findings}}{{upper .Key}}
+{{end}}{{range minSeverity "warning" .Findings}}{{.Rule}}@{{.Line}}:{{.Column}}
+{{end}}{{len (severity "info" .Findings)}} info
+{{with index .Findings 0}}{{(index $.Rules .Rule).Title}} {{json .Message}}{{end}}
+`)
+
+	assert.Equal(t, `TerraTidy 1.2.3: 3 (1/1/1)
+main.tf: 2
+versions.tf: 1
+LINT
+STYLE
+style.block-label-case@3:25
+lint.terraform-required-version@1:1
+1 info
+Block label case "Label \"WebServer\" should use snake_case"
+`, out)
+}
+
+func TestTemplateFormatter_Errors(t *testing.T) {
+	err := (&TemplateFormatter{}).Format(nil, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--template-file")
+
+	_, err = ParseTemplateFile(writeTemplate(t, "{{range .Findings}"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "parsing template")
+
+	_, err = ParseTemplateFile(filepath.Join(t.TempDir(), "missing.tmpl"))
+	require.Error(t, err)
+
+	tmpl, err := ParseTemplateFile(writeTemplate(t, `{{range groupBy "color" .Findings}}{{end}}`))
+	require.NoError(t, err)
+	err = (&TemplateFormatter{Template: tmpl}).Format(templateFindings(), &bytes.Buffer{})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), `unknown field "color"`), err.Error())
+}
@@ -0,0 +1,281 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+// ANSI escape sequences used by the text output
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiDim    = "\x1b[2m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiCyan   = "\x1b[36m"
+	ansiGreen  = "\x1b[32m"
+)
+
+// TextFormatter outputs findings for the terminal: grouped by file, each with
+// the source line and a caret under its range, and ending with summary tables
+// by engine and by rule. Colors are used when writing to a terminal, unless
+// NoColor is set or the NO_COLOR environment variable is.
+type TextFormatter struct {
+	Verbose bool // Also show how fixable findings are fixed
+	NoColor bool // Never use colors
+}
+
+// Format implements the Formatter interface for text output
+func (f *TextFormatter) Format(findings []sdk.Finding, w io.Writer) error {
+	p := &textPrinter{color: !f.NoColor && colorEnabled(w), sources: make(map[string][]string)}
+
+	if len(findings) == 0 {
+		p.printf("%s\n", p.paint(ansiGreen, "✓ No issues found"))
+		return p.flush(w)
+	}
+
+	// Group findings by file, files in name order and file-less findings last
+	byFile := make(map[string][]sdk.Finding)
+	for _, finding := range findings {
+		byFile[finding.File] = append(byFile[finding.File], finding)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if (files[i] == "") != (files[j] == "") {
+			return files[j] == ""
+		}
+		return files[i] < files[j]
+	})
+
+	for _, file := range files {
+		fileFindings := byFile[file]
+		sort.SliceStable(fileFindings, func(i, j int) bool {
+			a, b := fileFindings[i].Location.Start, fileFindings[j].Location.Start
+			if a.Line != b.Line {
+				return a.Line < b.Line
+			}
+			return a.Column < b.Column
+		})
+
+		name := relativePath(file)
+		if name == "" {
+			name = "(no file)"
+		}
+		p.printf("%s\n", p.paint(ansiBold, name))
+		for _, finding := range fileFindings {
+			f.printFinding(p, finding)
+		}
+		p.printf("\n")
+	}
+
+	p.printSummary(findings)
+	return p.flush(w)
+}
+
+// printFinding prints a finding with its source line and a caret under its
+// range.
+func (f *TextFormatter) printFinding(p *textPrinter, finding sdk.Finding) {
+	start := finding.Location.Start
+	position := ""
+	if start.Line > 0 {
+		position = fmt.Sprintf("%d:%d", start.Line, start.Column)
+	}
+	severity := fmt.Sprintf("%-7s", finding.Severity)
+	p.printf("  %s %-7s %s %s\n",
+		p.paint(severityColor(finding.Severity), severity),
+		position,
+		finding.Message,
+		p.paint(ansiDim, "("+finding.Rule+")"),
+	)
+
+	if line, ok := p.sourceLine(finding.File, start.Line); ok {
+		gutter := fmt.Sprintf("%6d | ", start.Line)
+		p.printf("%s%s\n", p.paint(ansiDim, gutter), line)
+		p.printf("%s%s\n",
+			p.paint(ansiDim, strings.Repeat(" ", len(gutter)-2)+"| "),
+			p.paint(severityColor(finding.Severity), caret(line, finding)),
+		)
+	}
+
+	if f.Verbose && finding.Fixable {
+		fix := "automatically fixable"
+		if finding.Fix != nil && finding.Fix.Description != "" {
+			fix = "fix: " + finding.Fix.Description
+		}
+		p.printf("          %s\n", p.paint(ansiGreen, fix))
+	}
+}
+
+// caret returns the marker line under a finding's range in line: from the
+// start column to the end column, or to the end of the line for ranges that
+// span lines. Tabs are kept so the marker lines up with the source.
+func caret(line string, finding sdk.Finding) string {
+	start, end := finding.Location.Start, finding.Location.End
+	runes := []rune(line)
+	from := max(start.Column, 1) - 1
+	if from > len(runes) {
+		from = len(runes)
+	}
+	to := len(runes)
+	if end.Line == start.Line && end.Column > start.Column {
+		to = min(end.Column-1, len(runes))
+	}
+
+	var b strings.Builder
+	for _, r := range runes[:from] {
+		if r == '\t' {
+			b.WriteRune('\t')
+		} else {
+			b.WriteRune(' ')
+		}
+	}
+	b.WriteString(strings.Repeat("^", max(to-from, 1)))
+	return b.String()
+}
+
+// printSummary prints the tables of findings by engine and by rule and the
+// totals.
+func (p *textPrinter) printSummary(findings []sdk.Finding) {
+	type counts struct{ errors, warnings, info int }
+	var total counts
+	byEngine := make(map[string]*counts)
+	byRule := make(map[string]int)
+	for _, finding := range findings {
+		engine := findingEngine(finding.Rule)
+		if byEngine[engine] == nil {
+			byEngine[engine] = &counts{}
+		}
+		for _, c := range []*counts{byEngine[engine], &total} {
+			switch finding.Severity {
+			case sdk.SeverityError:
+				c.errors++
+			case sdk.SeverityWarning:
+				c.warnings++
+			default:
+				c.info++
+			}
+		}
+		byRule[finding.Rule]++
+	}
+
+	engines := make([]string, 0, len(byEngine))
+	for engine := range byEngine {
+		engines = append(engines, engine)
+	}
+	sort.Strings(engines)
+
+	rules := make([]string, 0, len(byRule))
+	for rule := range byRule {
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if byRule[rules[i]] != byRule[rules[j]] {
+			return byRule[rules[i]] > byRule[rules[j]]
+		}
+		return rules[i] < rules[j]
+	})
+
+	width := len("ENGINE")
+	for _, engine := range engines {
+		width = max(width, len(engine))
+	}
+	p.printf("%-*s  %6s  %8s  %4s\n", width, "ENGINE", "ERRORS", "WARNINGS", "INFO")
+	for _, engine := range engines {
+		c := byEngine[engine]
+		p.printf("%-*s  %6d  %8d  %4d\n", width, engine, c.errors, c.warnings, c.info)
+	}
+	p.printf("\n")
+
+	tw := tabwriter.NewWriter(&p.buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "RULE\tCOUNT\n")
+	for _, rule := range rules {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\n", rule, byRule[rule])
+	}
+	_ = tw.Flush()
+	p.printf("\n")
+
+	icon, color := "✗", ansiRed
+	if total.errors == 0 {
+		icon, color = "⚠", ansiYellow
+	}
+	p.printf("%s\n", p.paint(color, fmt.Sprintf("%s %d issue(s): %d error(s), %d warning(s), %d info",
+		icon, len(findings), total.errors, total.warnings, total.info)))
+}
+
+// textPrinter buffers the text output and reads source lines for it.
+type textPrinter struct {
+	buf     strings.Builder
+	color   bool
+	sources map[string][]string
+}
+
+func (p *textPrinter) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(&p.buf, format, args...)
+}
+
+func (p *textPrinter) flush(w io.Writer) error {
+	_, err := io.WriteString(w, p.buf.String())
+	return err
+}
+
+// paint wraps s in an ANSI style when colors are enabled
+func (p *textPrinter) paint(style, s string) string {
+	if !p.color || s == "" {
+		return s
+	}
+	return style + s + ansiReset
+}
+
+// sourceLine returns a line of file, reading each file once
+func (p *textPrinter) sourceLine(file string, line int) (string, bool) {
+	if file == "" || line <= 0 {
+		return "", false
+	}
+	lines, ok := p.sources[file]
+	if !ok {
+		if content, err := os.ReadFile(file); err == nil && utf8.Valid(content) {
+			lines = strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+		}
+		p.sources[file] = lines
+	}
+	if line > len(lines) {
+		return "", false
+	}
+	return lines[line-1], true
+}
+
+// severityColor returns the ANSI color of a severity
+func severityColor(severity sdk.Severity) string {
+	switch severity {
+	case sdk.SeverityError:
+		return ansiRed
+	case sdk.SeverityWarning:
+		return ansiYellow
+	default:
+		return ansiCyan
+	}
+}
+
+// colorEnabled reports whether colors should be written to w: w is a terminal
+// and NO_COLOR is not set.
+func colorEnabled(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,73 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/pkg/sdk"
+)
+
+func TestTextFormatter_SourceContext(t *testing.T) {
+	dir := t.TempDir()
+	tfFile := filepath.Join(dir, "main.tf")
+	source := "resource \"aws_s3_bucket\" \"data\" {\n\tacl = \"public-read\"\n}\n"
+	if err := os.WriteFile(tfFile, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings := []sdk.Finding{
+		{
+			Rule:     "policy.aws-s3-public-acl",
+			Message:  "Public ACL",
+			File:     tfFile,
+			Severity: sdk.SeverityError,
+			Location: hcl.Range{Start: hcl.Pos{Line: 2, Column: 2}, End: hcl.Pos{Line: 2, Column: 5}},
+		},
+		{
+			Rule:     "style.block-label-case",
+			Message:  "Spans lines",
+			File:     tfFile,
+			Severity: sdk.SeverityWarning,
+			Location: hcl.Range{Start: hcl.Pos{Line: 1, Column: 26}, End: hcl.Pos{Line: 3, Column: 2}},
+		},
+	}
+
+	var buf strings.Builder
+	if err := (&TextFormatter{}).Format(findings, &buf); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		// Sorted by line; a multi-line range is marked to the end of its first line
+		"  warning 1:26    Spans lines (style.block-label-case)\n" +
+			"     1 | resource \"aws_s3_bucket\" \"data\" {\n" +
+			"       |                          ^^^^^^^^\n",
+		// Tabs are kept so the caret lines up
+		"  error   2:2     Public ACL (policy.aws-s3-public-acl)\n" +
+			"     2 | \tacl = \"public-read\"\n" +
+			"       | \t^^^\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "\x1b[") {
+		t.Error("colors written to a non-terminal writer")
+	}
+}
+
+func TestTextFormatter_Colors(t *testing.T) {
+	p := &textPrinter{color: true}
+	if got := p.paint(ansiRed, "error"); got != "\x1b[31merror\x1b[0m" {
+		t.Errorf("paint() = %q", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if colorEnabled(os.Stdout) {
+		t.Error("NO_COLOR must disable colors")
+	}
+}
@@ -107,6 +107,14 @@ func (m *Manager) LoadAll() error {
 	return nil
 }
 
+// LoadPlugin loads a single Go plugin (.so file)
+func (m *Manager) LoadPlugin(path string) error {
+	if err := m.loadGoPlugin(path); err != nil {
+		return fmt.Errorf("loading Go plugin %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
 // loadFromDirectory loads all plugins from a directory
 func (m *Manager) loadFromDirectory(dir string) error {
 	// Expand path
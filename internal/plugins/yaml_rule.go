@@ -0,0 +1,129 @@
+package plugins
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLRule is a declarative rule defined in a YAML file, as generated by
+// `terratidy init-rule --type yaml`. It reports resources of the matched types
+// that lack one of the required attributes or blocks.
+type YAMLRule struct {
+	RuleName string       `yaml:"name"`
+	Desc     string       `yaml:"description"`
+	Severity sdk.Severity `yaml:"severity"`
+	Message  string       `yaml:"message"`
+	Patterns YAMLPatterns `yaml:"patterns"`
+	Examples YAMLExamples `yaml:"examples"`
+	Tags     []string     `yaml:"tags"`
+}
+
+// YAMLPatterns selects the resources a YAML rule checks and what they require.
+type YAMLPatterns struct {
+	ResourceTypes      []string `yaml:"resource_types"` // Empty matches every resource
+	RequiredAttributes []string `yaml:"required_attributes"`
+}
+
+// YAMLExamples are configurations that must pass (Good) and fail (Bad) the rule.
+type YAMLExamples struct {
+	Good string `yaml:"good"`
+	Bad  string `yaml:"bad"`
+}
+
+// LoadYAMLRule reads a YAML rule definition.
+func LoadYAMLRule(path string) (*YAMLRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	var rule YAMLRule
+	if err := yaml.Unmarshal(content, &rule); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	if rule.RuleName == "" {
+		return nil, fmt.Errorf("%s: rule name is required", path)
+	}
+	switch rule.Severity {
+	case "":
+		rule.Severity = sdk.SeverityWarning
+	case sdk.SeverityError, sdk.SeverityWarning, sdk.SeverityInfo:
+	default:
+		return nil, fmt.Errorf("%s: invalid severity %q (use info, warning or error)", path, rule.Severity)
+	}
+	if len(rule.Patterns.RequiredAttributes) == 0 {
+		return nil, fmt.Errorf("%s: patterns.required_attributes is empty", path)
+	}
+	if rule.Message == "" {
+		rule.Message = "Resource violates " + rule.RuleName + " rule"
+	}
+	return &rule, nil
+}
+
+// Name returns the rule identifier.
+func (r *YAMLRule) Name() string {
+	return r.RuleName
+}
+
+// Description returns a human-readable description of the rule.
+func (r *YAMLRule) Description() string {
+	return r.Desc
+}
+
+// Check reports matched resources missing a required attribute or block.
+func (r *YAMLRule) Check(ctx *sdk.Context, file *hcl.File) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+
+	body, ok := file.Body.(*hclsyntax.Body)
+	if !ok {
+		return findings, nil
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type != "resource" || len(block.Labels) != 2 {
+			continue
+		}
+		if len(r.Patterns.ResourceTypes) > 0 && !slices.Contains(r.Patterns.ResourceTypes, block.Labels[0]) {
+			continue
+		}
+
+		for _, required := range r.Patterns.RequiredAttributes {
+			if hasAttributeOrBlock(block.Body, required) {
+				continue
+			}
+			findings = append(findings, sdk.Finding{
+				Rule: r.Name(),
+				Message: fmt.Sprintf("%s (%s.%s is missing %s)",
+					r.Message, block.Labels[0], block.Labels[1], required),
+				File:     ctx.File,
+				Location: block.DefRange(),
+				Severity: r.Severity,
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// Fix is not supported for YAML rules.
+func (r *YAMLRule) Fix(_ *sdk.Context, _ *hcl.File) ([]byte, error) {
+	return nil, nil
+}
+
+func hasAttributeOrBlock(body *hclsyntax.Body, name string) bool {
+	if _, ok := body.Attributes[name]; ok {
+		return true
+	}
+	for _, block := range body.Blocks {
+		if block.Type == name {
+			return true
+		}
+	}
+	return false
+}
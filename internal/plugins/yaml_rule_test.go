@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeYAMLRule(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rule.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func checkYAMLRule(t *testing.T, rule sdk.Rule, src string) []sdk.Finding {
+	t.Helper()
+	file, diags := hclsyntax.ParseConfig([]byte(src), "main.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	findings, err := rule.Check(&sdk.Context{File: "main.tf"}, file)
+	require.NoError(t, err)
+	return findings
+}
+
+func TestLoadYAMLRule(t *testing.T) {
+	rule, err := LoadYAMLRule(writeYAMLRule(t, `name: s3-encryption
+description: "S3 buckets must be encrypted"
+severity: error
+patterns:
+  resource_types: [aws_s3_bucket]
+  required_attributes: [server_side_encryption_configuration, tags]
+message: "S3 bucket is not compliant"
+`))
+	require.NoError(t, err)
+	assert.Equal(t, "s3-encryption", rule.Name())
+	assert.Equal(t, "S3 buckets must be encrypted", rule.Description())
+
+	findings := checkYAMLRule(t, rule, `
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs"
+  tags   = {}
+}
+
+resource "aws_s3_bucket" "data" {
+  bucket = "data"
+  tags   = {}
+
+  server_side_encryption_configuration {
+    rule {}
+  }
+}
+
+resource "aws_instance" "web" {}
+`)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "s3-encryption", findings[0].Rule)
+	assert.Equal(t, sdk.SeverityError, findings[0].Severity)
+	assert.Equal(t, "S3 bucket is not compliant (aws_s3_bucket.logs is missing server_side_encryption_configuration)",
+		findings[0].Message)
+	assert.Equal(t, 2, findings[0].Location.Start.Line)
+}
+
+func TestLoadYAMLRule_Defaults(t *testing.T) {
+	rule, err := LoadYAMLRule(writeYAMLRule(t, `name: owner-tag
+patterns:
+  required_attributes: [tags]
+`))
+	require.NoError(t, err)
+
+	// No resource_types matches every resource
+	findings := checkYAMLRule(t, rule, `resource "aws_instance" "web" {}`)
+	require.Len(t, findings, 1)
+	assert.Equal(t, sdk.SeverityWarning, findings[0].Severity)
+	assert.Contains(t, findings[0].Message, "Resource violates owner-tag rule")
+}
+
+func TestLoadYAMLRule_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing name", "patterns:\n  required_attributes: [tags]\n", "rule name is required"},
+		{"bad severity", "name: x\nseverity: fatal\npatterns:\n  required_attributes: [tags]\n", "invalid severity"},
+		{"no requirements", "name: x\n", "required_attributes is empty"},
+		{"invalid yaml", "name: [", "parsing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadYAMLRule(writeYAMLRule(t, tt.content))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package ruletest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// goTestEvent is a line of `go test -json` output.
+type goTestEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// RunGoTests runs the tests of a Go rule package with `go test -json` and
+// returns one result per test. A failing build is reported as an error.
+func RunGoTests(ctx context.Context, dir string) ([]Result, error) {
+	cmd := exec.CommandContext(ctx, "go", "test", "-json", "./...")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, runErr := cmd.Output()
+	results, err := parseGoTestEvents(bytes.NewReader(out))
+	if err != nil {
+		return nil, err
+	}
+	if runErr != nil && len(results) == 0 {
+		return nil, fmt.Errorf("go test: %w\n%s%s", runErr, strings.TrimSpace(stderr.String()), buildOutput(out))
+	}
+	return results, nil
+}
+
+// parseGoTestEvents converts `go test -json` events into results, in the order
+// the tests finished. Subtests are reported under their full name.
+func parseGoTestEvents(r io.Reader) ([]Result, error) {
+	var results []Result
+	output := make(map[string]*strings.Builder)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var event goTestEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			// go test prints build failures as plain text
+			continue
+		}
+		if event.Test == "" {
+			continue
+		}
+
+		key := event.Package + "/" + event.Test
+		switch event.Action {
+		case "output":
+			if output[key] == nil {
+				output[key] = &strings.Builder{}
+			}
+			output[key].WriteString(event.Output)
+		case "pass", "fail", "skip":
+			result := Result{
+				Suite:    "go",
+				Name:     event.Test,
+				File:     event.Package,
+				Duration: time.Duration(event.Elapsed * float64(time.Second)),
+				Skipped:  event.Action == "skip",
+			}
+			if b := output[key]; b != nil {
+				result.Output = b.String()
+			}
+			if event.Action == "fail" {
+				result.Failure = "test failed"
+				if result.Output != "" {
+					result.Failure = strings.TrimSpace(result.Output)
+				}
+			}
+			results = append(results, result)
+		}
+	}
+	return results, scanner.Err()
+}
+
+// buildOutput returns the plain-text lines of go test output, such as
+// compiler errors.
+func buildOutput(out []byte) string {
+	var b strings.Builder
+	for _, line := range strings.Split(string(out), "\n") {
+		var event goTestEvent
+		if json.Unmarshal([]byte(line), &event) == nil {
+			if event.Action == "output" && event.Test == "" {
+				b.WriteString(event.Output)
+			}
+			continue
+		}
+		if line != "" {
+			b.WriteString(line + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "\n" + strings.TrimRight(b.String(), "\n")
+}
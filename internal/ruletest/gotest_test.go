@@ -0,0 +1,36 @@
+package ruletest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGoTestEvents(t *testing.T) {
+	events := `{"Action":"start","Package":"example.com/rules/tags"}
+{"Action":"run","Package":"example.com/rules/tags","Test":"TestRule_Name"}
+{"Action":"output","Package":"example.com/rules/tags","Test":"TestRule_Name","Output":"=== RUN   TestRule_Name\n"}
+{"Action":"pass","Package":"example.com/rules/tags","Test":"TestRule_Name","Elapsed":0.25}
+{"Action":"output","Package":"example.com/rules/tags","Test":"TestRule_Check/catches_violation","Output":"    rule_test.go:20: expected 1 finding\n"}
+{"Action":"fail","Package":"example.com/rules/tags","Test":"TestRule_Check/catches_violation","Elapsed":0}
+{"Action":"skip","Package":"example.com/rules/tags","Test":"TestSlow","Elapsed":0}
+not json
+{"Action":"fail","Package":"example.com/rules/tags","Elapsed":0.3}
+`
+	results, err := parseGoTestEvents(strings.NewReader(events))
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	assert.Equal(t, "TestRule_Name", results[0].Name)
+	assert.True(t, results[0].Passed())
+	assert.Equal(t, 250*time.Millisecond, results[0].Duration)
+	assert.Equal(t, "example.com/rules/tags", results[0].File)
+
+	assert.Equal(t, "TestRule_Check/catches_violation", results[1].Name)
+	assert.Equal(t, "rule_test.go:20: expected 1 finding", results[1].Failure)
+
+	assert.True(t, results[2].Skipped)
+}
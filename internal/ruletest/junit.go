@@ -0,0 +1,113 @@
+package ruletest
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes results as a JUnit XML report, one test suite per Suite
+// in order of first appearance.
+func WriteJUnit(w io.Writer, results []Result) error {
+	report := junitSuites{}
+	index := make(map[string]int)
+	var total time.Duration
+	suiteTimes := make(map[string]time.Duration)
+
+	for _, r := range results {
+		i, ok := index[r.Suite]
+		if !ok {
+			i = len(report.Suites)
+			index[r.Suite] = i
+			report.Suites = append(report.Suites, junitSuite{Name: r.Suite})
+		}
+		suite := &report.Suites[i]
+
+		tc := junitCase{
+			Name:      r.Name,
+			Classname: r.Suite,
+			File:      r.File,
+			Time:      seconds(r.Duration),
+			SystemOut: r.Output,
+		}
+		switch {
+		case r.Skipped:
+			tc.Skipped = &struct{}{}
+			suite.Skipped++
+		case r.Failure != "":
+			tc.Failure = &junitFailure{Message: firstLine(r.Failure), Text: r.Failure}
+			suite.Failures++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, tc)
+		suiteTimes[r.Suite] += r.Duration
+		total += r.Duration
+	}
+
+	for i := range report.Suites {
+		suite := &report.Suites[i]
+		suite.Time = seconds(suiteTimes[suite.Name])
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Skipped += suite.Skipped
+	}
+	report.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+func firstLine(s string) string {
+	for i, c := range s {
+		if c == '\n' {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package ruletest
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, []Result{
+		{Suite: "rego", Name: "data.tags_test.test_ok", File: "tags_test.rego", Duration: 1500 * time.Microsecond},
+		{Suite: "fixtures", Name: "public-bucket", Failure: "missing: no-public-s3\nunexpected: x"},
+		{Suite: "rego", Name: "data.tags_test.todo_test_later", Skipped: true, Output: "note"},
+	}))
+
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1" skipped="1" time="0.002">
+  <testsuite name="rego" tests="2" failures="0" skipped="1" time="0.002">
+    <testcase name="data.tags_test.test_ok" classname="rego" file="tags_test.rego" time="0.002"></testcase>
+    <testcase name="data.tags_test.todo_test_later" classname="rego" time="0.000">
+      <skipped></skipped>
+      <system-out>note</system-out>
+    </testcase>
+  </testsuite>
+  <testsuite name="fixtures" tests="1" failures="1" skipped="0" time="0.000">
+    <testcase name="public-bucket" classname="fixtures" time="0.000">
+      <failure message="missing: no-public-s3">missing: no-public-s3&#xA;unexpected: x</failure>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
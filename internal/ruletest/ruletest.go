@@ -0,0 +1,303 @@
+// Package ruletest runs the tests of custom rules: fixture cases compared with
+// expected findings, and reports the results as text or JUnit XML.
+package ruletest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture layout: each case directory holds the Terraform under input/ and the
+// findings it must produce in expected.yaml.
+const inputDirName = "input"
+
+var expectedFiles = []string{"expected.yaml", "expected.yml", "expected.json"}
+
+// Result is the outcome of one test.
+type Result struct {
+	Suite    string // Group of the test, e.g. rego, fixtures or go
+	Name     string
+	File     string
+	Duration time.Duration
+	Skipped  bool
+	Failure  string // Empty when the test passed
+	Output   string
+}
+
+// Passed reports whether the test ran and passed.
+func (r Result) Passed() bool {
+	return !r.Skipped && r.Failure == ""
+}
+
+// ExpectedFinding is a finding a fixture must produce. Empty fields match any
+// value.
+type ExpectedFinding struct {
+	Rule     string `yaml:"rule" json:"rule"`         // Full rule name, or without the engine prefix
+	Severity string `yaml:"severity" json:"severity"` // Exact severity
+	Message  string `yaml:"message" json:"message"`   // Substring of the message
+	File     string `yaml:"file" json:"file"`         // Path relative to the input directory
+	Line     int    `yaml:"line" json:"line"`         // Start line
+}
+
+// Expected is the content of an expected findings file.
+type Expected struct {
+	Findings []ExpectedFinding `yaml:"findings" json:"findings"`
+}
+
+// Case is a set of fixture files checked together.
+type Case struct {
+	Name     string
+	Dir      string // Directory expected file paths are relative to
+	Files    []string
+	Expected *Expected // Nil when findings are only reported
+}
+
+// LoadExpected reads an expected findings file (YAML or JSON).
+func LoadExpected(path string) (*Expected, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading expected file: %w", err)
+	}
+
+	var expected Expected
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &expected); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &expected); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported expected file format: %s", filepath.Ext(path))
+	}
+	return &expected, nil
+}
+
+// FindCases returns the fixture cases below dir: every directory with an
+// input/ folder. A case without an expected file must produce no findings.
+func FindCases(dir string) ([]Case, error) {
+	var cases []Case
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		input := filepath.Join(path, inputDirName)
+		if info, err := os.Stat(input); err != nil || !info.IsDir() {
+			return nil
+		}
+
+		c := Case{Name: path, Dir: input, Expected: &Expected{}}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != "." {
+			c.Name = filepath.ToSlash(rel)
+		}
+		if c.Files, err = FindFiles(input); err != nil {
+			return err
+		}
+		for _, name := range expectedFiles {
+			expectedPath := filepath.Join(path, name)
+			if _, err := os.Stat(expectedPath); err == nil {
+				if c.Expected, err = LoadExpected(expectedPath); err != nil {
+					return err
+				}
+				break
+			}
+		}
+
+		cases = append(cases, c)
+		return filepath.SkipDir
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return cases, err
+}
+
+// FindFiles returns the Terraform and HCL files below dir.
+func FindFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isHCLFile(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	sort.Strings(files)
+	return files, err
+}
+
+func isHCLFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	for _, ext := range []string{".tf", ".hcl", ".tfvars", ".tf.json", ".tfvars.json"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare matches findings against the expected ones and returns a message
+// for each expected finding that is missing and each finding that was not
+// expected. Each finding matches at most one expectation.
+func Compare(expected *Expected, findings []sdk.Finding, dir string) []string {
+	var problems []string
+	matched := make([]bool, len(findings))
+
+	for _, exp := range expected.Findings {
+		found := false
+		for i, actual := range findings {
+			if !matched[i] && Matches(exp, actual, dir) {
+				matched[i] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, "missing: "+describeExpected(exp))
+		}
+	}
+
+	for i, actual := range findings {
+		if !matched[i] {
+			problems = append(problems, fmt.Sprintf("unexpected: %s: %s (%s)",
+				actual.Rule, actual.Message, location(actual, dir)))
+		}
+	}
+	return problems
+}
+
+// Matches reports whether a finding satisfies an expectation. The rule matches
+// exactly or without its engine prefix (no-public-s3 matches
+// policy.no-public-s3, but not policy.no-public-s3-acl).
+func Matches(expected ExpectedFinding, actual sdk.Finding, dir string) bool {
+	if expected.Rule != "" && expected.Rule != actual.Rule {
+		_, rule, ok := strings.Cut(actual.Rule, ".")
+		if !ok || rule != expected.Rule {
+			return false
+		}
+	}
+	if expected.Severity != "" && string(actual.Severity) != expected.Severity {
+		return false
+	}
+	if expected.Message != "" && !strings.Contains(actual.Message, expected.Message) {
+		return false
+	}
+	if expected.File != "" && relativePath(actual.File, dir) != filepath.ToSlash(expected.File) {
+		return false
+	}
+	if expected.Line != 0 && actual.Location.Start.Line != expected.Line {
+		return false
+	}
+	return true
+}
+
+func describeExpected(exp ExpectedFinding) string {
+	var parts []string
+	if exp.Rule != "" {
+		parts = append(parts, exp.Rule)
+	}
+	if exp.Severity != "" {
+		parts = append(parts, "severity "+exp.Severity)
+	}
+	if exp.Message != "" {
+		parts = append(parts, fmt.Sprintf("message containing %q", exp.Message))
+	}
+	if exp.File != "" {
+		loc := exp.File
+		if exp.Line != 0 {
+			loc = fmt.Sprintf("%s:%d", loc, exp.Line)
+		}
+		parts = append(parts, "at "+loc)
+	}
+	if len(parts) == 0 {
+		return "any finding"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func location(f sdk.Finding, dir string) string {
+	loc := relativePath(f.File, dir)
+	if f.Location.Start.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, f.Location.Start.Line)
+	}
+	return loc
+}
+
+func relativePath(path, dir string) string {
+	if dir != "" {
+		if rel, err := filepath.Rel(dir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// RunRules checks files with Go or YAML rules, as the style engine does.
+func RunRules(rules []sdk.Rule, files []string) ([]sdk.Finding, error) {
+	var findings []sdk.Finding
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		fileFindings, err := CheckSource(rules, path, content)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, fileFindings...)
+	}
+	return findings, nil
+}
+
+// CheckSource checks in-memory Terraform source, such as a rule example.
+func CheckSource(rules []sdk.Rule, filename string, content []byte) ([]sdk.Finding, error) {
+	parser := hclparse.NewParser()
+
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(filename, ".json") {
+		file, diags = parser.ParseJSON(content, filename)
+	} else {
+		file, diags = parser.ParseHCL(content, filename)
+	}
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("parsing %s: %s", filename, diags.Error())
+	}
+
+	ruleCtx := &sdk.Context{
+		Config:  make(map[string]interface{}),
+		WorkDir: ".",
+		File:    filename,
+	}
+
+	var findings []sdk.Finding
+	for _, rule := range rules {
+		ruleFindings, err := rule.Check(ruleCtx, file)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name(), err)
+		}
+		findings = append(findings, ruleFindings...)
+	}
+	return findings, nil
+}
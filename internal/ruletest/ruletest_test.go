@@ -0,0 +1,142 @@
+package ruletest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/santosr2/terratidy/internal/plugins"
+	"github.com/santosr2/terratidy/pkg/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+}
+
+func finding(rule, msg, file string, line int) sdk.Finding {
+	return sdk.Finding{
+		Rule:     rule,
+		Message:  msg,
+		File:     file,
+		Severity: sdk.SeverityError,
+		Location: hcl.Range{Filename: file, Start: hcl.Pos{Line: line}},
+	}
+}
+
+func TestFindCases(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "public-bucket", "input", "main.tf"), `resource "aws_s3_bucket" "b" {}`)
+	writeFile(t, filepath.Join(dir, "public-bucket", "expected.yaml"), `findings:
+  - rule: no-public-s3
+    file: main.tf
+    line: 1
+`)
+	writeFile(t, filepath.Join(dir, "aws", "compliant", "input", "modules", "a", "main.tf"), `# nothing`)
+	writeFile(t, filepath.Join(dir, "aws", "compliant", "input", "README.md"), `ignored`)
+	writeFile(t, filepath.Join(dir, "loose.tf"), `# not part of a case`)
+
+	cases, err := FindCases(dir)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+
+	assert.Equal(t, "aws/compliant", cases[0].Name)
+	assert.Equal(t, []string{filepath.Join(dir, "aws", "compliant", "input", "modules", "a", "main.tf")}, cases[0].Files)
+	assert.Empty(t, cases[0].Expected.Findings, "no expected file means no findings")
+
+	assert.Equal(t, "public-bucket", cases[1].Name)
+	assert.Equal(t, filepath.Join(dir, "public-bucket", "input"), cases[1].Dir)
+	require.Len(t, cases[1].Expected.Findings, 1)
+	assert.Equal(t, ExpectedFinding{Rule: "no-public-s3", File: "main.tf", Line: 1}, cases[1].Expected.Findings[0])
+
+	cases, err = FindCases(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, cases)
+}
+
+func TestLoadExpected(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "expected.json"), `{"findings": [{"rule": "policy.x", "severity": "warning"}]}`)
+	writeFile(t, filepath.Join(dir, "expected.txt"), `findings: []`)
+
+	expected, err := LoadExpected(filepath.Join(dir, "expected.json"))
+	require.NoError(t, err)
+	assert.Equal(t, []ExpectedFinding{{Rule: "policy.x", Severity: "warning"}}, expected.Findings)
+
+	_, err = LoadExpected(filepath.Join(dir, "expected.txt"))
+	assert.ErrorContains(t, err, "unsupported expected file format")
+}
+
+func TestMatches(t *testing.T) {
+	actual := finding("policy.no-public-s3", "bucket logs is public", "/fixtures/a/input/main.tf", 3)
+
+	tests := []struct {
+		name     string
+		expected ExpectedFinding
+		want     bool
+	}{
+		{"full rule", ExpectedFinding{Rule: "policy.no-public-s3"}, true},
+		{"without engine prefix", ExpectedFinding{Rule: "no-public-s3"}, true},
+		{"rule suffix only", ExpectedFinding{Rule: "public-s3"}, false},
+		{"rule prefix only", ExpectedFinding{Rule: "policy.no-public"}, false},
+		{"severity", ExpectedFinding{Severity: "warning"}, false},
+		{"message substring", ExpectedFinding{Message: "logs is public"}, true},
+		{"other message", ExpectedFinding{Message: "private"}, false},
+		{"relative file and line", ExpectedFinding{File: "main.tf", Line: 3}, true},
+		{"other line", ExpectedFinding{File: "main.tf", Line: 4}, false},
+		{"other file", ExpectedFinding{File: "vars.tf"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Matches(tt.expected, actual, "/fixtures/a/input"))
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	findings := []sdk.Finding{
+		finding("policy.required-tags", "web is missing tags", "/in/main.tf", 1),
+		finding("policy.required-tags", "db is missing tags", "/in/main.tf", 5),
+	}
+
+	problems := Compare(&Expected{Findings: []ExpectedFinding{
+		{Rule: "required-tags", Message: "web"},
+		{Rule: "required-tags", Message: "web"},
+	}}, findings, "/in")
+	assert.Equal(t, []string{
+		`missing: required-tags, message containing "web"`,
+		"unexpected: policy.required-tags: db is missing tags (main.tf:5)",
+	}, problems)
+
+	assert.Empty(t, Compare(&Expected{Findings: []ExpectedFinding{
+		{Rule: "required-tags"}, {Rule: "required-tags"},
+	}}, findings, "/in"))
+}
+
+func TestRunRules(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "rule.yaml"), `name: owner-tag
+patterns:
+  resource_types: [aws_instance]
+  required_attributes: [tags]
+`)
+	writeFile(t, filepath.Join(dir, "main.tf"), `resource "aws_instance" "web" {}`)
+	writeFile(t, filepath.Join(dir, "broken.tf"), `resource "aws_instance" {`)
+
+	rule, err := plugins.LoadYAMLRule(filepath.Join(dir, "rule.yaml"))
+	require.NoError(t, err)
+
+	findings, err := RunRules([]sdk.Rule{rule}, []string{filepath.Join(dir, "main.tf")})
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "owner-tag", findings[0].Rule)
+	assert.Equal(t, filepath.Join(dir, "main.tf"), findings[0].File)
+
+	_, err = RunRules([]sdk.Rule{rule}, []string{filepath.Join(dir, "broken.tf")})
+	assert.ErrorContains(t, err, "parsing")
+}
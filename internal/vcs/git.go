@@ -212,6 +212,11 @@ func (g *Git) filterTerraformFiles(files []string) []string {
 		ext := strings.ToLower(filepath.Ext(f))
 		if ext == ".tf" || ext == ".tfvars" || ext == ".hcl" {
 			result = append(result, f)
+			continue
+		}
+		name := strings.ToLower(filepath.Base(f))
+		if strings.HasSuffix(name, ".tf.json") || strings.HasSuffix(name, ".tfvars.json") {
+			result = append(result, f)
 		}
 	}
 	return result
@@ -69,15 +69,19 @@ func TestGit_FilterTerraformFiles(t *testing.T) {
 		"terraform.tfvars",
 		".tflint.hcl",
 		"test.go",
+		"main.tf.json",
+		"package.json",
 	}
 
 	filtered := git.filterTerraformFiles(files)
 
-	assert.Len(t, filtered, 4)
+	assert.Len(t, filtered, 5)
 	assert.Contains(t, filtered, "main.tf")
 	assert.Contains(t, filtered, "variables.tf")
 	assert.Contains(t, filtered, "terraform.tfvars")
 	assert.Contains(t, filtered, ".tflint.hcl")
+	assert.Contains(t, filtered, "main.tf.json")
+	assert.NotContains(t, filtered, "package.json")
 	assert.NotContains(t, filtered, "README.md")
 	assert.NotContains(t, filtered, "config.yaml")
 	assert.NotContains(t, filtered, "test.go")
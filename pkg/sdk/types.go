@@ -27,9 +27,23 @@ type Finding struct {
 	Location hcl.Range              `json:"location"`
 	Severity Severity               `json:"severity"`
 	Fixable  bool                   `json:"fixable"`
+	Fix      *Fix                   `json:"fix,omitempty"`
 	FixFunc  func() ([]byte, error) `json:"-"`
 }
 
+// Fix describes the edits that resolve a finding as data, for consumers that
+// cannot call FixFunc (such as SARIF output or editor integrations)
+type Fix struct {
+	Description string     `json:"description,omitempty"`
+	Edits       []TextEdit `json:"edits"`
+}
+
+// TextEdit replaces the text covered by Range with NewText
+type TextEdit struct {
+	Range   hcl.Range `json:"range"`
+	NewText string    `json:"new_text"`
+}
+
 // Context provides context for rule execution
 type Context struct {
 	Config  map[string]interface{}